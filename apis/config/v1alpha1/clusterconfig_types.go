@@ -19,6 +19,8 @@ import (
 	"github.com/liqotech/liqo/pkg/crdClient"
 	"github.com/liqotech/liqo/pkg/labelPolicy"
 	"github.com/liqotech/liqo/pkg/liqonet"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -31,6 +33,83 @@ type ClusterConfigSpec struct {
 	DiscoveryConfig     DiscoveryConfig     `json:"discoveryConfig"`
 	LiqonetConfig       LiqonetConfig       `json:"liqonetConfig"`
 	DispatcherConfig    DispatcherConfig    `json:"dispatcherConfig,omitempty"`
+	//ApiServerConfig defines how the foreign clusters reach the local API server
+	ApiServerConfig ApiServerConfig `json:"apiServerConfig,omitempty"`
+	//PodMutatorConfig defines which pods can be offloaded to the foreign clusters
+	PodMutatorConfig PodMutatorConfig `json:"podMutatorConfig,omitempty"`
+}
+
+// OffloadingPolicy defines whether the pods can be scheduled on the virtual nodes
+type OffloadingPolicy string
+
+const (
+	// the pods tolerate the taint of the virtual nodes
+	OffloadingPolicyAllow OffloadingPolicy = "Allow"
+	// the pods are not mutated, so that they cannot be scheduled on the virtual nodes
+	OffloadingPolicyDeny OffloadingPolicy = "Deny"
+	// the pods tolerate the taint of the virtual nodes and are scheduled only on them, through their node affinity
+	OffloadingPolicyOffload OffloadingPolicy = "Offload"
+)
+
+// PodMutatorConfig defines how the pod mutator allows the pods to be scheduled on the virtual nodes
+type PodMutatorConfig struct {
+	// DefaultPolicy is applied to the pods not matching any rule
+	// +kubebuilder:validation:Enum="Allow";"Deny"
+	// +kubebuilder:default="Allow"
+	DefaultPolicy OffloadingPolicy `json:"defaultPolicy,omitempty"`
+	// Rules are evaluated in order, the first one matching a pod is applied
+	Rules []OffloadingRule `json:"rules,omitempty"`
+}
+
+// OffloadingRule selects a set of pods and defines how they can be offloaded to the foreign clusters.
+// A rule matches a pod if all its selectors match it, the selectors which are not set match every pod.
+type OffloadingRule struct {
+	// Name identifies the rule in the logs and in the audit annotations
+	Name string `json:"name,omitempty"`
+	// Namespaces contains the names of the namespaces of the selected pods
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces of the selected pods by labels
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// PodSelector selects the pods by labels
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// +kubebuilder:validation:Enum="Allow";"Deny";"Offload"
+	// +kubebuilder:default="Allow"
+	Policy OffloadingPolicy `json:"policy,omitempty"`
+	// AllowedClusters contains the cluster IDs of the foreign clusters the allowed pods can be offloaded to,
+	// enforced through their node affinity. If empty, the pods can be offloaded to every foreign cluster.
+	AllowedClusters []string `json:"allowedClusters,omitempty"`
+	// Resources defines how the resource requests of the pods are mutated. It is applied only with the Offload policy,
+	// since the allowed pods may be scheduled on the local nodes too.
+	Resources *ResourcesMutation `json:"resources,omitempty"`
+}
+
+// ResourcesMutation defines how the resource requests of the containers of the offloadable pods are mutated,
+// e.g. to make them schedulable in the namespaces of the foreign clusters, which may enforce quotas
+type ResourcesMutation struct {
+	// DefaultRequests are set in the containers not requesting the resource
+	DefaultRequests corev1.ResourceList `json:"defaultRequests,omitempty"`
+	// RequestsPercentage scales the requests of the containers, after the default requests are set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	RequestsPercentage int32 `json:"requestsPercentage,omitempty"`
+}
+
+// ApiServerConfig defines how the foreign clusters reach the local API server, through the kubeconfigs created for them
+type ApiServerConfig struct {
+	// Endpoints contains the URLs of the API server (e.g. https://10.0.0.1:6443) in order of preference,
+	// such as the addresses of the control plane nodes or of a load balancer.
+	// If empty, the APISERVER and APISERVER_PORT env variables or the address of the first master node are used.
+	// The endpoints are checked before a kubeconfig is created: the first reachable one is the server of its
+	// current context, while the other ones are added as further contexts, used for failover.
+	Endpoints []string `json:"endpoints,omitempty"`
+	// CaData contains the PEM encoded CA certificate of the API server, to be used instead of the ServiceAccount one
+	// (e.g. if the endpoints are exposed by a load balancer with its own certificate).
+	CaData []byte `json:"caData,omitempty"`
+	// CredentialsTtl, if set, is the validity (in seconds) of the credentials embedded in the kubeconfigs created for
+	// the foreign clusters: the tokens are requested through the TokenRequest API, rotated when 80% of their validity
+	// elapsed and revoked when the foreign cluster unjoins. If not set, the long-lived ServiceAccount tokens are used.
+	// +kubebuilder:validation:Minimum=600
+	CredentialsTtl int64 `json:"credentialsTtl,omitempty"`
 }
 
 //AdvertisementConfig defines the configuration for the advertisement protocol
@@ -48,17 +127,87 @@ type AdvertisementConfig struct {
 	KeepaliveRetryTime int32 `json:"keepaliveRetryTime,omitempty"`
 	// LabelPolicies contains the policies for each label to be added to remote virtual nodes
 	LabelPolicies []LabelPolicy `json:"labelPolicies,omitempty"`
+	// NodeSelector selects the physical nodes whose resources are announced to the foreign clusters.
+	// The pods offloaded by the foreign clusters are scheduled only on the selected nodes.
+	// If not set, all the physical nodes are selected.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// NodePoolLabel is the label identifying the node pool of the physical nodes.
+	// If set, the resources of each node pool are announced to the foreign clusters.
+	NodePoolLabel string `json:"nodePoolLabel,omitempty"`
 }
 
 type BroadcasterConfig struct {
-	//ResourceSharingPercentage defines the percentage of your cluster resources that you will share with foreign clusters.
+	//ResourceSharingPercentage defines the percentage of your cluster resources that you will share with each foreign cluster.
+	//It can be overridden for a specific foreign cluster through a ClusterSharingQuota.
 	// +kubebuilder:validation:Maximum=100
 	// +kubebuilder:validation:Minimum=0
 	ResourceSharingPercentage int32 `json:"resourceSharingPercentage"`
+	//ClusterSharingQuotas defines the resources shared with specific foreign clusters.
+	ClusterSharingQuotas []ClusterSharingQuota `json:"clusterSharingQuotas,omitempty"`
+	//MaxTotalSharingPercentage defines the maximum percentage of your cluster resources that can be announced to all the foreign clusters altogether.
+	//Each foreign cluster is offered at most the resources not yet announced to the other ones.
+	//Values higher than 100 allow to over-commit your cluster resources. If not set, it defaults to 100.
+	// +kubebuilder:validation:Minimum=0
+	MaxTotalSharingPercentage *int32 `json:"maxTotalSharingPercentage,omitempty"`
 	//EnableBroadcaster flag allows you to enable/disable the broadcasting of your Advertisement to the foreign clusters.
 	//When EnableBroadcaster is set to false, the home cluster notifies to the foreign he wants to stop sharing resources.
 	//This will trigger the deletion of the virtual-kubelet and, after that, of the Advertisement,
 	EnableBroadcaster bool `json:"enableBroadcaster"`
+	//PricingConfig defines how the prices announced in the Advertisement are computed.
+	PricingConfig PricingConfig `json:"pricingConfig,omitempty"`
+}
+
+// ClusterSharingQuota defines the resources shared with a specific foreign cluster
+type ClusterSharingQuota struct {
+	// ClusterID is the identifier of the foreign cluster
+	ClusterID string `json:"clusterID"`
+	// Percentage defines the percentage of your cluster resources shared with the foreign cluster.
+	// If not set, the ResourceSharingPercentage is used.
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	Percentage *int32 `json:"percentage,omitempty"`
+	// Resources defines the maximum quantity of each resource shared with the foreign cluster.
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// PricingConfig defines the pricing model applied to the resources announced to foreign clusters
+type PricingConfig struct {
+	// BasePrices contains the base price for every kind of resource (cpu, memory...).
+	// Resources not listed here are announced with the default price.
+	BasePrices corev1.ResourceList `json:"basePrices,omitempty"`
+	// SurgeThreshold is the utilisation percentage of a resource above which its price starts to increase.
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	SurgeThreshold int32 `json:"surgeThreshold,omitempty"`
+	// SurgePercentage is the percentage added to the base price of a resource when its utilisation reaches 100%.
+	// Between SurgeThreshold and 100% the increase is linear.
+	// +kubebuilder:validation:Minimum=0
+	SurgePercentage int32 `json:"surgePercentage,omitempty"`
+	// ClusterDiscounts contains the discounts granted to specific consumer clusters.
+	ClusterDiscounts []ClusterDiscount `json:"clusterDiscounts,omitempty"`
+	// ImagePrices contains the prices of the images already stored in the cluster.
+	ImagePrices []ImagePrice `json:"imagePrices,omitempty"`
+	// DefaultImagePrice is the price of the images not matching any ImagePrice.
+	// If not set, those images are announced with the default price.
+	DefaultImagePrice *resource.Quantity `json:"defaultImagePrice,omitempty"`
+}
+
+// ClusterDiscount defines the discount granted to a consumer cluster
+type ClusterDiscount struct {
+	// ClusterID is the identifier of the consumer cluster
+	ClusterID string `json:"clusterID"`
+	// Percentage is the discount applied to every price announced to the consumer cluster
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	Percentage int32 `json:"percentage"`
+}
+
+// ImagePrice defines the price of the images whose name starts with a given prefix
+type ImagePrice struct {
+	// Prefix is matched against the names of the images stored in the cluster
+	Prefix string `json:"prefix"`
+	// Price is the price announced for the matching images
+	Price resource.Quantity `json:"price"`
 }
 
 // AcceptPolicy defines the policy to accept/refuse an Advertisement
@@ -73,6 +222,18 @@ const (
 	ManualAccept AcceptPolicy = "Manual"
 )
 
+// VirtualNodeSplit defines how the resources of a foreign cluster are mapped on virtual nodes
+type VirtualNodeSplit string
+
+const (
+	// SplitNone means a single virtual node is created for each foreign cluster
+	SplitNone VirtualNodeSplit = "None"
+	// SplitByZone means a virtual node is created for each zone announced by the foreign cluster
+	SplitByZone VirtualNodeSplit = "Zone"
+	// SplitByNodePool means a virtual node is created for each node pool announced by the foreign cluster
+	SplitByNodePool VirtualNodeSplit = "NodePool"
+)
+
 type AdvOperatorConfig struct {
 	// MaxAcceptableAdvertisement defines the maximum number of Advertisements that can be accepted over time.
 	// The maximum value for this field is set to 1000000, a symbolic value that implements the AcceptAll policy.
@@ -85,6 +246,17 @@ type AdvOperatorConfig struct {
 	// Manual means every Advertisement received will need a manual accept/refuse, which can be done by updating its status.
 	// +kubebuilder:validation:Enum="AutoAcceptMax";"Manual"
 	AcceptPolicy AcceptPolicy `json:"acceptPolicy"`
+	// MaxAcceptablePrices defines the maximum price that can be accepted for every kind of resource.
+	// Advertisements announcing a higher price for one of the listed resources are refused.
+	MaxAcceptablePrices corev1.ResourceList `json:"maxAcceptablePrices,omitempty"`
+	// VirtualNodeSplit defines how many virtual nodes are created for each accepted Advertisement.
+	// None means a single virtual node for the whole foreign cluster;
+	// Zone and NodePool mean a virtual node for each zone or node pool announced in the Advertisement topology,
+	// each one with its own capacity and labels. If the foreign cluster does not announce any zone or node pool,
+	// a single virtual node is created.
+	// +kubebuilder:validation:Enum="None";"Zone";"NodePool"
+	// +kubebuilder:default="None"
+	VirtualNodeSplit VirtualNodeSplit `json:"virtualNodeSplit,omitempty"`
 }
 
 // LabelPolicy define a key-value structure to indicate which keys have to be aggregated and with which policy
@@ -92,9 +264,21 @@ type LabelPolicy struct {
 	// Label Key to be aggregated in new virtual nodes
 	Key string `json:"key"`
 	// Merge labels Policy
-	// +kubebuilder:validation:Enum="LabelPolicyAnyTrue";"LabelPolicyAllTrue";"LabelPolicyAnyTrueNoLabelIfFalse";"LabelPolicyAllTrueNoLabelIfFalse"
+	// +kubebuilder:validation:Enum="LabelPolicyAnyTrue";"LabelPolicyAllTrue";"LabelPolicyAnyTrueNoLabelIfFalse";"LabelPolicyAllTrueNoLabelIfFalse";"LabelPolicyMajority";"LabelPolicyUnion";"LabelPolicyIntersection";"LabelPolicyMin";"LabelPolicyMax";"LabelPolicyRegex"
 	// +kubebuilder:default="LabelPolicyAnyTrue"
 	Policy labelPolicy.LabelPolicyType `json:"policy,omitempty"`
+	// Separator of the elements of the label values aggregated by the Union and Intersection policies. If not set,
+	// the values are not split and the Union policy advertises the label only if the nodes share the same value
+	// +kubebuilder:validation:Pattern="^[-_.]$"
+	// +optional
+	Separator string `json:"separator,omitempty"`
+	// Regex selecting the label values aggregated by the value policies, the nodes whose value does not match
+	// it are considered as not having the label. Required by the Regex policy.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// Replacement transforming the values matching the regex, it can reference its submatches, e.g. "$1"
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
 }
 
 type DiscoveryConfig struct {
@@ -114,9 +298,142 @@ type DiscoveryConfig struct {
 
 	EnableDiscovery     bool `json:"enableDiscovery"`
 	EnableAdvertisement bool `json:"enableAdvertisement"`
+	// Interfaces selects the network interfaces used to register and resolve the mDNS services.
+	// If not set, all the multicast interfaces having an IPv4 address out of the pod networks are used.
+	Interfaces *MdnsInterfaceConfig `json:"interfaces,omitempty"`
 
 	AutoJoin          bool `json:"autojoin"`
 	AutoJoinUntrusted bool `json:"autojoinUntrusted"`
+	// AutoJoinRequirements defines the capabilities the clusters discovered in LAN have to announce to be automatically joined
+	AutoJoinRequirements *CapabilityRequirements `json:"autoJoinRequirements,omitempty"`
+	// Unpeering defines how the workloads offloaded to a foreign cluster are drained before unpeering from it
+	Unpeering UnpeeringConfig `json:"unpeering,omitempty"`
+
+	// --- WAN ---
+
+	// Dnssec defines how the DNS answers retrieved by the WAN discovery are validated
+	Dnssec DnssecConfig `json:"dnssec,omitempty"`
+	// DnsPublication, if set, publishes the local cluster in a DNS zone through dynamic updates (RFC 2136),
+	// in order to be discovered by the clusters having a SearchDomain for that zone
+	DnsPublication *DnsPublicationConfig `json:"dnsPublication,omitempty"`
+
+	// --- Registry ---
+
+	// RegistryPublication, if set, publishes the local cluster in a discovery registry,
+	// in order to be discovered by the clusters having a SearchDomain for that registry
+	RegistryPublication *RegistryPublicationConfig `json:"registryPublication,omitempty"`
+
+	// --- Garbage collection ---
+
+	// GarbageCollection defines how the stale ForeignClusters and the resources left by the peerings with
+	// the deleted ForeignClusters are collected
+	GarbageCollection GarbageCollectionConfig `json:"garbageCollection,omitempty"`
+}
+
+// UnpeeringConfig defines how the virtual node of a foreign cluster is drained before unpeering from it
+type UnpeeringConfig struct {
+	// DrainTimeout is the maximum time (in seconds) spent evicting the pods running on the virtual node,
+	// the peering is torn down when it expires even if some pods have not been evicted, e.g. because of
+	// their PodDisruptionBudgets. If 0, the pods are not evicted and the peering is torn down immediately.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	DrainTimeout uint32 `json:"drainTimeout,omitempty"`
+}
+
+// GarbageCollectionConfig defines how often and which resources are deleted by the discovery garbage collector
+type GarbageCollectionConfig struct {
+	// Period is the interval (in seconds) between two collections.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	Period uint32 `json:"period,omitempty"`
+	// WanTtl is the time (in seconds) after which a ForeignCluster discovered in WAN is deleted, if its SearchDomain
+	// has not found it again since then. The ForeignClusters we are peered with are kept.
+	// If 0, the ForeignClusters discovered in WAN are deleted only when their SearchDomain stops returning them.
+	WanTtl uint32 `json:"wanTtl,omitempty"`
+	// DryRun, if set, disables the deletions: the resources that would be deleted are logged
+	// and listed in the discovery-gc-report ConfigMap.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// MdnsInterfaceConfig selects the network interfaces used by the mDNS discovery on multi-homed nodes
+type MdnsInterfaceConfig struct {
+	// Allow contains the names of the interfaces to be used, shell patterns (e.g. "eth*") are accepted.
+	// If empty, all the interfaces are allowed.
+	Allow []string `json:"allow,omitempty"`
+	// Deny contains the names of the interfaces never to be used, shell patterns are accepted.
+	Deny []string `json:"deny,omitempty"`
+	// Cidrs contains the networks the interfaces have to be connected to: if not empty,
+	// an interface is used only if one of its addresses belongs to one of them.
+	Cidrs []string `json:"cidrs,omitempty"`
+	// ApiUrls contains the URL of the API server announced on an interface, indexed by interface name.
+	// If an interface is missing, the address of the master node in the same subnet of the interface is announced.
+	ApiUrls map[string]string `json:"apiUrls,omitempty"`
+}
+
+// DnsPublicationConfig defines where and how the local cluster is published in a DNS zone
+type DnsPublicationConfig struct {
+	// Zone is the DNS zone to be updated. The PTR record pointing to the local cluster is added to the zone apex,
+	// while its SRV and TXT records are added to <cluster-id>.<zone>.
+	Zone string `json:"zone"`
+	// Server is the address (host:port) of the primary DNS server accepting the updates for the zone.
+	// If the port is missing, 53 is used.
+	Server string `json:"server"`
+	// Ttl is the TTL (in seconds) of the published records, which are refreshed every Ttl/2 seconds.
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:default=300
+	Ttl uint32 `json:"ttl,omitempty"`
+	// Tsig authenticates the updates sent to the DNS server.
+	Tsig *TsigConfig `json:"tsig,omitempty"`
+}
+
+// DnssecConfig defines how the DNS answers retrieved by the WAN discovery are validated
+type DnssecConfig struct {
+	// TrustAnchors contains the DS or DNSKEY records, in zone file format, trusted as secure entry points of the
+	// DNSSEC chain of trust (e.g. the DS record of the root zone or of the searched domain).
+	// If set, the signatures of the PTR, SRV and TXT records are validated locally.
+	TrustAnchors []string `json:"trustAnchors,omitempty"`
+	// Tsig authenticates the messages exchanged with the DNS server.
+	// If set and no TrustAnchors are provided, the answers are validated by the DNS server,
+	// which has to set the Authenticated Data flag.
+	Tsig *TsigConfig `json:"tsig,omitempty"`
+}
+
+// TsigConfig defines the key used to sign the DNS messages with TSIG (RFC 8945)
+type TsigConfig struct {
+	// KeyName is the name of the TSIG key.
+	KeyName string `json:"keyName"`
+	// Algorithm is the HMAC algorithm of the TSIG key.
+	// +kubebuilder:validation:Enum="hmac-sha1";"hmac-sha256";"hmac-sha512"
+	// +kubebuilder:default="hmac-sha256"
+	Algorithm string `json:"algorithm,omitempty"`
+	// SecretRef references the Secret containing the base64 encoded TSIG secret in its "secret" key.
+	SecretRef corev1.SecretReference `json:"secretRef"`
+}
+
+// CapabilityRequirements defines the capabilities a discovered cluster has to announce to be automatically joined,
+// it has the same fields of the CapabilityRequirements of the SearchDomains
+type CapabilityRequirements struct {
+	// Minimum version of the schema of the discovery record
+	MinTxtVersion int `json:"minTxtVersion,omitempty"`
+	// The foreign cluster has to support at least one of these tunnel backends
+	TunnelBackends []string `json:"tunnelBackends,omitempty"`
+	// The foreign cluster has to serve all these API groups (group/version)
+	ApiGroups []string `json:"apiGroups,omitempty"`
+	// The foreign cluster has to accept incoming peerings
+	AcceptsPeering bool `json:"acceptsPeering,omitempty"`
+}
+
+// RegistryPublicationConfig defines where and how the local cluster is published in a discovery registry
+type RegistryPublicationConfig struct {
+	// Url is the HTTP(S) endpoint of the registry
+	Url string `json:"url"`
+	// Ttl is the TTL (in seconds) of the published record, which is refreshed every Ttl/2 seconds.
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:default=300
+	Ttl uint32 `json:"ttl,omitempty"`
+	// KeySecretRef references the Secret containing the PEM encoded (PKCS #8) ed25519 private key
+	// used to sign the record, in its "privateKey" key.
+	KeySecretRef corev1.SecretReference `json:"keySecretRef"`
 }
 
 type LiqonetConfig struct {
@@ -137,7 +454,48 @@ type Resource struct {
 	Group    string `json:"group"`
 	Version  string `json:"version"`
 	Resource string `json:"resource"`
+	// Rules defines which fields of the resource are replicated and in which direction.
+	// If not set, the spec and the status are copied to the remote replica and its status is copied back.
+	Rules *ReplicationRules `json:"rules,omitempty"`
+	// NamespaceMapping maps the namespaces of the local resources to the namespaces of their remote replicas.
+	// The resources in the namespaces not in the mapping are replicated in the same namespace.
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+	// Selector limits the replicated resources to the ones whose labels match it.
+	// If not set, all the resources with the replication label are replicated.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// PeerSelector limits the peering clusters the resources are replicated to, to the ones whose ForeignCluster
+	// labels match it. If not set, the resources are replicated to any peering cluster.
+	PeerSelector *metav1.LabelSelector `json:"peerSelector,omitempty"`
+}
+
+// ConflictResolution defines which cluster prevails on the fields replicated in both directions
+type ConflictResolution string
+
+const (
+	// the last change, made on either cluster, is copied to the other one
+	LastWriterWins ConflictResolution = "LastWriterWins"
+	// the changes made on the remote replica are not copied back and they are overwritten by the local values
+	LocalWins ConflictResolution = "LocalWins"
+	// the changes made on the local resource are not copied to the remote replica, whose values are copied back
+	RemoteWins ConflictResolution = "RemoteWins"
+)
+
+// ReplicationRules defines the field-level replication of a resource. The fields are identified by
+// dot-separated paths rooted at the spec or at the status, e.g. "spec" or "status.podCIDR".
+type ReplicationRules struct {
+	// LocalToRemote contains the fields owned by the local cluster, copied from the local resource to its remote replica.
+	LocalToRemote []string `json:"localToRemote,omitempty"`
+	// RemoteToLocal contains the fields owned by the remote cluster, copied from the remote replica to the local resource.
+	RemoteToLocal []string `json:"remoteToLocal,omitempty"`
+	// Ignored contains the fields that are never replicated, even if they are nested in a replicated field.
+	Ignored []string `json:"ignored,omitempty"`
+	// ConflictResolution applies to the fields replicated in both directions, i.e. the ones nested in both
+	// a LocalToRemote and a RemoteToLocal field.
+	// +kubebuilder:validation:Enum="LastWriterWins";"LocalWins";"RemoteWins"
+	// +kubebuilder:default="LastWriterWins"
+	ConflictResolution ConflictResolution `json:"conflictResolution,omitempty"`
 }
+
 type DispatcherConfig struct {
 	ResourcesToReplicate []Resource `json:"resourcesToReplicate,omitempty"`
 }
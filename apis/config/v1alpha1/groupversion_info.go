@@ -23,6 +23,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
 )
 
+// the typed clients of the resources are generated by hack/typed-client-gen
+//go:generate go run ../../../hack/typed-client-gen -kind ClusterConfig -var cc -header ../../../hack/boilerplate.go.txt
+
 var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "config.liqo.io", Version: "v1alpha1"}
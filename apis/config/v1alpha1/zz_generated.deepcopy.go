@@ -20,12 +20,21 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvOperatorConfig) DeepCopyInto(out *AdvOperatorConfig) {
 	*out = *in
+	if in.MaxAcceptablePrices != nil {
+		in, out := &in.MaxAcceptablePrices, &out.MaxAcceptablePrices
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvOperatorConfig.
@@ -41,13 +50,18 @@ func (in *AdvOperatorConfig) DeepCopy() *AdvOperatorConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvertisementConfig) DeepCopyInto(out *AdvertisementConfig) {
 	*out = *in
-	out.OutgoingConfig = in.OutgoingConfig
-	out.IngoingConfig = in.IngoingConfig
+	in.OutgoingConfig.DeepCopyInto(&out.OutgoingConfig)
+	in.IngoingConfig.DeepCopyInto(&out.IngoingConfig)
 	if in.LabelPolicies != nil {
 		in, out := &in.LabelPolicies, &out.LabelPolicies
 		*out = make([]LabelPolicy, len(*in))
 		copy(*out, *in)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvertisementConfig.
@@ -60,9 +74,47 @@ func (in *AdvertisementConfig) DeepCopy() *AdvertisementConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiServerConfig) DeepCopyInto(out *ApiServerConfig) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CaData != nil {
+		in, out := &in.CaData, &out.CaData
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiServerConfig.
+func (in *ApiServerConfig) DeepCopy() *ApiServerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiServerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BroadcasterConfig) DeepCopyInto(out *BroadcasterConfig) {
 	*out = *in
+	if in.ClusterSharingQuotas != nil {
+		in, out := &in.ClusterSharingQuotas, &out.ClusterSharingQuotas
+		*out = make([]ClusterSharingQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxTotalSharingPercentage != nil {
+		in, out := &in.MaxTotalSharingPercentage, &out.MaxTotalSharingPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	in.PricingConfig.DeepCopyInto(&out.PricingConfig)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcasterConfig.
@@ -75,6 +127,31 @@ func (in *BroadcasterConfig) DeepCopy() *BroadcasterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityRequirements) DeepCopyInto(out *CapabilityRequirements) {
+	*out = *in
+	if in.TunnelBackends != nil {
+		in, out := &in.TunnelBackends, &out.TunnelBackends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApiGroups != nil {
+		in, out := &in.ApiGroups, &out.ApiGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityRequirements.
+func (in *CapabilityRequirements) DeepCopy() *CapabilityRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfig) DeepCopyInto(out *ClusterConfig) {
 	*out = *in
@@ -138,9 +215,11 @@ func (in *ClusterConfigList) DeepCopyObject() runtime.Object {
 func (in *ClusterConfigSpec) DeepCopyInto(out *ClusterConfigSpec) {
 	*out = *in
 	in.AdvertisementConfig.DeepCopyInto(&out.AdvertisementConfig)
-	out.DiscoveryConfig = in.DiscoveryConfig
+	in.DiscoveryConfig.DeepCopyInto(&out.DiscoveryConfig)
 	in.LiqonetConfig.DeepCopyInto(&out.LiqonetConfig)
 	in.DispatcherConfig.DeepCopyInto(&out.DispatcherConfig)
+	in.ApiServerConfig.DeepCopyInto(&out.ApiServerConfig)
+	in.PodMutatorConfig.DeepCopyInto(&out.PodMutatorConfig)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfigSpec.
@@ -168,9 +247,74 @@ func (in *ClusterConfigStatus) DeepCopy() *ClusterConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDiscount) DeepCopyInto(out *ClusterDiscount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiscount.
+func (in *ClusterDiscount) DeepCopy() *ClusterDiscount {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDiscount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSharingQuota) DeepCopyInto(out *ClusterSharingQuota) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSharingQuota.
+func (in *ClusterSharingQuota) DeepCopy() *ClusterSharingQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSharingQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiscoveryConfig) DeepCopyInto(out *DiscoveryConfig) {
 	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = new(MdnsInterfaceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoJoinRequirements != nil {
+		in, out := &in.AutoJoinRequirements, &out.AutoJoinRequirements
+		*out = new(CapabilityRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Unpeering = in.Unpeering
+	in.Dnssec.DeepCopyInto(&out.Dnssec)
+	if in.DnsPublication != nil {
+		in, out := &in.DnsPublication, &out.DnsPublication
+		*out = new(DnsPublicationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryPublication != nil {
+		in, out := &in.RegistryPublication, &out.RegistryPublication
+		*out = new(RegistryPublicationConfig)
+		**out = **in
+	}
+	out.GarbageCollection = in.GarbageCollection
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryConfig.
@@ -189,7 +333,9 @@ func (in *DispatcherConfig) DeepCopyInto(out *DispatcherConfig) {
 	if in.ResourcesToReplicate != nil {
 		in, out := &in.ResourcesToReplicate, &out.ResourcesToReplicate
 		*out = make([]Resource, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -203,6 +349,82 @@ func (in *DispatcherConfig) DeepCopy() *DispatcherConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DnsPublicationConfig) DeepCopyInto(out *DnsPublicationConfig) {
+	*out = *in
+	if in.Tsig != nil {
+		in, out := &in.Tsig, &out.Tsig
+		*out = new(TsigConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DnsPublicationConfig.
+func (in *DnsPublicationConfig) DeepCopy() *DnsPublicationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DnsPublicationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DnssecConfig) DeepCopyInto(out *DnssecConfig) {
+	*out = *in
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tsig != nil {
+		in, out := &in.Tsig, &out.Tsig
+		*out = new(TsigConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DnssecConfig.
+func (in *DnssecConfig) DeepCopy() *DnssecConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DnssecConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectionConfig) DeepCopyInto(out *GarbageCollectionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectionConfig.
+func (in *GarbageCollectionConfig) DeepCopy() *GarbageCollectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrice) DeepCopyInto(out *ImagePrice) {
+	*out = *in
+	out.Price = in.Price.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrice.
+func (in *ImagePrice) DeepCopy() *ImagePrice {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LabelPolicy) DeepCopyInto(out *LabelPolicy) {
 	*out = *in
@@ -239,9 +461,215 @@ func (in *LiqonetConfig) DeepCopy() *LiqonetConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MdnsInterfaceConfig) DeepCopyInto(out *MdnsInterfaceConfig) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cidrs != nil {
+		in, out := &in.Cidrs, &out.Cidrs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApiUrls != nil {
+		in, out := &in.ApiUrls, &out.ApiUrls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MdnsInterfaceConfig.
+func (in *MdnsInterfaceConfig) DeepCopy() *MdnsInterfaceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MdnsInterfaceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffloadingRule) DeepCopyInto(out *OffloadingRule) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedClusters != nil {
+		in, out := &in.AllowedClusters, &out.AllowedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourcesMutation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffloadingRule.
+func (in *OffloadingRule) DeepCopy() *OffloadingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(OffloadingRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodMutatorConfig) DeepCopyInto(out *PodMutatorConfig) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]OffloadingRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMutatorConfig.
+func (in *PodMutatorConfig) DeepCopy() *PodMutatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodMutatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PricingConfig) DeepCopyInto(out *PricingConfig) {
+	*out = *in
+	if in.BasePrices != nil {
+		in, out := &in.BasePrices, &out.BasePrices
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ClusterDiscounts != nil {
+		in, out := &in.ClusterDiscounts, &out.ClusterDiscounts
+		*out = make([]ClusterDiscount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePrices != nil {
+		in, out := &in.ImagePrices, &out.ImagePrices
+		*out = make([]ImagePrice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultImagePrice != nil {
+		in, out := &in.DefaultImagePrice, &out.DefaultImagePrice
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingConfig.
+func (in *PricingConfig) DeepCopy() *PricingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PricingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryPublicationConfig) DeepCopyInto(out *RegistryPublicationConfig) {
+	*out = *in
+	out.KeySecretRef = in.KeySecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryPublicationConfig.
+func (in *RegistryPublicationConfig) DeepCopy() *RegistryPublicationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryPublicationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationRules) DeepCopyInto(out *ReplicationRules) {
+	*out = *in
+	if in.LocalToRemote != nil {
+		in, out := &in.LocalToRemote, &out.LocalToRemote
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoteToLocal != nil {
+		in, out := &in.RemoteToLocal, &out.RemoteToLocal
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ignored != nil {
+		in, out := &in.Ignored, &out.Ignored
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationRules.
+func (in *ReplicationRules) DeepCopy() *ReplicationRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resource) DeepCopyInto(out *Resource) {
 	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = new(ReplicationRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PeerSelector != nil {
+		in, out := &in.PeerSelector, &out.PeerSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Resource.
@@ -253,3 +681,56 @@ func (in *Resource) DeepCopy() *Resource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcesMutation) DeepCopyInto(out *ResourcesMutation) {
+	*out = *in
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcesMutation.
+func (in *ResourcesMutation) DeepCopy() *ResourcesMutation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcesMutation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TsigConfig) DeepCopyInto(out *TsigConfig) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TsigConfig.
+func (in *TsigConfig) DeepCopy() *TsigConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TsigConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnpeeringConfig) DeepCopyInto(out *UnpeeringConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnpeeringConfig.
+func (in *UnpeeringConfig) DeepCopy() *UnpeeringConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UnpeeringConfig)
+	in.DeepCopyInto(out)
+	return out
+}
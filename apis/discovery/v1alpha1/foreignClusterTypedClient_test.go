@@ -0,0 +1,199 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"github.com/stretchr/testify/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createFakeForeignCluster(name string) *ForeignCluster {
+	return &ForeignCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       "ForeignCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: ForeignClusterSpec{
+			ClusterIdentity: ClusterIdentity{
+				ClusterID: name,
+			},
+			Join: true,
+		},
+	}
+}
+
+func waitForeignCluster(t *testing.T, ch chan *ForeignCluster) *ForeignCluster {
+	select {
+	case fc := <-ch:
+		return fc
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called")
+		return nil
+	}
+}
+
+func TestForeignClusterClient_Fake(t *testing.T) {
+	crdClient.Fake = true
+	defer func() { crdClient.Fake = false }()
+	clientSet, err := crdClient.NewFromConfig(nil)
+	assert.Nil(t, err)
+
+	added := make(chan *ForeignCluster, 1)
+	updated := make(chan *ForeignCluster, 1)
+	deleted := make(chan *ForeignCluster, 1)
+	lister, _, err := WatchForeignClusters(clientSet, 0, ForeignClusterHandlerFuncs{
+		AddFunc:    func(fc *ForeignCluster) { added <- fc },
+		UpdateFunc: func(oldFc, newFc *ForeignCluster) { updated <- newFc },
+		DeleteFunc: func(fc *ForeignCluster) { deleted <- fc },
+	}, metav1.ListOptions{})
+	assert.Nil(t, err)
+	client := NewForeignClusterClient(clientSet)
+
+	fc, err := client.Create(context.TODO(), createFakeForeignCluster("cluster-1"), metav1.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "cluster-1", fc.Spec.ClusterIdentity.ClusterID)
+	assert.Equal(t, "cluster-1", waitForeignCluster(t, added).Name)
+	_, err = client.Create(context.TODO(), createFakeForeignCluster("cluster-1"), metav1.CreateOptions{})
+	assert.True(t, kerrors.IsAlreadyExists(err))
+
+	//the returned objects are copies of the stored ones
+	fc.Spec.Join = false
+	fc, err = client.Get(context.TODO(), "cluster-1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.True(t, fc.Spec.Join)
+
+	fc.Spec.Join = false
+	fc, err = client.Update(context.TODO(), fc, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	assert.False(t, fc.Spec.Join)
+	assert.False(t, waitForeignCluster(t, updated).Spec.Join)
+
+	_, err = client.Create(context.TODO(), createFakeForeignCluster("cluster-2"), metav1.CreateOptions{})
+	assert.Nil(t, err)
+	waitForeignCluster(t, added)
+	list, err := client.List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list.Items))
+	assert.Equal(t, 2, len(lister.List()))
+	cached, found, err := lister.Get("cluster-2")
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "cluster-2", cached.Spec.ClusterIdentity.ClusterID)
+
+	assert.Nil(t, client.Delete(context.TODO(), "cluster-2", metav1.DeleteOptions{}))
+	assert.Equal(t, "cluster-2", waitForeignCluster(t, deleted).Name)
+	_, err = client.Get(context.TODO(), "cluster-2", metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+	_, found, err = lister.Get("cluster-2")
+	assert.Nil(t, err)
+	assert.False(t, found)
+	assert.True(t, kerrors.IsNotFound(client.Delete(context.TODO(), "cluster-2", metav1.DeleteOptions{})))
+
+	//the merge patches are applied to the stored object
+	fc, err = client.Patch(context.TODO(), "cluster-1", types.MergePatchType, []byte(`{"spec":{"join":true},"metadata":{"labels":{"key":"value"}}}`), metav1.PatchOptions{})
+	assert.Nil(t, err)
+	assert.True(t, fc.Spec.Join)
+	assert.Equal(t, "value", fc.Labels["key"])
+	assert.Equal(t, "cluster-1", fc.Spec.ClusterIdentity.ClusterID)
+	assert.True(t, waitForeignCluster(t, updated).Spec.Join)
+
+	//the requests fail when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Get(ctx, "cluster-1", metav1.GetOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestForeignClusterClient_REST(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		assert.Equal(t, "/apis/discovery.liqo.io/v1alpha1/foreignclusters/cluster-1", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(createFakeForeignCluster("cluster-1"))
+	}))
+	defer server.Close()
+	config := &rest.Config{
+		Host:    server.URL,
+		APIPath: "/apis",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &GroupVersion,
+			ContentType:          "application/json",
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+	}
+	clientSet, err := crdClient.NewFromConfig(config)
+	assert.Nil(t, err)
+
+	fc, err := NewForeignClusterClient(clientSet).Get(context.TODO(), "cluster-1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "cluster-1", fc.Spec.ClusterIdentity.ClusterID)
+	assert.True(t, fc.Spec.Join)
+
+	//server-side apply
+	_, err = NewForeignClusterClient(clientSet).Patch(context.TODO(), "cluster-1", types.ApplyPatchType, []byte(`{"spec":{"join":true}}`), metav1.PatchOptions{FieldManager: "discovery"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, http.MethodPatch, requests[1].Method)
+	assert.Equal(t, string(types.ApplyPatchType), requests[1].Header.Get("Content-Type"))
+	assert.Equal(t, "discovery", requests[1].URL.Query().Get("fieldManager"))
+}
+
+func TestForeignClusterHandlerFuncs_Tombstone(t *testing.T) {
+	var deleted *ForeignCluster
+	handlers := ForeignClusterHandlerFuncs{
+		DeleteFunc: func(fc *ForeignCluster) { deleted = fc },
+	}.ResourceEventHandlerFuncs()
+
+	handlers.DeleteFunc(cache.DeletedFinalStateUnknown{Key: "cluster-1", Obj: createFakeForeignCluster("cluster-1")})
+	assert.NotNil(t, deleted)
+	assert.Equal(t, "cluster-1", deleted.Name)
+
+	//the objects of other types are discarded, the nil handlers are skipped
+	deleted = nil
+	handlers.DeleteFunc(&PeeringRequest{})
+	assert.Nil(t, deleted)
+	handlers.AddFunc(createFakeForeignCluster("cluster-1"))
+}
+
+func TestForeignClusterLister_StoreError(t *testing.T) {
+	lister := NewForeignClusterLister(&cache.FakeCustomStore{
+		GetByKeyFunc: func(key string) (interface{}, bool, error) {
+			return nil, false, errors.New("injected")
+		},
+	})
+	_, found, err := lister.Get("cluster-1")
+	assert.NotNil(t, err, "the errors of the store should not be swallowed")
+	assert.False(t, found)
+}
+
+func TestWatchForeignClusters_Store(t *testing.T) {
+	crdClient.Fake = true
+	defer func() { crdClient.Fake = false }()
+	clientSet, err := crdClient.NewFromConfig(nil)
+	assert.Nil(t, err)
+
+	_, stop, err := WatchForeignClusters(clientSet, 0, ForeignClusterHandlerFuncs{}, metav1.ListOptions{})
+	assert.Nil(t, err)
+	store := clientSet.Store
+	assert.NotNil(t, store)
+	_, _, err = WatchForeignClusters(clientSet, 0, ForeignClusterHandlerFuncs{}, metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.True(t, store == clientSet.Store, "the Store of the clients should not be replaced by the next caches")
+	assert.Nil(t, clientSet.Stop, "the cache should be stopped through the returned channel")
+	close(stop)
+}
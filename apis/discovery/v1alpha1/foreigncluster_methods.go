@@ -98,7 +98,7 @@ func (fc *ForeignCluster) LoadForeignCA(localClient kubernetes.Interface, localN
 			Name: fc.Name + "-ca-data",
 			OwnerReferences: []metav1.OwnerReference{
 				{
-					APIVersion: "v1alpha1",
+					APIVersion: GroupVersion.String(),
 					Kind:       "ForeignCluster",
 					Name:       fc.Name,
 					UID:        fc.UID,
@@ -177,6 +177,11 @@ func (fc *ForeignCluster) LastUpdateNow() {
 }
 
 func (fc *ForeignCluster) IsExpired() bool {
+	return fc.IsOutdated(fc.Status.Ttl)
+}
+
+// IsOutdated returns true if the ForeignCluster has not been updated by the discovery in the last ttl seconds
+func (fc *ForeignCluster) IsOutdated(ttl uint32) bool {
 	ann := fc.GetAnnotations()
 	if ann == nil {
 		return false
@@ -192,5 +197,49 @@ func (fc *ForeignCluster) IsExpired() bool {
 		return true
 	}
 	now := time.Now().Unix()
-	return int64(lu+int(fc.Status.Ttl)) < now
+	return int64(lu+int(ttl)) < now
+}
+
+// Satisfies returns true if the capabilities match all the requirements
+// nil requirements are always satisfied, nil capabilities only by nil requirements
+func (c *ClusterCapabilities) Satisfies(req *CapabilityRequirements) bool {
+	if req == nil {
+		return true
+	}
+	if c == nil {
+		return false
+	}
+	if c.TxtVersion < req.MinTxtVersion {
+		return false
+	}
+	if req.AcceptsPeering && (c.AcceptsPeering == nil || !*c.AcceptsPeering) {
+		return false
+	}
+	if len(req.TunnelBackends) > 0 {
+		found := false
+		for _, backend := range req.TunnelBackends {
+			if containsString(c.TunnelBackends, backend) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, group := range req.ApiGroups {
+		if !containsString(c.ApiGroups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
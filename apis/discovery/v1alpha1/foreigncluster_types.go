@@ -35,6 +35,7 @@ const (
 	WanDiscovery             DiscoveryType = "WAN"
 	ManualDiscovery          DiscoveryType = "Manual"
 	IncomingPeeringDiscovery DiscoveryType = "IncomingPeering"
+	RegistryDiscovery        DiscoveryType = "Registry"
 )
 
 type TrustMode string
@@ -64,6 +65,10 @@ type ForeignClusterSpec struct {
 	ApiUrl string `json:"apiUrl"`
 	// How this ForeignCluster has been discovered
 	DiscoveryType DiscoveryType `json:"discoveryType"`
+	// Indicates if the DNS records this ForeignCluster has been discovered from have been validated with DNSSEC.
+	// When DNSSEC validation is configured, the auto-join of a SearchDomain never applies to the ForeignClusters
+	// discovered from unvalidated records.
+	DnssecValidated bool `json:"dnssecValidated,omitempty"`
 }
 
 type ClusterIdentity struct {
@@ -80,7 +85,7 @@ type ForeignClusterStatus struct {
 
 	Outgoing Outgoing `json:"outgoing,omitempty"`
 	Incoming Incoming `json:"incoming,omitempty"`
-	// If discoveryType is LAN and this counter reach 0 value, this FC will be removed
+	// If discoveryType is LAN or Registry and this TTL expires, this FC will be removed
 	Ttl uint32 `json:"ttl,omitempty"`
 	// +kubebuilder:validation:Enum="Unknown";"Trusted";"Untrusted"
 	// +kubebuilder:default="Unknown"
@@ -88,6 +93,36 @@ type ForeignClusterStatus struct {
 	TrustMode TrustMode `json:"trustMode,omitempty"`
 	// It stores most important network statuses
 	Network Network `json:"network,omitempty"`
+	// Capabilities announced by the foreign cluster in its discovery record
+	Capabilities *ClusterCapabilities `json:"capabilities,omitempty"`
+	// Status of the connection used by the CRDReplicator to replicate the resources to this remote cluster
+	Replication *ReplicationStatus `json:"replication,omitempty"`
+}
+
+// ClusterCapabilities describes what a foreign cluster supports, as announced in its discovery record
+type ClusterCapabilities struct {
+	// Version of the schema of the discovery record, 1 if the record is not versioned
+	TxtVersion int `json:"txtVersion"`
+	// Liqo version running on the foreign cluster
+	LiqoVersion string `json:"liqoVersion,omitempty"`
+	// Technologies supported to build the tunnel to the foreign cluster
+	TunnelBackends []string `json:"tunnelBackends,omitempty"`
+	// Liqo API groups (group/version) served by the foreign cluster
+	ApiGroups []string `json:"apiGroups,omitempty"`
+	// Indicates if the foreign cluster accepts incoming peerings, not set if unknown
+	AcceptsPeering *bool `json:"acceptsPeering,omitempty"`
+}
+
+// CapabilityRequirements defines the capabilities a foreign cluster has to announce to be automatically joined
+type CapabilityRequirements struct {
+	// Minimum version of the schema of the discovery record
+	MinTxtVersion int `json:"minTxtVersion,omitempty"`
+	// The foreign cluster has to support at least one of these tunnel backends
+	TunnelBackends []string `json:"tunnelBackends,omitempty"`
+	// The foreign cluster has to serve all these API groups (group/version)
+	ApiGroups []string `json:"apiGroups,omitempty"`
+	// The foreign cluster has to accept incoming peerings
+	AcceptsPeering bool `json:"acceptsPeering,omitempty"`
 }
 
 type ResourceLink struct {
@@ -121,6 +156,44 @@ type Outgoing struct {
 	IdentityRef *v1.ObjectReference `json:"identityRef,omitempty"`
 	// Advertisement status
 	AdvertisementStatus advtypes.AdvPhase `json:"advertisementStatus,omitempty"`
+	// Expiration of the credentials sent to the remote cluster with the PeeringRequest, unset if they do not expire
+	CredentialsExpiration *metav1.Time `json:"credentialsExpiration,omitempty"`
+	// Progress of the unpeering, set while the virtual node of the remote cluster is drained
+	Unpeering *Unpeering `json:"unpeering,omitempty"`
+}
+
+// UnpeeringPhase is a phase of the graceful unpeering from a remote cluster
+type UnpeeringPhase string
+
+const (
+	// the virtual node of the remote cluster is made unschedulable
+	UnpeeringCordoning UnpeeringPhase = "Cordoning"
+	// the pods running on the virtual node are evicted, honoring their PodDisruptionBudgets
+	UnpeeringDraining UnpeeringPhase = "Draining"
+	// the PeeringRequest and the Advertisement are deleted, tearing down the virtual kubelet and the network
+	UnpeeringTearingDown UnpeeringPhase = "TearingDown"
+)
+
+type Unpeering struct {
+	// +kubebuilder:validation:Enum="Cordoning";"Draining";"TearingDown"
+	Phase UnpeeringPhase `json:"phase"`
+	// Time the unpeering started at, the draining is interrupted when the drain timeout expires
+	StartTime metav1.Time `json:"startTime"`
+	// Number of pods still running on the virtual node
+	RemainingPods int32 `json:"remainingPods,omitempty"`
+	// Human readable details about the progress, e.g. why some pods have not been evicted
+	Message string `json:"message,omitempty"`
+}
+
+type ReplicationStatus struct {
+	// Indicates if the API server of the remote cluster is reachable with the current credentials
+	Connected bool `json:"connected"`
+	// Last time the connection to the remote cluster has been verified
+	LastSync *metav1.Time `json:"lastSync,omitempty"`
+	// Error of the last failed connection attempt
+	Error string `json:"error,omitempty"`
+	// Time of the next connection attempt, set while the connection is retried with an exponential backoff
+	NextRetry *metav1.Time `json:"nextRetry,omitempty"`
 }
 
 type Incoming struct {
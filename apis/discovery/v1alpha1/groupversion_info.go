@@ -25,6 +25,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
 )
 
+// the typed clients of the resources are generated by hack/typed-client-gen
+//go:generate go run ../../../hack/typed-client-gen -kind ForeignCluster -var fc -header ../../../hack/boilerplate.go.txt
+//go:generate go run ../../../hack/typed-client-gen -kind PeeringRequest -var pr -header ../../../hack/boilerplate.go.txt
+//go:generate go run ../../../hack/typed-client-gen -kind SearchDomain -var sd -header ../../../hack/boilerplate.go.txt
+
 var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "discovery.liqo.io", Version: "v1alpha1"}
@@ -46,6 +46,8 @@ type PeeringRequestSpec struct {
 type PeeringRequestStatus struct {
 	BroadcasterRef      *object_references.DeploymentReference `json:"broadcasterRef,omitempty"`
 	AdvertisementStatus advtypes.AdvPhase                      `json:"advertisementStatus,omitempty"`
+	// AnnouncedResources are the resources announced to the foreign cluster in the last Advertisement
+	AnnouncedResources v1.ResourceList `json:"announcedResources,omitempty"`
 }
 
 // +kubebuilder:object:root=true
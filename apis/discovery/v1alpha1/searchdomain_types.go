@@ -32,10 +32,32 @@ type SearchDomainSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// DNS domain where to search for subscribed remote clusters
-	Domain string `json:"domain"`
+	// DNS domain where to search for subscribed remote clusters, required unless Registry is set
+	// +optional
+	Domain string `json:"domain,omitempty"`
 	// Enable join process for retrieved clusters
 	AutoJoin bool `json:"autojoin"`
+	// Capabilities the retrieved clusters have to announce to be automatically joined
+	AutoJoinRequirements *CapabilityRequirements `json:"autoJoinRequirements,omitempty"`
+	// Require the PTR, SRV and TXT records of the domain to be validated with DNSSEC.
+	// If set, the clusters whose records cannot be validated are ignored.
+	RequireDnssec bool `json:"requireDnssec,omitempty"`
+	// Automatically join the retrieved clusters also if DNSSEC validation is not available, i.e. neither trust anchors
+	// nor a TSIG key are configured. Otherwise, only the clusters whose records have been validated with DNSSEC are
+	// automatically joined. The clusters whose records fail the validation are never automatically joined.
+	AutoJoinWithoutDnssec bool `json:"autoJoinWithoutDnssec,omitempty"`
+	// Registry, if set, retrieves the remote clusters from a discovery registry instead of DNS
+	Registry *RegistrySource `json:"registry,omitempty"`
+}
+
+// RegistrySource defines the discovery registry where to search for subscribed remote clusters
+type RegistrySource struct {
+	// Url is the HTTP(S) endpoint of the registry
+	Url string `json:"url"`
+	// TrustedKeys contains the base64 encoded ed25519 public keys trusted to sign the records of the registry.
+	// Records signed by other keys are ignored.
+	// +kubebuilder:validation:MinItems=1
+	TrustedKeys []string `json:"trustedKeys"`
 }
 
 // SearchDomainStatus defines the observed state of SearchDomain
@@ -25,6 +25,61 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityRequirements) DeepCopyInto(out *CapabilityRequirements) {
+	*out = *in
+	if in.TunnelBackends != nil {
+		in, out := &in.TunnelBackends, &out.TunnelBackends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApiGroups != nil {
+		in, out := &in.ApiGroups, &out.ApiGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityRequirements.
+func (in *CapabilityRequirements) DeepCopy() *CapabilityRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCapabilities) DeepCopyInto(out *ClusterCapabilities) {
+	*out = *in
+	if in.TunnelBackends != nil {
+		in, out := &in.TunnelBackends, &out.TunnelBackends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApiGroups != nil {
+		in, out := &in.ApiGroups, &out.ApiGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AcceptsPeering != nil {
+		in, out := &in.AcceptsPeering, &out.AcceptsPeering
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapabilities.
+func (in *ClusterCapabilities) DeepCopy() *ClusterCapabilities {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCapabilities)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterIdentity) DeepCopyInto(out *ClusterIdentity) {
 	*out = *in
@@ -121,6 +176,16 @@ func (in *ForeignClusterStatus) DeepCopyInto(out *ForeignClusterStatus) {
 	in.Outgoing.DeepCopyInto(&out.Outgoing)
 	in.Incoming.DeepCopyInto(&out.Incoming)
 	in.Network.DeepCopyInto(&out.Network)
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = new(ClusterCapabilities)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(ReplicationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForeignClusterStatus.
@@ -209,6 +274,15 @@ func (in *Outgoing) DeepCopyInto(out *Outgoing) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.CredentialsExpiration != nil {
+		in, out := &in.CredentialsExpiration, &out.CredentialsExpiration
+		*out = (*in).DeepCopy()
+	}
+	if in.Unpeering != nil {
+		in, out := &in.Unpeering, &out.Unpeering
+		*out = new(Unpeering)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Outgoing.
@@ -309,6 +383,13 @@ func (in *PeeringRequestStatus) DeepCopyInto(out *PeeringRequestStatus) {
 		*out = new(object_references.DeploymentReference)
 		**out = **in
 	}
+	if in.AnnouncedResources != nil {
+		in, out := &in.AnnouncedResources, &out.AnnouncedResources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeeringRequestStatus.
@@ -321,6 +402,49 @@ func (in *PeeringRequestStatus) DeepCopy() *PeeringRequestStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrySource) DeepCopyInto(out *RegistrySource) {
+	*out = *in
+	if in.TrustedKeys != nil {
+		in, out := &in.TrustedKeys, &out.TrustedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySource.
+func (in *RegistrySource) DeepCopy() *RegistrySource {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationStatus) DeepCopyInto(out *ReplicationStatus) {
+	*out = *in
+	if in.LastSync != nil {
+		in, out := &in.LastSync, &out.LastSync
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRetry != nil {
+		in, out := &in.NextRetry, &out.NextRetry
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationStatus.
+func (in *ReplicationStatus) DeepCopy() *ReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceLink) DeepCopyInto(out *ResourceLink) {
 	*out = *in
@@ -346,7 +470,7 @@ func (in *SearchDomain) DeepCopyInto(out *SearchDomain) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -403,6 +527,16 @@ func (in *SearchDomainList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchDomainSpec) DeepCopyInto(out *SearchDomainSpec) {
 	*out = *in
+	if in.AutoJoinRequirements != nil {
+		in, out := &in.AutoJoinRequirements, &out.AutoJoinRequirements
+		*out = new(CapabilityRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(RegistrySource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchDomainSpec.
@@ -434,3 +568,19 @@ func (in *SearchDomainStatus) DeepCopy() *SearchDomainStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Unpeering) DeepCopyInto(out *Unpeering) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Unpeering.
+func (in *Unpeering) DeepCopy() *Unpeering {
+	if in == nil {
+		return nil
+	}
+	out := new(Unpeering)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -23,6 +23,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
 )
 
+// the typed clients of the resources are generated by hack/typed-client-gen
+//go:generate go run ../../../hack/typed-client-gen -kind NetworkConfig -var nc -header ../../../hack/boilerplate.go.txt
+//go:generate go run ../../../hack/typed-client-gen -kind TunnelEndpoint -var tep -header ../../../hack/boilerplate.go.txt
+
 var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "net.liqo.io", Version: "v1alpha1"}
@@ -38,12 +38,41 @@ type SchedulingNodeSpec struct {
 
 // SchedulingNodeStatus defines the observed state of SchedulingNode
 type SchedulingNodeStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Allocatable contains the resources of the node available for the pods
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+	// Requested contains the sum of the requests of the non-terminated pods scheduled on the node
+	Requested corev1.ResourceList `json:"requested,omitempty"`
+	// Pods is the number of the non-terminated pods scheduled on the node
+	Pods int32 `json:"pods"`
+	// Ready indicates if the Ready condition of the node is true
+	Ready bool `json:"ready"`
+	// Unschedulable indicates if the node has been cordoned
+	Unschedulable bool `json:"unschedulable,omitempty"`
+	// Peering describes the peering with the foreign cluster backing the virtual node, unset for the physical nodes
+	Peering *PeeringStatus `json:"peering,omitempty"`
+	// LastAdvertisementTime is the timestamp of the last Advertisement received from the foreign cluster
+	LastAdvertisementTime *metav1.Time `json:"lastAdvertisementTime,omitempty"`
+}
+
+// PeeringStatus summarizes the state of the ForeignCluster backing a virtual node
+type PeeringStatus struct {
+	// ClusterID is the identifier of the foreign cluster
+	ClusterID string `json:"clusterID"`
+	// OutgoingJoined indicates if the foreign cluster is sharing its resources with the local one
+	OutgoingJoined bool `json:"outgoingJoined"`
+	// IncomingJoined indicates if the foreign cluster is using the resources of the local one
+	IncomingJoined bool `json:"incomingJoined"`
+	// AdvertisementStatus is the status of the Advertisement received from the foreign cluster
+	AdvertisementStatus string `json:"advertisementStatus,omitempty"`
+	// TunnelAvailable indicates if the TunnelEndpoint connecting the foreign cluster has been created
+	TunnelAvailable bool `json:"tunnelAvailable"`
+	// TunnelPhase is the phase of the TunnelEndpoint, empty if it is not available
+	TunnelPhase string `json:"tunnelPhase,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
 
 // SchedulingNode is the Schema for the schedulingnodes API
 type SchedulingNode struct {
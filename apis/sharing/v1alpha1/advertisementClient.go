@@ -19,12 +19,6 @@ func CreateAdvertisementClient(kubeconfig string, secret *v1.Secret, watchResour
 	var config *rest.Config
 	var err error
 
-	if err = AddToScheme(scheme.Scheme); err != nil {
-		panic(err)
-	}
-
-	crdClient.AddToRegistry("advertisements", &Advertisement{}, &AdvertisementList{}, Keyer, GroupResource)
-
 	if secret == nil {
 		config, err = crdClient.NewKubeconfig(kubeconfig, &GroupVersion)
 		if err != nil {
@@ -37,6 +31,17 @@ func CreateAdvertisementClient(kubeconfig string, secret *v1.Secret, watchResour
 		}
 	}
 
+	return CreateAdvertisementClientFromConfig(config, watchResources)
+}
+
+// create a client for Advertisement CR using the provided rest config
+func CreateAdvertisementClientFromConfig(config *rest.Config, watchResources bool) (*crdClient.CRDClient, error) {
+	if err := AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+
+	crdClient.AddToRegistry("advertisements", &Advertisement{}, &AdvertisementList{}, Keyer, GroupResource)
+
 	clientSet, err := crdClient.NewFromConfig(config)
 	if err != nil {
 		return nil, err
@@ -39,7 +39,12 @@ type AdvertisementSpec struct {
 	// Properties can contain any additional information about the cluster.
 	Properties map[corev1.ResourceName]string `json:"properties,omitempty"`
 	// Prices contains the possible prices for every kind of resource (cpu, memory, image).
-	Prices        corev1.ResourceList    `json:"prices,omitempty"`
+	Prices corev1.ResourceList `json:"prices,omitempty"`
+	// NodeSelector selects the nodes of the cluster whose resources are announced.
+	// The pods offloaded to the cluster must be scheduled only on those nodes.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Topology describes how the announced resources are spread across the nodes of the cluster.
+	Topology      AdvertisementTopology  `json:"topology,omitempty"`
 	KubeConfigRef corev1.SecretReference `json:"kubeConfigRef"`
 	// Timestamp is the time instant when this Advertisement was created.
 	Timestamp metav1.Time `json:"timestamp"`
@@ -48,6 +53,49 @@ type AdvertisementSpec struct {
 	TimeToLive metav1.Time `json:"timeToLive"`
 }
 
+// AdvertisementTopology describes how the resources of a cluster are spread across its nodes
+type AdvertisementTopology struct {
+	// LargestNodes contains the resources still allocatable on the largest nodes, i.e. the nodes whose free
+	// resources are not all exceeded by the ones of another node. A pod can be scheduled in the cluster only if
+	// one of them has enough resources for all its requests.
+	LargestNodes []corev1.ResourceList `json:"largestNodes,omitempty"`
+	// Zones is the breakdown of the announced nodes by region and zone.
+	Zones []ZoneTopology `json:"zones,omitempty"`
+	// NodePoolLabel is the label identifying the node pool of the nodes of the cluster.
+	NodePoolLabel string `json:"nodePoolLabel,omitempty"`
+	// NodePools is the breakdown of the announced nodes by node pool.
+	NodePools []NodePoolTopology `json:"nodePools,omitempty"`
+}
+
+// ZoneTopology describes the resources available in a zone of the cluster
+type ZoneTopology struct {
+	// Region is the region of the nodes, as reported by the topology.kubernetes.io/region label.
+	Region string `json:"region,omitempty"`
+	// Zone is the zone of the nodes, as reported by the topology.kubernetes.io/zone label.
+	Zone string `json:"zone,omitempty"`
+	// ZoneLabel is the label the zone of the nodes has been read from: topology.kubernetes.io/zone, unless some nodes
+	// only have the deprecated failure-domain.beta.kubernetes.io/zone label.
+	ZoneLabel string `json:"zoneLabel,omitempty"`
+	// Nodes is the number of nodes in the zone.
+	Nodes int32 `json:"nodes"`
+	// Resources contains the resources still allocatable in the zone.
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// LargestNodes contains the resources still allocatable on the largest nodes of the zone.
+	LargestNodes []corev1.ResourceList `json:"largestNodes,omitempty"`
+}
+
+// NodePoolTopology describes the resources available in a node pool of the cluster
+type NodePoolTopology struct {
+	// Name is the value of the node pool label of the nodes.
+	Name string `json:"name"`
+	// Nodes is the number of nodes in the node pool.
+	Nodes int32 `json:"nodes"`
+	// Resources contains the resources still allocatable in the node pool.
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// LargestNodes contains the resources still allocatable on the largest nodes of the node pool.
+	LargestNodes []corev1.ResourceList `json:"largestNodes,omitempty"`
+}
+
 // AdvPhase describes the phase of the Advertisement
 type AdvPhase string
 
@@ -69,6 +117,12 @@ type AdvertisementStatus struct {
 	VkReference object_references.DeploymentReference `json:"vkReference,omitempty"`
 	// VnodeReference is a reference to the virtual node linked to this Advertisement
 	VnodeReference object_references.NodeReference `json:"vnodeReference,omitempty"`
+	// VkReferences are the references to the deployments running the virtual-kubelets, when the foreign cluster
+	// is split in more virtual nodes.
+	VkReferences []object_references.DeploymentReference `json:"vkReferences,omitempty"`
+	// VnodeReferences are the references to the virtual nodes linked to this Advertisement, when the foreign cluster
+	// is split in more virtual nodes.
+	VnodeReferences []object_references.NodeReference `json:"vnodeReferences,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -20,8 +20,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"github.com/liqotech/liqo/pkg/object-references"
 	"k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -31,7 +33,7 @@ func (in *Advertisement) DeepCopyInto(out *Advertisement) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Advertisement.
@@ -134,6 +136,12 @@ func (in *AdvertisementSpec) DeepCopyInto(out *AdvertisementSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Topology.DeepCopyInto(&out.Topology)
 	out.KubeConfigRef = in.KubeConfigRef
 	in.Timestamp.DeepCopyInto(&out.Timestamp)
 	in.TimeToLive.DeepCopyInto(&out.TimeToLive)
@@ -154,6 +162,16 @@ func (in *AdvertisementStatus) DeepCopyInto(out *AdvertisementStatus) {
 	*out = *in
 	out.VkReference = in.VkReference
 	out.VnodeReference = in.VnodeReference
+	if in.VkReferences != nil {
+		in, out := &in.VkReferences, &out.VkReferences
+		*out = make([]object_references.DeploymentReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.VnodeReferences != nil {
+		in, out := &in.VnodeReferences, &out.VnodeReferences
+		*out = make([]object_references.NodeReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvertisementStatus.
@@ -165,3 +183,115 @@ func (in *AdvertisementStatus) DeepCopy() *AdvertisementStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvertisementTopology) DeepCopyInto(out *AdvertisementTopology) {
+	*out = *in
+	if in.LargestNodes != nil {
+		in, out := &in.LargestNodes, &out.LargestNodes
+		*out = make([]v1.ResourceList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(v1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ZoneTopology, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]NodePoolTopology, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvertisementTopology.
+func (in *AdvertisementTopology) DeepCopy() *AdvertisementTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvertisementTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTopology) DeepCopyInto(out *NodePoolTopology) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.LargestNodes != nil {
+		in, out := &in.LargestNodes, &out.LargestNodes
+		*out = make([]v1.ResourceList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(v1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTopology.
+func (in *NodePoolTopology) DeepCopy() *NodePoolTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneTopology) DeepCopyInto(out *ZoneTopology) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.LargestNodes != nil {
+		in, out := &in.LargestNodes, &out.LargestNodes
+		*out = make([]v1.ResourceList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(v1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneTopology.
+func (in *ZoneTopology) DeepCopy() *ZoneTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneTopology)
+	in.DeepCopyInto(out)
+	return out
+}
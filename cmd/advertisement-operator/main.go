@@ -16,6 +16,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	"github.com/liqotech/liqo/pkg/crdClient"
@@ -61,6 +62,8 @@ func main() {
 	var enableLeaderElection bool
 	var kubeletNamespace, kubeletImage, initKubeletImage string
 	var runsInKindEnv bool
+	var csrMaxDuration, csrSigningDuration time.Duration
+	var csrMinRsaKeySize, csrMinEcdsaKeySize int
 
 	flag.StringVar(&metricsAddr, "metrics-addr", defaultMetricsaddr, "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
@@ -70,6 +73,10 @@ func main() {
 	flag.StringVar(&kubeletImage, "kubelet-image", defaultVKImage, "The image of the virtual kubelet to be deployed")
 	flag.StringVar(&initKubeletImage, "init-kubelet-image", defaultInitVKImage, "The image of the virtual kubelet init container to be deployed")
 	flag.BoolVar(&runsInKindEnv, "run-in-kind", false, "The cluster in which the controller runs is managed by kind")
+	flag.DurationVar(&csrMaxDuration, "csr-max-duration", 365*24*time.Hour, "The maximum duration of the certificates issued for the CSRs approved by Liqo, 0 to not check it")
+	flag.DurationVar(&csrSigningDuration, "csr-signing-duration", 0, "The duration of the certificates issued by the cluster signer, read from the flags of the kube-controller-manager pods if 0")
+	flag.IntVar(&csrMinRsaKeySize, "csr-min-rsa-key-size", 2048, "The minimum size in bits of the RSA keys of the CSRs approved by Liqo")
+	flag.IntVar(&csrMinEcdsaKeySize, "csr-min-ecdsa-key-size", 256, "The minimum size in bits of the ECDSA keys of the CSRs approved by Liqo")
 	flag.Parse()
 
 	if clusterId == "" {
@@ -101,8 +108,6 @@ func main() {
 		klog.Error(err)
 		os.Exit(1)
 	}
-	go csrApprover.WatchCSR(clientset, "liqo.io/csr=true", 5*time.Second)
-
 	// get the number of already accepted advertisements
 	advClient, err := advtypes.CreateAdvertisementClient(localKubeconfig, nil, true)
 	if err != nil {
@@ -132,6 +137,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	csrPolicy := csrApprover.DefaultPolicy(kubeletNamespace)
+	csrPolicy.MinRsaKeySize = csrMinRsaKeySize
+	csrPolicy.MinEcdsaKeySize = csrMinEcdsaKeySize
+	csrPolicy.MaxDuration = csrMaxDuration
+	go csrApprover.WatchCSR(clientset, mgr.GetEventRecorderFor("csr-approver"), "liqo.io/csr=true", 5*time.Second,
+		csrPolicy, csrApprover.ListForeignClusters(discoveryClient), csrApprover.ControllerManagerSigningDuration(clientset, csrSigningDuration))
+
 	r := &advop.AdvertisementReconciler{
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
@@ -153,10 +165,18 @@ func main() {
 	}
 	// +kubebuilder:scaffold:builder
 
-	r.WatchConfiguration(localKubeconfig, nil)
+	stop := ctrl.SetupSignalHandler()
+	// the informers started outside of the manager are stopped with it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	r.WatchConfiguration(ctx, localKubeconfig, nil)
 
 	klog.Info("starting manager as advertisement-operator")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(stop); err != nil {
 		klog.Error(err)
 		os.Exit(1)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,12 +31,17 @@ func init() {
 }
 
 func main() {
+	var metricsAddr string
+	var workers int
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.IntVar(&workers, "workers", crdReplicator.DefaultWorkers, "The number of workers replicating the resources of each peering cluster")
 	flag.Parse()
 	cfg := ctrl.GetConfigOrDie()
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme:         scheme,
-		Port:           9443,
-		LeaderElection: false,
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		Port:               9443,
+		LeaderElection:     false,
 	})
 	if err != nil {
 		klog.Error(err, "unable to start manager")
@@ -70,18 +77,29 @@ func main() {
 		LocalWatchers:                  make(map[string]map[string]chan struct{}),
 		RemoteWatchers:                 make(map[string]map[string]chan struct{}),
 		RemoteDynSharedInformerFactory: make(map[string]dynamicinformer.DynamicSharedInformerFactory),
+		RemoteConnections:              make(map[string]*crdReplicator.RemoteConnection),
+		Backoff:                        flowcontrol.NewBackOff(crdReplicator.InitialBackoff, crdReplicator.MaxBackoff),
+		Workers:                        workers,
 	}
 	if err = d.SetupWithManager(mgr); err != nil {
 		klog.Error(err, "unable to setup the crdReplicator-operator")
 		os.Exit(1)
 	}
-	err = d.WatchConfiguration(cfg, &configv1alpha1.GroupVersion)
+	stop := ctrl.SetupSignalHandler()
+	// the informers started outside of the manager are stopped with it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	err = d.WatchConfiguration(ctx, cfg, &configv1alpha1.GroupVersion)
 	if err != nil {
 		klog.Error(err)
 		os.Exit(-1)
 	}
 	klog.Info("Starting crdReplicator-operator")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(stop); err != nil {
 		klog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	nettypes "github.com/liqotech/liqo/apis/net/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	"github.com/liqotech/liqo/internal/discovery"
 	foreign_cluster_operator "github.com/liqotech/liqo/internal/discovery/foreign-cluster-operator"
+	"github.com/liqotech/liqo/internal/discovery/registry"
 	search_domain_operator "github.com/liqotech/liqo/internal/discovery/search-domain-operator"
 	"github.com/liqotech/liqo/pkg/clusterID"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog"
 	"os"
@@ -22,6 +25,9 @@ var (
 	scheme = runtime.NewScheme()
 )
 
+// ConfigMap where the discovery registry persists the keys the cluster IDs are pinned to
+const registryKeysConfigMap = "discovery-registry-keys"
+
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = discoveryv1alpha1.AddToScheme(scheme)
@@ -37,11 +43,13 @@ func main() {
 	var requeueAfter int64 // seconds
 	var kubeconfigPath string
 	var resolveContextRefreshTime int // minutes
+	var registryAddress string
 
 	flag.StringVar(&namespace, "namespace", "default", "Namespace where your configs are stored.")
 	flag.Int64Var(&requeueAfter, "requeueAfter", 30, "Period after that PeeringRequests status is rechecked (seconds)")
 	flag.StringVar(&kubeconfigPath, "kubeconfigPath", filepath.Join(os.Getenv("HOME"), ".kube", "config"), "For debug purpose, set path to local kubeconfig")
 	flag.IntVar(&resolveContextRefreshTime, "resolveContextRefreshTime", 10, "Period after that mDNS resolve context is refreshed (minutes)")
+	flag.StringVar(&registryAddress, "registryAddress", "", "Address where to serve a discovery registry, e.g. :8090 (disabled if empty)")
 	flag.Parse()
 
 	klog.Info("Namespace: ", namespace)
@@ -58,7 +66,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	discoveryCtl, err := discovery.NewDiscoveryCtrl(namespace, clusterId, kubeconfigPath, resolveContextRefreshTime)
+	stop := ctrl.SetupSignalHandler()
+	// the informers started outside of the manager are stopped with it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	discoveryCtl, err := discovery.NewDiscoveryCtrl(ctx, namespace, clusterId, kubeconfigPath, resolveContextRefreshTime)
 	if err != nil {
 		klog.Error(err, err.Error())
 		os.Exit(1)
@@ -89,8 +106,23 @@ func main() {
 	klog.Info("Starting ForeignCluster operator")
 	foreign_cluster_operator.StartOperator(&mgr, namespace, time.Duration(requeueAfter)*time.Second, discoveryCtl, kubeconfigPath)
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if registryAddress != "" {
+		klog.Info("Serving the discovery registry on ", registryAddress)
+		go func() {
+			store := registry.NewConfigMapKeyStore(kubernetes.NewForConfigOrDie(mgr.GetConfig()), namespace, registryKeysConfigMap)
+			if err := registry.ListenAndServe(registryAddress, store, stop); err != nil {
+				klog.Error(err, "unable to serve the discovery registry")
+				discoveryCtl.StopDiscovery()
+				os.Exit(1)
+			}
+		}()
+	}
+
+	if err := mgr.Start(stop); err != nil {
 		klog.Error(err, "problem running manager")
+		discoveryCtl.StopDiscovery()
 		os.Exit(1)
 	}
+	// withdraw the records published in DNS
+	discoveryCtl.StopDiscovery()
 }
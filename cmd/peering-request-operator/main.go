@@ -5,6 +5,7 @@ import (
 	"github.com/joho/godotenv"
 	peering_request_operator "github.com/liqotech/liqo/internal/peering-request-operator"
 	peering_request_admission "github.com/liqotech/liqo/internal/peering-request-operator/peering-request-admission"
+	"github.com/liqotech/liqo/pkg/webhook"
 	"k8s.io/klog"
 	"os"
 	"path/filepath"
@@ -16,12 +17,14 @@ func main() {
 	var broadcasterImage, broadcasterServiceAccount, vkServiceAccount string
 	var inputEnvFile string
 	var kubeconfigPath string
+	var webhookAddr string
 
 	flag.StringVar(&inputEnvFile, "input-env-file", "/etc/environment/liqo/env", "The environment variable file to source at startup")
 	flag.StringVar(&broadcasterImage, "broadcaster-image", "liqo/advertisement-broadcaster", "Broadcaster-operator image name")
 	flag.StringVar(&broadcasterServiceAccount, "broadcaster-sa", "broadcaster", "Broadcaster-operator ServiceAccount name")
 	flag.StringVar(&vkServiceAccount, "vk-sa", "vk-remote", "Remote VirtualKubelet ServiceAccount name")
 	flag.StringVar(&kubeconfigPath, "kubeconfigPath", filepath.Join(os.Getenv("HOME"), ".kube", "config"), "For debug purpose, set path to local kubeconfig")
+	flag.StringVar(&webhookAddr, "webhook-listen-address", webhook.DefaultAddr, "The address the admission webhook, the probes and the metrics are served on")
 	flag.Parse()
 
 	if err := godotenv.Load(inputEnvFile); err != nil {
@@ -39,11 +42,11 @@ func main() {
 	}
 	keyPath, ok := os.LookupEnv("liqokey")
 	if !ok {
-		certPath = "/etc/ssl/liqo/server-key.pem"
+		keyPath = "/etc/ssl/liqo/server-key.pem"
 	}
 
 	klog.Info("Starting admission webhook")
-	_ = peering_request_admission.StartWebhook(certPath, keyPath, namespace, kubeconfigPath)
+	_ = peering_request_admission.StartWebhook(certPath, keyPath, webhookAddr, namespace, kubeconfigPath)
 
 	klog.Info("Starting peering-request operator")
 	peering_request_operator.StartOperator(namespace, broadcasterImage, broadcasterServiceAccount, vkServiceAccount, kubeconfigPath)
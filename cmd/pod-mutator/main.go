@@ -4,6 +4,7 @@ import (
 	"flag"
 	"github.com/joho/godotenv"
 	"github.com/liqotech/liqo/pkg/mutate"
+	"github.com/liqotech/liqo/pkg/webhook"
 	"k8s.io/klog"
 	"log"
 )
@@ -18,6 +19,7 @@ func main() {
 	var inputEnvFile string
 
 	flag.StringVar(&inputEnvFile, "input-env-file", inputFile, "The environment variable file to source at startup")
+	flag.StringVar(&config.Addr, "listen-address", webhook.DefaultAddr, "The address the webhook, the probes and the metrics are served on")
 	flag.Parse()
 
 	if err := godotenv.Load(inputEnvFile); err != nil {
@@ -31,6 +33,10 @@ func main() {
 	if err != nil {
 		klog.Fatal(err)
 	}
+	// the offloading policies are read from the ClusterConfig
+	if err = s.WatchConfiguration(""); err != nil {
+		klog.Fatal(err)
+	}
 
 	s.Serve()
 }
@@ -26,6 +26,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	schedulingv1 "github.com/liqotech/liqo/apis/scheduling/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	controllers "github.com/liqotech/liqo/internal/schedulingNodeOperator"
@@ -42,6 +44,8 @@ func init() {
 
 	_ = schedulingv1.AddToScheme(scheme)
 	_ = advtypes.AddToScheme(scheme)
+	_ = discoveryv1alpha1.AddToScheme(scheme)
+	_ = netv1alpha1.AddToScheme(scheme)
 }
 
 func main() {
@@ -82,6 +82,8 @@ func InstallFlags(flags *pflag.FlagSet, c *Opts) {
 	flags.StringVar(&c.ForeignClusterId, "foreign-cluster-id", c.ForeignClusterId, "The Id of the foreign cluster")
 	flags.StringVar(&c.KubeletNamespace, "kubelet-namespace", c.KubeletNamespace, "The namespace of the virtual kubelet")
 	flags.StringVar(&c.HomeClusterId, "home-cluster-id", c.HomeClusterId, "The Id of the home cluster")
+	flags.StringVar(&c.RemoteZone, "remote-zone", c.RemoteZone, "The zone of the foreign cluster mapped on the virtual node")
+	flags.StringVar(&c.RemoteNodePool, "remote-node-pool", c.RemoteNodePool, "The node pool of the foreign cluster mapped on the virtual node")
 	flags.BoolVar(&c.Profiling, "enable-profiling", c.Profiling, "Enable pprof profiling")
 
 	flagset := flag.NewFlagSet("klog", flag.PanicOnError)
@@ -85,6 +85,10 @@ type Opts struct {
 	HomeClusterId    string
 	KubeletNamespace string
 
+	// RemoteZone and RemoteNodePool restrict the virtual node to a zone or a node pool of the foreign cluster
+	RemoteZone     string
+	RemoteNodePool string
+
 	Version   string
 	Profiling bool
 }
@@ -20,7 +20,6 @@ import (
 	"k8s.io/klog"
 	"os"
 	"path"
-	"strings"
 
 	"github.com/liqotech/liqo/cmd/virtual-kubelet/internal/provider"
 	"github.com/liqotech/liqo/internal/utils/errdefs"
@@ -127,6 +126,8 @@ func runRootCommand(ctx context.Context, s *provider.Store, c *Opts) error {
 		ClusterId:         c.ForeignClusterId,
 		HomeClusterId:     c.HomeClusterId,
 		RemoteKubeConfig:  c.ForeignKubeconfig,
+		RemoteZone:        c.RemoteZone,
+		RemoteNodePool:    c.RemoteNodePool,
 	}
 
 	pInit := s.Get(c.Provider)
@@ -144,7 +145,11 @@ func runRootCommand(ctx context.Context, s *provider.Store, c *Opts) error {
 		leaseClient = client.CoordinationV1beta1().Leases(corev1.NamespaceNodeLease)
 	}
 
-	deployName := strings.Join([]string{virtualKubelet.VirtualKubeletPrefix, c.ForeignClusterId}, "")
+	partition := c.RemoteZone
+	if partition == "" {
+		partition = c.RemoteNodePool
+	}
+	deployName := virtualKubelet.VirtualKubeletName(c.ForeignClusterId, partition)
 	refs := createOwnerReference(client, deployName, c.KubeletNamespace)
 
 	var nodeRunner *node.NodeController
@@ -71,6 +71,8 @@ type InitConfig struct {
 	ClusterId         string
 	RemoteKubeConfig  string
 	HomeClusterId     string
+	RemoteZone        string
+	RemoteNodePool    string
 }
 
 type InitFunc func(InitConfig) (Provider, error)
@@ -15,6 +15,8 @@ func registerKubernetes(s *provider.Store) error {
 			cfg.DaemonPort,
 			cfg.ConfigPath,
 			cfg.RemoteKubeConfig,
+			cfg.RemoteZone,
+			cfg.RemoteNodePool,
 		)
 	})
 }
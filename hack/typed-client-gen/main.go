@@ -0,0 +1,83 @@
+// typed-client-gen generates the type-safe client, the event handlers, the lister and the watch functions of a custom
+// resource of the API packages. It is run by go generate in the package of the resource, e.g.
+//
+//	//go:generate go run ../../../hack/typed-client-gen -kind ForeignCluster -var fc
+//
+// and writes the foreignClusterTypedClient.go file in the current directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type params struct {
+	// Package is the name of the package of the resource
+	Package string
+	// Kind is the name of the type of the resource
+	Kind string
+	// Resource is the plural name of the resource in the API
+	Resource string
+	// Var is the name of the variables holding the resources, e.g. fc for ForeignCluster
+	Var string
+	// VarTitle is Var with the first letter in upper case
+	VarTitle string
+}
+
+func main() {
+	var p params
+	var header, output string
+	flag.StringVar(&p.Kind, "kind", "", "name of the type of the resource")
+	flag.StringVar(&p.Resource, "resource", "", "plural name of the resource in the API, the lower case kind followed by s if empty")
+	flag.StringVar(&p.Var, "var", "", "name of the variables holding the resources")
+	flag.StringVar(&p.Package, "package", os.Getenv("GOPACKAGE"), "name of the package of the resource")
+	flag.StringVar(&header, "header", "", "file whose content is prepended to the generated code")
+	flag.StringVar(&output, "output", "", "generated file, <kind>TypedClient.go if empty")
+	flag.Parse()
+
+	if p.Kind == "" || p.Var == "" || p.Package == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if p.Resource == "" {
+		p.Resource = strings.ToLower(p.Kind) + "s"
+	}
+	p.VarTitle = strings.Title(p.Var)
+	if output == "" {
+		first, size := utf8.DecodeRuneInString(p.Kind)
+		output = string(unicode.ToLower(first)) + p.Kind[size:] + "TypedClient.go"
+	}
+
+	if err := generate(p, header, output); err != nil {
+		os.Stderr.WriteString("typed-client-gen: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func generate(p params, header, output string) error {
+	var buf bytes.Buffer
+	if header != "" {
+		h, err := ioutil.ReadFile(header)
+		if err != nil {
+			return err
+		}
+		buf.Write(bytes.TrimSpace(h))
+		buf.WriteString("\n\n")
+	}
+	buf.WriteString("// Code generated by typed-client-gen. DO NOT EDIT.\n\n")
+	if err := typedClientTemplate.Execute(&buf, p); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Clean(output), src, 0644)
+}
@@ -0,0 +1,201 @@
+package main
+
+import "text/template"
+
+// typedClientTemplate is the template of the typed client of a resource, whose parameters are the fields of params
+var typedClientTemplate = template.Must(template.New("typedClient").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"time"
+)
+
+// {{.Kind}}Resource describes the {{.Kind}} resource to the typed clients
+var {{.Kind}}Resource = crdClient.ResourceType{
+	Api:           "{{.Resource}}",
+	GroupResource: schema.GroupResource{Group: GroupVersion.Group, Resource: "{{.Resource}}"},
+	Keyer:         crdClient.NameKeyer,
+	NewObject:     func() runtime.Object { return &{{.Kind}}{} },
+	NewList:       func() runtime.Object { return &{{.Kind}}List{} },
+}
+
+// {{.Kind}}Client is a type-safe client for the {{.Kind}} resources
+// +kubebuilder:object:generate=false
+type {{.Kind}}Client struct {
+	client crdClient.ResourceClient
+}
+
+// New{{.Kind}}Client returns a {{.Kind}}Client using the REST client of clientSet, in Fake mode it works on the
+// Store of clientSet, which has to be created by Watch{{.Kind}}s
+func New{{.Kind}}Client(clientSet *crdClient.CRDClient) *{{.Kind}}Client {
+	return &{{.Kind}}Client{
+		client: clientSet.ResourceClient({{.Kind}}Resource, ""),
+	}
+}
+
+func (c *{{.Kind}}Client) Get(ctx context.Context, name string, opts metav1.GetOptions) (*{{.Kind}}, error) {
+	result := &{{.Kind}}{}
+	err := c.client.Get(ctx, name, opts, result)
+	return result, err
+}
+
+func (c *{{.Kind}}Client) List(ctx context.Context, opts metav1.ListOptions) (*{{.Kind}}List, error) {
+	result := &{{.Kind}}List{}
+	err := c.client.List(ctx, opts, result)
+	return result, err
+}
+
+func (c *{{.Kind}}Client) Create(ctx context.Context, {{.Var}} *{{.Kind}}, opts metav1.CreateOptions) (*{{.Kind}}, error) {
+	result := &{{.Kind}}{}
+	err := c.client.Create(ctx, {{.Var}}, opts, result)
+	return result, err
+}
+
+func (c *{{.Kind}}Client) Update(ctx context.Context, {{.Var}} *{{.Kind}}, opts metav1.UpdateOptions) (*{{.Kind}}, error) {
+	result := &{{.Kind}}{}
+	err := c.client.Update(ctx, {{.Var}}.Name, {{.Var}}, opts, result)
+	return result, err
+}
+
+func (c *{{.Kind}}Client) UpdateStatus(ctx context.Context, {{.Var}} *{{.Kind}}, opts metav1.UpdateOptions) (*{{.Kind}}, error) {
+	result := &{{.Kind}}{}
+	err := c.client.UpdateStatus(ctx, {{.Var}}.Name, {{.Var}}, opts, result)
+	return result, err
+}
+
+// Patch supports the merge patches and the server-side apply, the latter requires opts.FieldManager to be set
+func (c *{{.Kind}}Client) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*{{.Kind}}, error) {
+	result := &{{.Kind}}{}
+	err := c.client.Patch(ctx, name, pt, data, opts, result, subresources...)
+	return result, err
+}
+
+func (c *{{.Kind}}Client) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete(ctx, name, opts)
+}
+
+func (c *{{.Kind}}Client) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.client.Watch(ctx, opts)
+}
+
+// {{.Kind}}HandlerFuncs are the handlers of the events on the {{.Kind}} resources, the nil ones are skipped
+// +kubebuilder:object:generate=false
+type {{.Kind}}HandlerFuncs struct {
+	AddFunc    func({{.Var}} *{{.Kind}})
+	UpdateFunc func(old{{.VarTitle}}, new{{.VarTitle}} *{{.Kind}})
+	DeleteFunc func({{.Var}} *{{.Kind}})
+}
+
+// ResourceEventHandlerFuncs converts the handlers to the ones of the cache, the objects which are not {{.Kind}}s
+// are discarded and the deleted objects are extracted from the tombstones
+func (h {{.Kind}}HandlerFuncs) ResourceEventHandlerFuncs() cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if {{.Var}}, ok := to{{.Kind}}(obj); ok && h.AddFunc != nil {
+				h.AddFunc({{.Var}})
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			old{{.VarTitle}}, ok := to{{.Kind}}(oldObj)
+			if !ok {
+				return
+			}
+			if new{{.VarTitle}}, ok := to{{.Kind}}(newObj); ok && h.UpdateFunc != nil {
+				h.UpdateFunc(old{{.VarTitle}}, new{{.VarTitle}})
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if {{.Var}}, ok := to{{.Kind}}(crdClient.FromTombstone(obj)); ok && h.DeleteFunc != nil {
+				h.DeleteFunc({{.Var}})
+			}
+		},
+	}
+}
+
+func to{{.Kind}}(obj interface{}) (*{{.Kind}}, bool) {
+	{{.Var}}, ok := obj.(*{{.Kind}})
+	if !ok {
+		klog.Errorf("expected a {{.Kind}} but got %T", obj)
+	}
+	return {{.Var}}, ok
+}
+
+// {{.Kind}}Lister returns the {{.Kind}} resources from the cache, they must not be modified
+// +kubebuilder:object:generate=false
+type {{.Kind}}Lister struct {
+	store cache.Store
+}
+
+// New{{.Kind}}Lister returns a {{.Kind}}Lister for a cache of {{.Kind}} resources
+func New{{.Kind}}Lister(store cache.Store) *{{.Kind}}Lister {
+	return &{{.Kind}}Lister{
+		store: store,
+	}
+}
+
+// Get returns the {{.Kind}} with the given name, the returned bool is false if it is not in the cache
+func (l *{{.Kind}}Lister) Get(name string) (*{{.Kind}}, bool, error) {
+	obj, found, err := l.store.GetByKey(name)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, nil
+	}
+	{{.Var}}, ok := obj.(*{{.Kind}})
+	if !ok {
+		return nil, false, errors.New("cached object is not a {{.Kind}}")
+	}
+	return {{.Var}}, true, nil
+}
+
+func (l *{{.Kind}}Lister) List() []*{{.Kind}} {
+	objs := l.store.List()
+	result := make([]*{{.Kind}}, 0, len(objs))
+	for _, obj := range objs {
+		if {{.Var}}, ok := obj.(*{{.Kind}}); ok {
+			result = append(result, {{.Var}})
+		}
+	}
+	return result
+}
+
+// Watch{{.Kind}}s starts a cache of the {{.Kind}} resources calling the handlers on their events, it can be stopped
+// closing the returned channel. In Fake mode the cache becomes the Store of clientSet, if it has not been set yet
+func Watch{{.Kind}}s(clientSet *crdClient.CRDClient,
+	resyncPeriod time.Duration,
+	handlers {{.Kind}}HandlerFuncs,
+	lo metav1.ListOptions) (*{{.Kind}}Lister, chan struct{}, error) {
+
+	store, stop, err := crdClient.WatchResourceType(clientSet, {{.Kind}}Resource, "", resyncPeriod, handlers.ResourceEventHandlerFuncs(), lo)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientSet.SetFakeStore(store)
+	return New{{.Kind}}Lister(store), stop, nil
+}
+
+// Watch{{.Kind}}sContext is the context-aware variant of Watch{{.Kind}}s: the cache is stopped when the context is done
+func Watch{{.Kind}}sContext(ctx context.Context,
+	clientSet *crdClient.CRDClient,
+	resyncPeriod time.Duration,
+	handlers {{.Kind}}HandlerFuncs,
+	lo metav1.ListOptions) (*{{.Kind}}Lister, error) {
+
+	store, err := crdClient.WatchResourceTypeContext(ctx, clientSet, {{.Kind}}Resource, "", resyncPeriod, handlers.ResourceEventHandlerFuncs(), lo)
+	if err != nil {
+		return nil, err
+	}
+	clientSet.SetFakeStore(store)
+	return New{{.Kind}}Lister(store), nil
+}
+`))
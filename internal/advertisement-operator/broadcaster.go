@@ -5,12 +5,17 @@ import (
 	"errors"
 	"github.com/liqotech/liqo/internal/discovery/kubeconfig"
 	advpkg "github.com/liqotech/liqo/pkg/advertisement-operator"
+	"github.com/liqotech/liqo/pkg/clusterConfig"
 	"github.com/liqotech/liqo/pkg/crdClient"
 	"github.com/liqotech/liqo/pkg/labelPolicy"
 	pkg "github.com/liqotech/liqo/pkg/virtualKubelet"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -31,12 +36,19 @@ type AdvertisementBroadcaster struct {
 	// remote-related variables
 	KubeconfigSecretForForeign *corev1.Secret       // secret containing the kubeconfig that will be sent to the foreign cluster
 	RemoteClient               *crdClient.CRDClient // client to create Advertisements and Secrets on the foreign cluster
+	// credentials-related variables
+	saName                string
+	apiServerConfig       *configv1alpha1.ApiServerConfig
+	credentialsSecret     *corev1.Secret // secret the tokens sent to the foreign cluster are bound to
+	credentialsExpiration *metav1.Time
+	credentialsMutex      sync.Mutex
 	// configuration variables
 	HomeClusterId      string
 	ForeignClusterId   string
 	PeeringRequestName string
 	ClusterConfig      configv1alpha1.ClusterConfigSpec
 	mutex              sync.Mutex
+	announceMutex      sync.Mutex // serializes the reservation and the announcement of the resources
 }
 
 // convenience struct, to be returned in func
@@ -44,9 +56,12 @@ type AdvResources struct {
 	PhysicalNodes *corev1.NodeList
 	VirtualNodes  *corev1.NodeList
 	Availability  corev1.ResourceList
+	Budget        corev1.ResourceList // the resources that can be announced to all the foreign clusters
 	Limits        corev1.ResourceList
 	Images        []corev1.ContainerImage
 	Labels        map[string]string
+	Prices        corev1.ResourceList
+	Topology      advtypes.AdvertisementTopology
 }
 
 // start the broadcaster which sends Advertisement messages
@@ -104,7 +119,7 @@ func StartBroadcaster(homeClusterId, localKubeconfigPath, peeringRequestName, sa
 
 	// create a CRD-client to the foreign cluster
 	for retry = 0; retry < 3; retry++ {
-		remoteClient, err = advtypes.CreateAdvertisementClient("", secretForAdvertisementCreation, true)
+		remoteClient, err = createRemoteClient(localClient, secretForAdvertisementCreation)
 		if err != nil {
 			klog.Errorln(err, "Unable to create client to remote cluster "+foreignClusterId+". Retry in 1 minute")
 			time.Sleep(1 * time.Minute)
@@ -126,30 +141,42 @@ func StartBroadcaster(homeClusterId, localKubeconfigPath, peeringRequestName, sa
 		HomeClusterId:      homeClusterId,
 		ForeignClusterId:   pr.Name,
 		PeeringRequestName: peeringRequestName,
+		saName:             saName,
 	}
 
 	kubeconfigSecretName := pkg.VirtualKubeletSecPrefix + homeClusterId
 
 	// create the kubeconfig to allow the foreign cluster to create resources on local cluster
-	kubeconfigForForeignCluster, err := kubeconfig.CreateKubeConfig(localClient.Client(), saName, pr.Spec.Namespace)
+	configuration, err := clusterConfig.GetConfiguration(nil, localKubeconfigPath)
 	if err != nil {
-		klog.Errorln(err, "Unable to create Kubeconfig")
+		klog.Errorln(err, "Unable to get the ClusterConfig")
 		return err
 	}
+	broadcaster.apiServerConfig = &configuration.Spec.ApiServerConfig
+	if broadcaster.apiServerConfig.CredentialsTtl > 0 {
+		// the tokens are bound to a Secret owned by the PeeringRequest: they are revoked when the peering is deleted
+		broadcaster.credentialsSecret, err = kubeconfig.GetCredentialsSecret(localClient.Client(), pr.Spec.Namespace,
+			pkg.CredentialsSecPrefix+foreignClusterId, metav1.OwnerReference{
+				APIVersion: "discovery.liqo.io/v1alpha1",
+				Kind:       "PeeringRequest",
+				Name:       pr.Name,
+				UID:        pr.UID,
+			})
+		if err != nil {
+			klog.Errorln(err, "Unable to get the Secret the credentials are bound to")
+			return err
+		}
+	}
 	// put the kubeconfig in a Secret, which is created on the foreign cluster
-	kubeconfigSecretForForeign := &corev1.Secret{
+	broadcaster.KubeconfigSecretForForeign = &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kubeconfigSecretName,
 			Namespace: pr.Spec.Namespace,
 		},
 		Data: nil,
-		StringData: map[string]string{
-			"kubeconfig": kubeconfigForForeignCluster,
-		},
 	}
-	broadcaster.KubeconfigSecretForForeign = kubeconfigSecretForForeign
-	_, err = broadcaster.SendSecretToForeignCluster(kubeconfigSecretForForeign)
+	err = broadcaster.refreshCredentials()
 	if err != nil {
 		// secret not created, without it the vk cannot be launched: just log and exit
 		klog.Errorf("Unable to create secret for virtualKubelet on remote cluster %v; error: %v", foreignClusterId, err)
@@ -158,6 +185,7 @@ func StartBroadcaster(homeClusterId, localKubeconfigPath, peeringRequestName, sa
 	// secret correctly created on foreign cluster, now launch the broadcaster to create Advertisement
 
 	broadcaster.WatchConfiguration(localKubeconfigPath, nil)
+	go broadcaster.RotateCredentials()
 
 	broadcaster.GenerateAdvertisement()
 	// if we come here there has been an error while the broadcaster was running
@@ -171,25 +199,16 @@ func (b *AdvertisementBroadcaster) GenerateAdvertisement() {
 	var once sync.Once
 
 	for {
-		_, err := b.SendSecretToForeignCluster(b.KubeconfigSecretForForeign)
+		err := b.sendKubeconfigSecret()
 		if err != nil {
 			klog.Errorln(err, "Error while sending Secret for virtual-kubelet to cluster "+b.ForeignClusterId)
 			time.Sleep(1 * time.Minute)
 			continue
 		}
 
-		advRes, err := b.GetResourcesForAdv()
-		if err != nil {
-			klog.Errorln(err, "Error while computing resources for Advertisement")
-			time.Sleep(1 * time.Minute)
-			continue
-		}
-
 		// create the Advertisement on the foreign cluster
-		advToCreate := b.CreateAdvertisement(advRes)
-		adv, err := b.SendAdvertisementToForeignCluster(advToCreate)
+		adv, err := b.announce()
 		if err != nil {
-			klog.Errorln(err, "Error while sending Advertisement to cluster "+b.ForeignClusterId)
 			time.Sleep(1 * time.Minute)
 			continue
 		}
@@ -203,11 +222,37 @@ func (b *AdvertisementBroadcaster) GenerateAdvertisement() {
 	}
 }
 
+// compute the resources to be announced, reserve them and send the Advertisement to the foreign cluster
+func (b *AdvertisementBroadcaster) announce() (*advtypes.Advertisement, error) {
+	b.announceMutex.Lock()
+	defer b.announceMutex.Unlock()
+
+	advRes, err := b.GetResourcesForAdv()
+	if err != nil {
+		klog.Errorln(err, "Error while computing resources for Advertisement")
+		return nil, err
+	}
+	advRes.Availability, err = b.ReserveResources(advRes.Availability, advRes.Budget)
+	if err != nil {
+		klog.Errorln(err, "Error while reserving resources in PeeringRequest "+b.PeeringRequestName)
+		return nil, err
+	}
+	advToCreate := b.CreateAdvertisement(advRes)
+	adv, err := b.SendAdvertisementToForeignCluster(advToCreate)
+	if err != nil {
+		klog.Errorln(err, "Error while sending Advertisement to cluster "+b.ForeignClusterId)
+		return nil, err
+	}
+	// release the resources reserved for the previous Advertisement and not announced anymore
+	if err = b.SaveAnnouncedResources(adv); err != nil {
+		klog.Errorln(err, "Error while saving announced resources in PeeringRequest "+b.PeeringRequestName)
+	}
+	return adv, nil
+}
+
 // create advertisement message
 func (b *AdvertisementBroadcaster) CreateAdvertisement(advRes *AdvResources) advtypes.Advertisement {
 
-	// set prices field
-	prices := ComputePrices(advRes.Images)
 	// use virtual nodes to build neighbours
 	neighbours := make(map[corev1.ResourceName]corev1.ResourceList)
 	for _, vnode := range advRes.VirtualNodes.Items {
@@ -238,10 +283,12 @@ func (b *AdvertisementBroadcaster) CreateAdvertisement(advRes *AdvResources) adv
 				Scopes:        nil,
 				ScopeSelector: nil,
 			},
-			Labels:     advRes.Labels,
-			Neighbors:  neighbours,
-			Properties: nil,
-			Prices:     prices,
+			Labels:       advRes.Labels,
+			Neighbors:    neighbours,
+			Properties:   nil,
+			Prices:       advRes.Prices,
+			NodeSelector: b.ClusterConfig.AdvertisementConfig.NodeSelector.DeepCopy(),
+			Topology:     advRes.Topology,
 			KubeConfigRef: corev1.SecretReference{
 				Namespace: b.KubeconfigSecretForForeign.Namespace,
 				Name:      b.KubeconfigSecretForForeign.Name,
@@ -255,7 +302,12 @@ func (b *AdvertisementBroadcaster) CreateAdvertisement(advRes *AdvResources) adv
 
 func (b *AdvertisementBroadcaster) GetResourcesForAdv() (advRes *AdvResources, err error) {
 	// get physical and virtual nodes in the cluster
-	physicalNodes, err := b.LocalClient.Client().CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: "type != virtual-node"})
+	physicalNodesSelector, err := GetPhysicalNodesSelector(b.ClusterConfig.AdvertisementConfig.NodeSelector)
+	if err != nil {
+		klog.Errorln("Invalid node selector")
+		return nil, err
+	}
+	physicalNodes, err := b.LocalClient.Client().CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: physicalNodesSelector.String()})
 	if err != nil {
 		klog.Errorln("Could not get physical nodes, retry in 1 minute")
 		return nil, err
@@ -275,19 +327,41 @@ func (b *AdvertisementBroadcaster) GetResourcesForAdv() (advRes *AdvResources, e
 		klog.Errorln("Could not list pods, retry in 1 minute")
 		return nil, err
 	}
+	if b.ClusterConfig.AdvertisementConfig.NodeSelector != nil {
+		// only the pods running on the selected nodes consume announced resources
+		FilterPodsByNodes(nodeNonTerminatedPodsList, physicalNodes)
+	}
 	reqs, limits := GetAllPodsResources(nodeNonTerminatedPodsList)
+	// get resources already announced to the other foreign clusters
+	announcedToOthers, err := b.GetResourcesAnnouncedToOthers()
+	if err != nil {
+		klog.Errorln("Could not list peering requests, retry in 1 minute")
+		return nil, err
+	}
 	// compute resources to be announced to the other cluster
-	availability, images := ComputeAnnouncedResources(physicalNodes, reqs, int64(b.ClusterConfig.AdvertisementConfig.OutgoingConfig.ResourceSharingPercentage))
+	availability, images := ComputeSharedResources(physicalNodes, reqs, announcedToOthers, b.ClusterConfig.AdvertisementConfig.OutgoingConfig, b.ForeignClusterId)
+	budget := ComputeSharingBudget(physicalNodes, reqs, b.ClusterConfig.AdvertisementConfig.OutgoingConfig)
 
 	labels := GetLabels(physicalNodes, b.ClusterConfig.AdvertisementConfig.LabelPolicies)
 
+	// describe how the free resources are spread across the nodes
+	topology := ComputeTopology(physicalNodes, nodeNonTerminatedPodsList, b.ClusterConfig.AdvertisementConfig.NodePoolLabel)
+
+	// compute prices on the basis of the current utilisation of the cluster
+	allocatable, _ := GetClusterResources(physicalNodes.Items)
+	utilisation := ComputeUtilisation(allocatable, reqs)
+	prices := ComputePrices(images, utilisation, b.ClusterConfig.AdvertisementConfig.OutgoingConfig.PricingConfig, b.ForeignClusterId)
+
 	return &AdvResources{
 		PhysicalNodes: physicalNodes,
 		VirtualNodes:  virtualNodes,
 		Availability:  availability,
+		Budget:        budget,
 		Limits:        limits,
 		Images:        images,
 		Labels:        labels,
+		Prices:        prices,
+		Topology:      topology,
 	}, nil
 }
 
@@ -337,6 +411,83 @@ func (b *AdvertisementBroadcaster) SendAdvertisementToForeignCluster(advToCreate
 	return adv, nil
 }
 
+// get the sum of the resources announced to all the foreign clusters but the one managed by this broadcaster
+func (b *AdvertisementBroadcaster) GetResourcesAnnouncedToOthers() (corev1.ResourceList, error) {
+	tmp, err := b.DiscoveryClient.Resource("peeringrequests").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	prList, ok := tmp.(*discoveryv1alpha1.PeeringRequestList)
+	if !ok {
+		return nil, errors.New("retrieved object is not a PeeringRequestList")
+	}
+	announced := corev1.ResourceList{}
+	for i := range prList.Items {
+		pr := &prList.Items[i]
+		if pr.Name == b.PeeringRequestName {
+			continue
+		}
+		addResourceLists(&announced, &pr.Status.AnnouncedResources)
+	}
+	return announced, nil
+}
+
+// save the resources announced to the foreign cluster in the PeeringRequest, so that the other broadcasters can take them into account
+func (b *AdvertisementBroadcaster) SaveAnnouncedResources(adv *advtypes.Advertisement) error {
+	return b.updateAnnouncedResources(func(corev1.ResourceList) corev1.ResourceList {
+		return adv.Spec.ResourceQuota.Hard.DeepCopy()
+	})
+}
+
+// reserve the resources to be announced to the foreign cluster in its PeeringRequest, limited by the part of the budget
+// not reserved by the broadcasters of the other foreign clusters.
+// The broadcasters run in different processes: each one records its reservation before reading the other ones, hence
+// of two concurrent reservations at least the later one is limited by the earlier one and the budget is not exceeded.
+// The resources announced in the previous Advertisement stay reserved until the new one has been sent.
+func (b *AdvertisementBroadcaster) ReserveResources(wanted, budget corev1.ResourceList) (corev1.ResourceList, error) {
+	var previous corev1.ResourceList
+	err := b.updateAnnouncedResources(func(announced corev1.ResourceList) corev1.ResourceList {
+		previous = announced
+		return maxResourceLists(announced, wanted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	announcedToOthers, err := b.GetResourcesAnnouncedToOthers()
+	if err != nil {
+		return nil, err
+	}
+	reserved := limitToBudget(wanted, budget, announcedToOthers)
+	if reflect.DeepEqual(reserved, wanted) {
+		return reserved, nil
+	}
+	// release the part of the reservation exceeding the budget, keeping it is safe if the update fails
+	err = b.updateAnnouncedResources(func(corev1.ResourceList) corev1.ResourceList {
+		return maxResourceLists(previous, reserved)
+	})
+	if err != nil {
+		klog.Errorln(err, "Error while releasing resources in PeeringRequest "+b.PeeringRequestName)
+	}
+	return reserved, nil
+}
+
+// update the resources announced to the foreign cluster in its PeeringRequest, retrying on conflicts
+func (b *AdvertisementBroadcaster) updateAnnouncedResources(update func(announced corev1.ResourceList) corev1.ResourceList) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tmp, err := b.DiscoveryClient.Resource("peeringrequests").Get(b.PeeringRequestName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		pr, ok := tmp.(*discoveryv1alpha1.PeeringRequest)
+		if !ok {
+			return errors.New("retrieved object is not a PeeringRequest")
+		}
+		pr.Status.AnnouncedResources = update(pr.Status.AnnouncedResources.DeepCopy())
+		_, err = b.DiscoveryClient.Resource("peeringrequests").Update(pr.Name, pr, metav1.UpdateOptions{})
+		return err
+	})
+}
+
 func (b *AdvertisementBroadcaster) SendSecretToForeignCluster(secret *corev1.Secret) (*corev1.Secret, error) {
 	secretForeign, err := b.RemoteClient.Client().CoreV1().Secrets(secret.Namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
 	if err == nil {
@@ -378,6 +529,94 @@ func (b *AdvertisementBroadcaster) NotifyAdvertisementDeletion() error {
 	return nil
 }
 
+// create the client to the foreign cluster from the Secret in the PeeringRequest
+func createRemoteClient(localClient *crdClient.CRDClient, secret *corev1.Secret) (*crdClient.CRDClient, error) {
+	config, err := crdClient.NewKubeconfigFromSecret(secret, &advtypes.GroupVersion)
+	if err != nil {
+		return nil, err
+	}
+	// the credentials in the Secret are rotated by the foreign cluster
+	crdClient.ReloadTokenFromSecret(config, localClient.Client(), secret.Namespace, secret.Name)
+	return advtypes.CreateAdvertisementClientFromConfig(config, true)
+}
+
+// send the Secret with the kubeconfig for the virtual-kubelet to the foreign cluster
+func (b *AdvertisementBroadcaster) sendKubeconfigSecret() error {
+	b.credentialsMutex.Lock()
+	defer b.credentialsMutex.Unlock()
+	_, err := b.SendSecretToForeignCluster(b.KubeconfigSecretForForeign)
+	return err
+}
+
+// create new credentials for the foreign cluster and send them in the Secret with the kubeconfig for the virtual-kubelet
+func (b *AdvertisementBroadcaster) refreshCredentials() error {
+	kc, expiration, err := kubeconfig.CreateKubeConfig(b.LocalClient.Client(), b.apiServerConfig, b.saName,
+		b.KubeconfigSecretForForeign.Namespace, b.credentialsSecret)
+	if err != nil {
+		klog.Errorln(err, "Unable to create Kubeconfig")
+		return err
+	}
+	b.credentialsMutex.Lock()
+	defer b.credentialsMutex.Unlock()
+	b.KubeconfigSecretForForeign.StringData = map[string]string{
+		"kubeconfig": kc,
+	}
+	b.credentialsExpiration = expiration
+	_, err = b.SendSecretToForeignCluster(b.KubeconfigSecretForForeign)
+	return err
+}
+
+// get the expiration of the credentials sent to the foreign cluster, nil if they do not expire
+func (b *AdvertisementBroadcaster) getCredentialsExpiration() *metav1.Time {
+	b.credentialsMutex.Lock()
+	defer b.credentialsMutex.Unlock()
+	return b.credentialsExpiration
+}
+
+// replace the credentials sent to the foreign cluster before they expire, if they do
+func (b *AdvertisementBroadcaster) RotateCredentials() {
+	for expiration := b.getCredentialsExpiration(); expiration != nil; expiration = b.getCredentialsExpiration() {
+		time.Sleep(time.Until(kubeconfig.RotationTime(expiration, b.apiServerConfig.CredentialsTtl)))
+		if err := b.refreshCredentials(); err != nil {
+			klog.Errorf("Unable to rotate the credentials of cluster %v, retry in 1 minute; error: %v", b.ForeignClusterId, err)
+			time.Sleep(1 * time.Minute)
+			continue
+		}
+		klog.Infof("Credentials of cluster %v rotated, they expire at %v", b.ForeignClusterId, b.getCredentialsExpiration())
+	}
+}
+
+// get the selector matching the physical nodes whose resources are announced
+func GetPhysicalNodesSelector(nodeSelector *metav1.LabelSelector) (labels.Selector, error) {
+	selector := labels.Everything()
+	if nodeSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(nodeSelector); err != nil {
+			return nil, err
+		}
+	}
+	notVirtual, err := labels.NewRequirement("type", selection.NotEquals, []string{"virtual-node"})
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*notVirtual), nil
+}
+
+// remove from the list the pods not running on the given nodes
+func FilterPodsByNodes(podList *corev1.PodList, nodes *corev1.NodeList) {
+	nodeNames := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames[node.Name] = true
+	}
+	pods := podList.Items[:0]
+	for _, pod := range podList.Items {
+		if nodeNames[pod.Spec.NodeName] {
+			pods = append(pods, pod)
+		}
+	}
+	podList.Items = pods
+}
+
 // get resources used by pods on physical nodes
 func GetAllPodsResources(nodeNonTerminatedPodsList *corev1.PodList) (requests corev1.ResourceList, limits corev1.ResourceList) {
 	// remove pods on virtual nodes
@@ -443,6 +682,20 @@ func addResourceLists(dst *corev1.ResourceList, toAdd *corev1.ResourceList) {
 	}
 }
 
+// get for each resource the maximum of the two lists
+func maxResourceLists(a, b corev1.ResourceList) corev1.ResourceList {
+	res := a.DeepCopy()
+	if res == nil {
+		res = corev1.ResourceList{}
+	}
+	for k, v := range b {
+		if qnt, ok := res[k]; !ok || qnt.Cmp(v) < 0 {
+			res[k] = v.DeepCopy()
+		}
+	}
+	return res
+}
+
 func GetNodeImages(node corev1.Node) []corev1.ContainerImage {
 	m := make(map[string]corev1.ContainerImage)
 
@@ -470,13 +723,82 @@ func GetLabels(physicalNodes *corev1.NodeList, labelPolicies []configv1alpha1.La
 		return labels
 	}
 	for _, lblPol := range labelPolicies {
-		if val, insert := labelPolicy.GetInstance(lblPol.Policy).Process(physicalNodes, lblPol.Key); insert {
+		policy, err := labelPolicy.NewPolicy(lblPol.Policy, labelPolicy.Options{
+			Separator:   lblPol.Separator,
+			Regex:       lblPol.Regex,
+			Replacement: lblPol.Replacement,
+		})
+		if err != nil {
+			klog.Errorf("invalid policy %s for label %s: %s", lblPol.Policy, lblPol.Key, err)
+			continue
+		}
+		if val, insert := policy.Process(physicalNodes, lblPol.Key); insert {
 			labels[lblPol.Key] = val
 		}
 	}
 	return labels
 }
 
+// compute the resources to be shared with a foreign cluster
+// the offer is given by the sharing quota of the foreign cluster, limited by the resources not yet announced to the other foreign clusters
+func ComputeSharedResources(physicalNodes *corev1.NodeList, reqs corev1.ResourceList, announcedToOthers corev1.ResourceList,
+	config configv1alpha1.BroadcasterConfig, foreignClusterId string) (availability corev1.ResourceList, images []corev1.ContainerImage) {
+
+	sharingPercentage := int64(config.ResourceSharingPercentage)
+	var quotaResources corev1.ResourceList
+	for _, quota := range config.ClusterSharingQuotas {
+		if quota.ClusterID == foreignClusterId {
+			if quota.Percentage != nil {
+				sharingPercentage = int64(*quota.Percentage)
+			}
+			quotaResources = quota.Resources
+			break
+		}
+	}
+	availability, images = ComputeAnnouncedResources(physicalNodes, reqs, sharingPercentage)
+	// apply the absolute limits of the foreign cluster
+	for k, v := range quotaResources {
+		if qnt, ok := availability[k]; ok && qnt.Cmp(v) > 0 {
+			availability[k] = v.DeepCopy()
+		}
+	}
+
+	budget := ComputeSharingBudget(physicalNodes, reqs, config)
+	return limitToBudget(availability, budget, announcedToOthers), images
+}
+
+// compute the total amount of resources that can be announced to all the foreign clusters
+func ComputeSharingBudget(physicalNodes *corev1.NodeList, reqs corev1.ResourceList, config configv1alpha1.BroadcasterConfig) corev1.ResourceList {
+	maxTotalPercentage := int64(100)
+	if config.MaxTotalSharingPercentage != nil {
+		maxTotalPercentage = int64(*config.MaxTotalSharingPercentage)
+	}
+	budget, _ := ComputeAnnouncedResources(physicalNodes, reqs, maxTotalPercentage)
+	return budget
+}
+
+// limit the resources to the part of the budget not announced to the other foreign clusters
+func limitToBudget(availability, budget, announcedToOthers corev1.ResourceList) corev1.ResourceList {
+	res := availability.DeepCopy()
+	for k, v := range res {
+		remaining, ok := budget[k]
+		if !ok {
+			continue
+		}
+		remaining = remaining.DeepCopy()
+		if announced, ok := announcedToOthers[k]; ok {
+			remaining.Sub(announced)
+		}
+		if remaining.Sign() < 0 {
+			remaining.Set(0)
+		}
+		if v.Cmp(remaining) > 0 {
+			res[k] = remaining
+		}
+	}
+	return res
+}
+
 // create announced resources for advertisement
 func ComputeAnnouncedResources(physicalNodes *corev1.NodeList, reqs corev1.ResourceList, sharingPercentage int64) (availability corev1.ResourceList, images []corev1.ContainerImage) {
 	// get allocatable resources in all the physical nodes
@@ -504,17 +826,3 @@ func ComputeAnnouncedResources(physicalNodes *corev1.NodeList, reqs corev1.Resou
 	}
 	return availability, images
 }
-
-// create prices resource for advertisement
-func ComputePrices(images []corev1.ContainerImage) corev1.ResourceList {
-	//TODO: logic to set prices
-	prices := corev1.ResourceList{}
-	prices[corev1.ResourceCPU] = *resource.NewQuantity(1, resource.DecimalSI)
-	prices[corev1.ResourceMemory] = resource.MustParse("2m")
-	for _, image := range images {
-		for _, name := range image.Names {
-			prices[corev1.ResourceName(name)] = *resource.NewQuantity(5, resource.DecimalSI)
-		}
-	}
-	return prices
-}
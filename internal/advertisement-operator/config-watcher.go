@@ -1,6 +1,7 @@
 package advertisementOperator
 
 import (
+	"context"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	"github.com/liqotech/liqo/pkg/clusterConfig"
@@ -9,6 +10,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
+	"reflect"
 	"time"
 )
 
@@ -49,6 +51,35 @@ func (b *AdvertisementBroadcaster) WatchConfiguration(kubeconfigPath string, cli
 			b.updateAdvertisement()
 		}
 
+		if !reflect.DeepEqual(newConfig.ClusterSharingQuotas, b.ClusterConfig.AdvertisementConfig.OutgoingConfig.ClusterSharingQuotas) ||
+			!reflect.DeepEqual(newConfig.MaxTotalSharingPercentage, b.ClusterConfig.AdvertisementConfig.OutgoingConfig.MaxTotalSharingPercentage) {
+			// the sharing quotas have been modified: update the advertisement with the new resources
+			klog.Info("AdvertisementConfig changed: the sharing quotas have changed")
+			b.ClusterConfig.AdvertisementConfig.OutgoingConfig = newConfig
+			b.updateAdvertisement()
+		}
+
+		if !reflect.DeepEqual(newConfig.PricingConfig, b.ClusterConfig.AdvertisementConfig.OutgoingConfig.PricingConfig) {
+			// the pricing model has been modified: update the advertisement with the new prices
+			klog.Info("AdvertisementConfig changed: the PricingConfig has changed")
+			b.ClusterConfig.AdvertisementConfig.OutgoingConfig = newConfig
+			b.updateAdvertisement()
+		}
+
+		if !reflect.DeepEqual(configuration.Spec.AdvertisementConfig.NodeSelector, b.ClusterConfig.AdvertisementConfig.NodeSelector) {
+			// the announced nodes have changed: update the advertisement
+			klog.Info("AdvertisementConfig changed: the NodeSelector has changed")
+			b.ClusterConfig.AdvertisementConfig.NodeSelector = configuration.Spec.AdvertisementConfig.NodeSelector.DeepCopy()
+			b.updateAdvertisement()
+		}
+
+		if configuration.Spec.AdvertisementConfig.NodePoolLabel != b.ClusterConfig.AdvertisementConfig.NodePoolLabel {
+			// the node pools have changed: update the advertisement topology
+			klog.Info("AdvertisementConfig changed: the NodePoolLabel has changed")
+			b.ClusterConfig.AdvertisementConfig.NodePoolLabel = configuration.Spec.AdvertisementConfig.NodePoolLabel
+			b.updateAdvertisement()
+		}
+
 		if differentLabels(b.ClusterConfig.AdvertisementConfig.LabelPolicies, configuration.Spec.AdvertisementConfig.LabelPolicies) {
 			// update label policies
 			b.ClusterConfig.AdvertisementConfig.LabelPolicies = configuration.Spec.AdvertisementConfig.LabelPolicies
@@ -59,21 +90,15 @@ func (b *AdvertisementBroadcaster) WatchConfiguration(kubeconfigPath string, cli
 }
 
 func (b *AdvertisementBroadcaster) updateAdvertisement() {
-	advRes, err := b.GetResourcesForAdv()
-	if err != nil {
-		klog.Errorln(err, "Error while computing resources for Advertisement")
-	}
-	advToCreate := b.CreateAdvertisement(advRes)
-	_, err = b.SendAdvertisementToForeignCluster(advToCreate)
-	if err != nil {
-		klog.Errorln(err, "Error while sending Advertisement to cluster "+b.ForeignClusterId)
-	}
+	// the errors are logged, the Advertisement is sent again at the next period
+	_, _ = b.announce()
 }
 
-func (r *AdvertisementReconciler) WatchConfiguration(kubeconfigPath string, client *crdClient.CRDClient) {
-	go clusterConfig.WatchConfiguration(func(configuration *configv1alpha1.ClusterConfig) {
+// WatchConfiguration applies the changes of the ClusterConfig to the Advertisements until the context is done
+func (r *AdvertisementReconciler) WatchConfiguration(ctx context.Context, kubeconfigPath string, client *crdClient.CRDClient) {
+	go clusterConfig.WatchConfigurationContext(ctx, func(configuration *configv1alpha1.ClusterConfig) {
 		newConfig := configuration.Spec.AdvertisementConfig
-		if newConfig.IngoingConfig != r.ClusterConfig.IngoingConfig {
+		if !reflect.DeepEqual(newConfig.IngoingConfig, r.ClusterConfig.IngoingConfig) {
 			// the config update is related to the advertisement operator
 			// list all advertisements
 			obj, err := r.AdvClient.Resource("advertisements").List(metav1.ListOptions{})
@@ -97,6 +122,17 @@ func (r *AdvertisementReconciler) WatchConfiguration(kubeconfigPath string, clie
 					r.UpdateAdvertisement(&adv)
 				}
 			}
+			if !reflect.DeepEqual(newConfig.IngoingConfig.MaxAcceptablePrices, r.ClusterConfig.IngoingConfig.MaxAcceptablePrices) {
+				// the new maximum prices will be applied to the Advertisements received from now on
+				klog.Info("AdvertisementConfig changed: the MaxAcceptablePrices have changed")
+				r.ClusterConfig.IngoingConfig.MaxAcceptablePrices = newConfig.IngoingConfig.MaxAcceptablePrices
+			}
+			if newConfig.IngoingConfig.VirtualNodeSplit != r.ClusterConfig.IngoingConfig.VirtualNodeSplit {
+				// the virtual nodes of the Advertisements received from now on will be split accordingly
+				klog.Infof("AdvertisementConfig changed: the VirtualNodeSplit has changed from %v to %v",
+					r.ClusterConfig.IngoingConfig.VirtualNodeSplit, newConfig.IngoingConfig.VirtualNodeSplit)
+				r.ClusterConfig.IngoingConfig.VirtualNodeSplit = newConfig.IngoingConfig.VirtualNodeSplit
+			}
 		}
 	}, client, kubeconfigPath)
 }
@@ -139,7 +175,7 @@ func differentLabels(current []configv1alpha1.LabelPolicy, next []configv1alpha1
 
 func contains(arr []configv1alpha1.LabelPolicy, el configv1alpha1.LabelPolicy) bool {
 	for _, a := range arr {
-		if a.Key == el.Key && a.Policy == el.Policy {
+		if reflect.DeepEqual(a, el) {
 			return true
 		}
 	}
@@ -24,7 +24,7 @@ import (
 	advpkg "github.com/liqotech/liqo/pkg/advertisement-operator"
 	"github.com/liqotech/liqo/pkg/crdClient"
 	objectreferences "github.com/liqotech/liqo/pkg/object-references"
-	"github.com/liqotech/liqo/pkg/virtualKubelet"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -152,6 +152,15 @@ func (r *AdvertisementReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{RequeueAfter: r.RetryTimeout}, nil
 	}
 
+	if r.virtualNodesChanged(&adv) {
+		// the announced zones or node pools have changed: align the virtual-kubelets
+		if err := r.createVirtualKubelet(ctx, &adv); err != nil {
+			return ctrl.Result{}, err
+		}
+		klog.Info("Virtual kubelets updated for cluster " + adv.Spec.ClusterId)
+		return ctrl.Result{RequeueAfter: r.RetryTimeout}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -225,6 +234,14 @@ func (r *AdvertisementReconciler) CheckAdvertisement(adv *advtypes.Advertisement
 		}
 	}
 
+	// if announced prices are higher than the acceptable ones, always refuse the Adv
+	for k, maxPrice := range r.ClusterConfig.IngoingConfig.MaxAcceptablePrices {
+		if price, ok := adv.Spec.Prices[k]; ok && price.Cmp(maxPrice) > 0 {
+			adv.Status.AdvertisementStatus = advtypes.AdvertisementRefused
+			return
+		}
+	}
+
 	switch r.ClusterConfig.IngoingConfig.AcceptPolicy {
 	case configv1alpha1.AutoAcceptMax:
 		if r.AcceptedAdvNum < r.ClusterConfig.IngoingConfig.MaxAcceptableAdvertisement {
@@ -262,8 +279,50 @@ func (r *AdvertisementReconciler) createVirtualKubelet(ctx context.Context, adv
 		klog.Errorf("Cannot find secret %v in namespace %v for the virtual kubelet; error: %v", secRef.Name, secRef.Namespace, err)
 		return err
 	}
-	name := virtualKubelet.VirtualKubeletPrefix + adv.Spec.ClusterId
-	nodeName := virtualKubelet.VirtualNodePrefix + adv.Spec.ClusterId
+
+	// create a virtual-kubelet for each partition of the foreign cluster
+	partitions := advpkg.GetVirtualNodePartitions(adv, r.ClusterConfig.IngoingConfig.VirtualNodeSplit)
+	vkRefs := make([]objectreferences.DeploymentReference, 0, len(partitions))
+	vnodeRefs := make([]objectreferences.NodeReference, 0, len(partitions))
+	for _, partition := range partitions {
+		deploy, err := r.createVirtualKubeletForPartition(ctx, adv, partition)
+		if err != nil {
+			return err
+		}
+		vkRefs = append(vkRefs, objectreferences.DeploymentReference{
+			Namespace: deploy.Namespace,
+			Name:      deploy.Name,
+		})
+		vnodeRefs = append(vnodeRefs, objectreferences.NodeReference{
+			Name: partition.VirtualNodeName(adv.Spec.ClusterId),
+		})
+	}
+
+	// delete the virtual-kubelets of the partitions not announced anymore
+	for _, oldRef := range getVkReferences(adv) {
+		if !containsDeploymentReference(vkRefs, oldRef) {
+			if err := r.deleteVirtualKubelet(ctx, oldRef); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.recordEvent("launching virtual-kubelet for cluster "+adv.Spec.ClusterId, "Normal", "VkCreated", adv)
+	adv.Status.VkCreated = true
+	adv.Status.VkReference = vkRefs[0]
+	adv.Status.VnodeReference = vnodeRefs[0]
+	adv.Status.VkReferences = vkRefs
+	adv.Status.VnodeReferences = vnodeRefs
+	if err := r.Status().Update(ctx, adv); err != nil {
+		klog.Error(err)
+	}
+	return nil
+}
+
+// create the virtual-kubelet mapping a partition of the foreign cluster on a virtual node
+func (r *AdvertisementReconciler) createVirtualKubeletForPartition(ctx context.Context, adv *advtypes.Advertisement, partition advpkg.VirtualNodePartition) (*appsv1.Deployment, error) {
+	name := partition.VirtualKubeletName(adv.Spec.ClusterId)
+	nodeName := partition.VirtualNodeName(adv.Spec.ClusterId)
 	// Create the base resources
 	vkSa := &v1.ServiceAccount{
 		TypeMeta: metav1.TypeMeta{},
@@ -273,9 +332,9 @@ func (r *AdvertisementReconciler) createVirtualKubelet(ctx context.Context, adv
 			OwnerReferences: advpkg.GetOwnerReference(adv),
 		},
 	}
-	err = advpkg.CreateOrUpdate(r.Client, ctx, vkSa)
+	err := advpkg.CreateOrUpdate(r.Client, ctx, vkSa)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vkCrb := &rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
@@ -293,30 +352,72 @@ func (r *AdvertisementReconciler) createVirtualKubelet(ctx context.Context, adv
 	}
 	err = advpkg.CreateOrUpdate(r.Client, ctx, vkCrb)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Create the virtual Kubelet
-	deploy := advpkg.CreateVkDeployment(adv, name, r.KubeletNamespace, r.VKImage, r.InitVKImage, nodeName, r.HomeClusterId)
+	deploy := advpkg.CreateVkDeployment(adv, partition, name, r.KubeletNamespace, r.VKImage, r.InitVKImage, nodeName, r.HomeClusterId)
 	err = advpkg.CreateOrUpdate(r.Client, ctx, deploy)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return deploy, nil
+}
 
-	r.recordEvent("launching virtual-kubelet for cluster "+adv.Spec.ClusterId, "Normal", "VkCreated", adv)
-	adv.Status.VkCreated = true
-	adv.Status.VkReference = objectreferences.DeploymentReference{
-		Namespace: deploy.Namespace,
-		Name:      deploy.Name,
+// delete a virtual-kubelet and its base resources
+// the virtual node is deleted by the garbage collector, since it is owned by the virtual-kubelet deployment
+func (r *AdvertisementReconciler) deleteVirtualKubelet(ctx context.Context, ref objectreferences.DeploymentReference) error {
+	klog.Infof("deleting virtual-kubelet %v/%v", ref.Namespace, ref.Name)
+	objects := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace}},
+		&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ref.Name}},
 	}
-	adv.Status.VnodeReference = objectreferences.NodeReference{
-		Name: nodeName,
-	}
-	if err := r.Status().Update(ctx, adv); err != nil {
-		klog.Error(err)
+	for _, obj := range objects {
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
 	return nil
 }
 
+// check if the virtual nodes of an Advertisement do not match its partitions anymore,
+// i.e. if the announced zones or node pools, or the split policy, have changed
+func (r *AdvertisementReconciler) virtualNodesChanged(adv *advtypes.Advertisement) bool {
+	partitions := advpkg.GetVirtualNodePartitions(adv, r.ClusterConfig.IngoingConfig.VirtualNodeSplit)
+	vkRefs := getVkReferences(adv)
+	if len(partitions) != len(vkRefs) {
+		return true
+	}
+	for _, partition := range partitions {
+		ref := objectreferences.DeploymentReference{
+			Namespace: r.KubeletNamespace,
+			Name:      partition.VirtualKubeletName(adv.Spec.ClusterId),
+		}
+		if !containsDeploymentReference(vkRefs, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// get the references to the virtual-kubelets of an Advertisement
+// the Advertisements created before the virtual node split only have the VkReference
+func getVkReferences(adv *advtypes.Advertisement) []objectreferences.DeploymentReference {
+	if len(adv.Status.VkReferences) == 0 && adv.Status.VkReference.Name != "" {
+		return []objectreferences.DeploymentReference{adv.Status.VkReference}
+	}
+	return adv.Status.VkReferences
+}
+
+func containsDeploymentReference(refs []objectreferences.DeploymentReference, ref objectreferences.DeploymentReference) bool {
+	for _, r := range refs {
+		if r.Namespace == ref.Namespace && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *AdvertisementReconciler) recordEvent(msg string, eventType string, eventReason string, adv *advtypes.Advertisement) {
 	klog.Info(msg)
 	r.EventsRecorder.Event(adv, eventType, eventReason, msg)
@@ -0,0 +1,117 @@
+package advertisementOperator
+
+import (
+	"strings"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// prices announced when the PricingConfig does not specify them
+var (
+	defaultResourcePrices = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("2m"),
+	}
+	defaultImagePrice = resource.MustParse("5")
+)
+
+// compute the utilisation percentage of every allocatable resource, given the resources requested by the pods
+func ComputeUtilisation(allocatable corev1.ResourceList, reqs corev1.ResourceList) map[corev1.ResourceName]int64 {
+	utilisation := make(map[corev1.ResourceName]int64, len(allocatable))
+	for k, v := range allocatable {
+		total := v.MilliValue()
+		if total <= 0 {
+			continue
+		}
+		var used int64
+		if req, ok := reqs[k]; ok {
+			used = req.MilliValue()
+		}
+		percentage := used * 100 / total
+		if percentage > 100 {
+			percentage = 100
+		} else if percentage < 0 {
+			percentage = 0
+		}
+		utilisation[k] = percentage
+	}
+	return utilisation
+}
+
+// create prices resource for advertisement
+// the base price of every resource is increased by the surge multiplier, driven by the current utilisation,
+// then the discount granted to the foreign cluster is applied to every price (resources and images)
+func ComputePrices(images []corev1.ContainerImage, utilisation map[corev1.ResourceName]int64,
+	pricingConfig configv1alpha1.PricingConfig, foreignClusterId string) corev1.ResourceList {
+
+	discount := getClusterDiscount(pricingConfig.ClusterDiscounts, foreignClusterId)
+
+	basePrices := defaultResourcePrices.DeepCopy()
+	for k, v := range pricingConfig.BasePrices {
+		basePrices[k] = v.DeepCopy()
+	}
+
+	prices := corev1.ResourceList{}
+	for k, v := range basePrices {
+		surge := computeSurge(utilisation[k], pricingConfig.SurgeThreshold, pricingConfig.SurgePercentage)
+		prices[k] = applyPercentages(v, 100+surge, 100-discount)
+	}
+	for _, image := range images {
+		for _, name := range image.Names {
+			price := getImagePrice(pricingConfig, name)
+			prices[corev1.ResourceName(name)] = applyPercentages(price, 100, 100-discount)
+		}
+	}
+	return prices
+}
+
+// the surge grows linearly from 0, when the utilisation is equal to the threshold, to surgePercentage, when the utilisation is 100%
+func computeSurge(utilisation int64, threshold int32, surgePercentage int32) int64 {
+	if surgePercentage <= 0 || utilisation <= int64(threshold) || threshold >= 100 {
+		return 0
+	}
+	return int64(surgePercentage) * (utilisation - int64(threshold)) / (100 - int64(threshold))
+}
+
+func getClusterDiscount(discounts []configv1alpha1.ClusterDiscount, clusterId string) int64 {
+	for _, d := range discounts {
+		if d.ClusterID == clusterId {
+			if d.Percentage > 100 {
+				return 100
+			}
+			return int64(d.Percentage)
+		}
+	}
+	return 0
+}
+
+// get the price of an image: the longest matching prefix wins
+func getImagePrice(pricingConfig configv1alpha1.PricingConfig, imageName string) resource.Quantity {
+	var price *resource.Quantity
+	matchLen := -1
+	for i := range pricingConfig.ImagePrices {
+		ip := &pricingConfig.ImagePrices[i]
+		if strings.HasPrefix(imageName, ip.Prefix) && len(ip.Prefix) > matchLen {
+			price = &ip.Price
+			matchLen = len(ip.Prefix)
+		}
+	}
+	if price != nil {
+		return price.DeepCopy()
+	}
+	if pricingConfig.DefaultImagePrice != nil {
+		return pricingConfig.DefaultImagePrice.DeepCopy()
+	}
+	return defaultImagePrice.DeepCopy()
+}
+
+// multiply the price by the given percentages, using millis to preserve precision
+func applyPercentages(price resource.Quantity, percentages ...int64) resource.Quantity {
+	value := price.MilliValue()
+	for _, p := range percentages {
+		value = value * p / 100
+	}
+	return *resource.NewMilliQuantity(value, price.Format)
+}
@@ -0,0 +1,190 @@
+package advertisementOperator
+
+import (
+	"sort"
+
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// compute the topology of the physical nodes, i.e. the resources still allocatable on the largest nodes
+// and the breakdown of the free resources by region and zone and, if nodePoolLabel is set, by node pool
+// a node without zone and region labels (or without the node pool label) is only considered in the largest nodes summary
+func ComputeTopology(physicalNodes *corev1.NodeList, podList *corev1.PodList, nodePoolLabel string) advtypes.AdvertisementTopology {
+	free := GetNodesFreeResources(physicalNodes, podList)
+
+	topology := advtypes.AdvertisementTopology{
+		NodePoolLabel: nodePoolLabel,
+	}
+	zones := make(map[[2]string]*advtypes.ZoneTopology)
+	pools := make(map[string]*advtypes.NodePoolTopology)
+	for i := range physicalNodes.Items {
+		node := &physicalNodes.Items[i]
+		nodeFree := free[node.Name]
+		topology.LargestNodes = addLargestNode(topology.LargestNodes, nodeFree)
+
+		if pool := node.GetLabels()[nodePoolLabel]; nodePoolLabel != "" && pool != "" {
+			pt, ok := pools[pool]
+			if !ok {
+				pt = &advtypes.NodePoolTopology{
+					Name:      pool,
+					Resources: corev1.ResourceList{},
+				}
+				pools[pool] = pt
+			}
+			pt.Nodes++
+			addResourceLists(&pt.Resources, &nodeFree)
+			pt.LargestNodes = addLargestNode(pt.LargestNodes, nodeFree)
+		}
+
+		region, zone := GetNodeRegionAndZone(node)
+		if region == "" && zone == "" {
+			continue
+		}
+		key := [2]string{region, zone}
+		zt, ok := zones[key]
+		if !ok {
+			zt = &advtypes.ZoneTopology{
+				Region:    region,
+				Zone:      zone,
+				Resources: corev1.ResourceList{},
+			}
+			if zone != "" {
+				zt.ZoneLabel = corev1.LabelZoneFailureDomainStable
+			}
+			zones[key] = zt
+		}
+		// the kubelets set both labels until the deprecated one is removed, hence the nodes without the stable
+		// label are selected through the deprecated one
+		if _, ok := node.GetLabels()[corev1.LabelZoneFailureDomainStable]; zone != "" && !ok {
+			zt.ZoneLabel = corev1.LabelZoneFailureDomain
+		}
+		zt.Nodes++
+		addResourceLists(&zt.Resources, &nodeFree)
+		zt.LargestNodes = addLargestNode(zt.LargestNodes, nodeFree)
+	}
+
+	topology.LargestNodes = sortLargestNodes(topology.LargestNodes)
+	for _, zt := range zones {
+		zt.LargestNodes = sortLargestNodes(zt.LargestNodes)
+		topology.Zones = append(topology.Zones, *zt)
+	}
+	// sort the zones to always produce the same Advertisement
+	sort.Slice(topology.Zones, func(i, j int) bool {
+		if topology.Zones[i].Region != topology.Zones[j].Region {
+			return topology.Zones[i].Region < topology.Zones[j].Region
+		}
+		return topology.Zones[i].Zone < topology.Zones[j].Zone
+	})
+	for _, pt := range pools {
+		pt.LargestNodes = sortLargestNodes(pt.LargestNodes)
+		topology.NodePools = append(topology.NodePools, *pt)
+	}
+	sort.Slice(topology.NodePools, func(i, j int) bool {
+		return topology.NodePools[i].Name < topology.NodePools[j].Name
+	})
+	return topology
+}
+
+// get the resources still allocatable on every node, given the pods running on it
+func GetNodesFreeResources(nodes *corev1.NodeList, podList *corev1.PodList) map[string]corev1.ResourceList {
+	free := make(map[string]corev1.ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		free[node.Name] = node.Status.Allocatable.DeepCopy()
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		nodeFree, ok := free[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		for k, v := range podReqs {
+			if qnt, ok := nodeFree[k]; ok {
+				qnt.Sub(v)
+				nodeFree[k] = qnt
+			}
+		}
+	}
+	for _, nodeFree := range free {
+		for k, v := range nodeFree {
+			if v.Sign() < 0 {
+				v.Set(0)
+				nodeFree[k] = v
+			}
+		}
+	}
+	return free
+}
+
+// get the region and the zone of a node, falling back to the deprecated labels
+func GetNodeRegionAndZone(node *corev1.Node) (region string, zone string) {
+	nodeLabels := node.GetLabels()
+	if region = nodeLabels[corev1.LabelZoneRegionStable]; region == "" {
+		region = nodeLabels[corev1.LabelZoneRegion]
+	}
+	if zone = nodeLabels[corev1.LabelZoneFailureDomainStable]; zone == "" {
+		zone = nodeLabels[corev1.LabelZoneFailureDomain]
+	}
+	return region, zone
+}
+
+// add the free resources of a node to the largest nodes, unless another node has at least the same amount of
+// every resource, and remove the nodes it exceeds: a pod fits a node of the cluster if and only if it fits one
+// of the largest nodes
+func addLargestNode(largestNodes []corev1.ResourceList, nodeFree corev1.ResourceList) []corev1.ResourceList {
+	for _, largest := range largestNodes {
+		if covers(largest, nodeFree) {
+			return largestNodes
+		}
+	}
+	result := largestNodes[:0]
+	for _, largest := range largestNodes {
+		if !covers(nodeFree, largest) {
+			result = append(result, largest)
+		}
+	}
+	return append(result, nodeFree.DeepCopy())
+}
+
+// sort the largest nodes, to always produce the same Advertisement
+// none of them is dropped, as each one fits some pods that no other node fits
+func sortLargestNodes(largestNodes []corev1.ResourceList) []corev1.ResourceList {
+	sort.Slice(largestNodes, func(i, j int) bool {
+		return compareResourceLists(largestNodes[i], largestNodes[j]) > 0
+	})
+	return largestNodes
+}
+
+// check if a has at least the quantity of every resource of b
+func covers(a, b corev1.ResourceList) bool {
+	for k, v := range b {
+		// a missing resource counts as zero
+		if qnt := a[k]; qnt.Cmp(v) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compare the quantities of the resources in alphabetical order of their names
+func compareResourceLists(a, b corev1.ResourceList) int {
+	names := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		names = append(names, string(k))
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			names = append(names, string(k))
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		qa, qb := a[corev1.ResourceName(name)], b[corev1.ResourceName(name)]
+		if c := qa.Cmp(qb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
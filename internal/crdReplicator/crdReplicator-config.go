@@ -1,6 +1,7 @@
 package crdReplicator
 
 import (
+	"context"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/pkg/clusterConfig"
 	"github.com/liqotech/liqo/pkg/crdClient"
@@ -11,7 +12,8 @@ import (
 	"reflect"
 )
 
-func (d *CRDReplicatorReconciler) WatchConfiguration(config *rest.Config, gv *schema.GroupVersion) error {
+// WatchConfiguration updates the resources to replicate when the ClusterConfig changes, until the context is done
+func (d *CRDReplicatorReconciler) WatchConfiguration(ctx context.Context, config *rest.Config, gv *schema.GroupVersion) error {
 	config.ContentConfig.GroupVersion = gv
 	config.APIPath = "/apis"
 	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
@@ -21,12 +23,16 @@ func (d *CRDReplicatorReconciler) WatchConfiguration(config *rest.Config, gv *sc
 		klog.Errorf("an error occurred while starting the watcher for the clusterConfig CRD: %s", err)
 		return err
 	}
-	go clusterConfig.WatchConfiguration(d.UpdateConfig, CRDclient, "")
+	go clusterConfig.WatchConfigurationContext(ctx, d.UpdateConfig, CRDclient, "")
 	return nil
 }
 
 func (d *CRDReplicatorReconciler) UpdateConfig(cfg *configv1alpha1.ClusterConfig) {
 	resources := d.GetConfig(cfg)
+	rules, scopes := d.GetRulesConfig(cfg), d.GetScopesConfig(cfg)
+	d.configMutex.Lock()
+	d.ResourceRules, d.ResourceScopes = rules, scopes
+	d.configMutex.Unlock()
 	if !reflect.DeepEqual(d.RegisteredResources, resources) {
 		klog.Info("updating the list of registered resources to be replicated")
 		d.UnregisteredResources = d.GetRemovedResources(resources)
@@ -48,6 +54,42 @@ func (d *CRDReplicatorReconciler) GetConfig(cfg *configv1alpha1.ClusterConfig) [
 	return config
 }
 
+//GetRulesConfig returns the replication rules of the resources registered with rules, indexed by resource
+func (d *CRDReplicatorReconciler) GetRulesConfig(cfg *configv1alpha1.ClusterConfig) map[string]*ReplicationRules {
+	rules := map[string]*ReplicationRules{}
+	for _, res := range cfg.Spec.DispatcherConfig.ResourcesToReplicate {
+		if res.Rules == nil {
+			continue
+		}
+		gvr := schema.GroupVersionResource{
+			Group:    res.Group,
+			Version:  res.Version,
+			Resource: res.Resource,
+		}
+		rules[gvr.String()] = newReplicationRules(res.Rules)
+	}
+	return rules
+}
+
+//GetScopesConfig returns the replication scopes of the resources registered with namespace mapping or selectors,
+//indexed by resource
+func (d *CRDReplicatorReconciler) GetScopesConfig(cfg *configv1alpha1.ClusterConfig) map[string]*ReplicationScope {
+	scopes := map[string]*ReplicationScope{}
+	for i := range cfg.Spec.DispatcherConfig.ResourcesToReplicate {
+		res := &cfg.Spec.DispatcherConfig.ResourcesToReplicate[i]
+		if len(res.NamespaceMapping) == 0 && res.Selector == nil && res.PeerSelector == nil {
+			continue
+		}
+		gvr := schema.GroupVersionResource{
+			Group:    res.Group,
+			Version:  res.Version,
+			Resource: res.Resource,
+		}
+		scopes[gvr.String()] = newReplicationScope(res)
+	}
+	return scopes
+}
+
 func (d *CRDReplicatorReconciler) GetRemovedResources(resources []schema.GroupVersionResource) []string {
 	oldRes := []string{}
 	diffRes := []string{}
@@ -1,9 +1,11 @@
 package crdReplicator
 
 import (
+	"context"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"testing"
 )
@@ -162,17 +164,49 @@ func TestDispatcherReconciler_UpdateConfig(t *testing.T) {
 	}
 }
 
+//the rules and the scopes are swapped while the workers read them, run with -race to check it
+func TestDispatcherReconciler_UpdateConfigConcurrent(t *testing.T) {
+	dispatcher := CRDReplicatorReconciler{}
+	cfg := &configv1alpha1.ClusterConfig{
+		Spec: configv1alpha1.ClusterConfigSpec{
+			DispatcherConfig: configv1alpha1.DispatcherConfig{ResourcesToReplicate: []configv1alpha1.Resource{
+				{
+					Group:    netv1alpha1.GroupVersion.Group,
+					Version:  netv1alpha1.GroupVersion.Version,
+					Resource: "networkconfigs",
+					Rules:    &configv1alpha1.ReplicationRules{LocalToRemote: []string{"spec"}},
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"replicate": "true"}},
+				},
+			}},
+		},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			dispatcher.UpdateConfig(cfg)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		dispatcher.GetRules(gvr)
+		dispatcher.GetScope(gvr)
+	}
+	<-done
+	assert.NotEqual(t, defaultRules, dispatcher.GetRules(gvr))
+	assert.NotEqual(t, defaultScope, dispatcher.GetScope(gvr))
+}
+
 //we test that if the *rest.config of the custer is not correct the function return the error
 func TestDispatcherReconciler_WatchConfiguration(t *testing.T) {
 	dispatcher := CRDReplicatorReconciler{}
 	//test1
 	//the group version is not correct and we expect an error
 	config := k8sManagerLocal.GetConfig()
-	err := dispatcher.WatchConfiguration(config, nil)
+	err := dispatcher.WatchConfiguration(context.TODO(), config, nil)
 	assert.NotNil(t, err, "error should be not nil")
 
 	//test2
 	//the group version is not correct and we expect an error
-	err = dispatcher.WatchConfiguration(config, &configv1alpha1.GroupVersion)
+	err = dispatcher.WatchConfiguration(context.TODO(), config, &configv1alpha1.GroupVersion)
 	assert.Nil(t, err, "error should be not nil")
 }
@@ -0,0 +1,203 @@
+package crdReplicator
+
+import (
+	"context"
+	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+var (
+	//the first reconnection to a peering cluster is attempted after InitialBackoff,
+	//each following one doubles the delay up to MaxBackoff
+	InitialBackoff = 5 * time.Second
+	MaxBackoff     = 5 * time.Minute
+)
+
+//RemoteConnection is the state of the connection to the API server of a peering cluster
+type RemoteConnection struct {
+	//the secret containing the kubeconfig the connection has been created from
+	SecretRef corev1.ObjectReference
+	//the resource version of the secret when the connection has been created,
+	//the connection is created again with the new kubeconfig when it changes
+	SecretVersion string
+	//client used to check the health of the remote API server
+	Discovery discovery.DiscoveryInterface
+	//the replication status reported in the ForeignCluster
+	Status v1alpha1.ReplicationStatus
+}
+
+//returns the reference to the secret with the kubeconfig of the peering cluster, the outgoing identity is preferred
+func getIdentityRef(fc *v1alpha1.ForeignCluster) *corev1.ObjectReference {
+	if fc.Status.Outgoing.AvailableIdentity {
+		return fc.Status.Outgoing.IdentityRef
+	} else if fc.Status.Incoming.AvailableIdentity {
+		return fc.Status.Incoming.IdentityRef
+	}
+	return nil
+}
+
+func (d *CRDReplicatorReconciler) getConnection(remoteClusterID string) *RemoteConnection {
+	if d.RemoteConnections == nil {
+		d.RemoteConnections = make(map[string]*RemoteConnection)
+	}
+	if d.Backoff == nil {
+		d.Backoff = flowcontrol.NewBackOff(InitialBackoff, MaxBackoff)
+	}
+	conn, ok := d.RemoteConnections[remoteClusterID]
+	if !ok {
+		conn = &RemoteConnection{}
+		d.RemoteConnections[remoteClusterID] = conn
+	}
+	return conn
+}
+
+func (d *CRDReplicatorReconciler) isConnected(remoteClusterID string) bool {
+	_, dynClientOk := d.RemoteDynClients[remoteClusterID]
+	_, dynFacOk := d.RemoteDynSharedInformerFactory[remoteClusterID]
+	return dynClientOk && dynFacOk
+}
+
+//checks the connection to the peering cluster: an healthy connection is recreated if the kubeconfig it has been
+//created from has changed, an unhealthy one is torn down and created again with an exponential backoff
+func (d *CRDReplicatorReconciler) reconcileConnection(fc *v1alpha1.ForeignCluster, ref *corev1.ObjectReference) ctrl.Result {
+	remoteClusterID := fc.Spec.ClusterIdentity.ClusterID
+	conn := d.getConnection(remoteClusterID)
+	res := d.checkConnection(conn, ref, remoteClusterID)
+	if err := d.updateReplicationStatus(fc, &conn.Status); err != nil {
+		klog.Errorf("%s -> unable to update the replication status of foreign cluster %s: %s", d.ClusterID, fc.Name, err)
+	}
+	return res
+}
+
+func (d *CRDReplicatorReconciler) checkConnection(conn *RemoteConnection, ref *corev1.ObjectReference, remoteClusterID string) ctrl.Result {
+	secret, err := d.ClientSet.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return d.connectionFailed(conn, remoteClusterID, err)
+	}
+	if d.isConnected(remoteClusterID) {
+		if conn.SecretRef.Namespace != ref.Namespace || conn.SecretRef.Name != ref.Name || conn.SecretVersion != secret.ResourceVersion {
+			klog.Infof("%s -> the kubeconfig of the peering cluster has changed, reloading the connection", remoteClusterID)
+			d.tearDownConnection(remoteClusterID)
+		} else if _, err = conn.Discovery.ServerVersion(); err != nil {
+			return d.connectionFailed(conn, remoteClusterID, err)
+		} else {
+			return d.connectionSucceeded(conn, remoteClusterID)
+		}
+	} else if d.Backoff.IsInBackOffSinceUpdate(remoteClusterID, time.Now()) {
+		//wait for the next attempt
+		return ctrl.Result{RequeueAfter: time.Until(conn.Status.NextRetry.Time)}
+	}
+
+	config, err := d.getKubeConfig(d.ClientSet, secret, remoteClusterID)
+	if err != nil {
+		return d.connectionFailed(conn, remoteClusterID, err)
+	}
+	conn.Discovery, err = discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return d.connectionFailed(conn, remoteClusterID, err)
+	}
+	//the watchers are started only if the remote API server is reachable with the current credentials
+	if _, err = conn.Discovery.ServerVersion(); err != nil {
+		return d.connectionFailed(conn, remoteClusterID, err)
+	}
+	if err = d.setUpConnectionToPeeringCluster(config, remoteClusterID); err != nil {
+		return d.connectionFailed(conn, remoteClusterID, err)
+	}
+	conn.SecretRef = *ref
+	conn.SecretVersion = secret.ResourceVersion
+	klog.Infof("%s -> connected to the peering cluster", remoteClusterID)
+	return d.connectionSucceeded(conn, remoteClusterID)
+}
+
+func (d *CRDReplicatorReconciler) connectionFailed(conn *RemoteConnection, remoteClusterID string, err error) ctrl.Result {
+	klog.Errorf("%s -> the connection to the peering cluster failed: %s", remoteClusterID, err)
+	d.tearDownConnection(remoteClusterID)
+	d.Backoff.Next(remoteClusterID, time.Now())
+	delay := d.Backoff.Get(remoteClusterID)
+	nextRetry := metav1.NewTime(time.Now().Add(delay))
+	conn.Status.Connected = false
+	conn.Status.Error = err.Error()
+	conn.Status.NextRetry = &nextRetry
+	klog.Infof("%s -> retrying the connection in %s", remoteClusterID, delay)
+	return ctrl.Result{RequeueAfter: delay}
+}
+
+func (d *CRDReplicatorReconciler) connectionSucceeded(conn *RemoteConnection, remoteClusterID string) ctrl.Result {
+	d.Backoff.Reset(remoteClusterID)
+	now := metav1.Now()
+	conn.Status = v1alpha1.ReplicationStatus{
+		Connected: true,
+		LastSync:  &now,
+	}
+	return result
+}
+
+//stops the remote watchers of the peering cluster and removes its clients, they are created again at the next
+//connection. The local watchers are kept running: while the peering cluster is not connected they ignore the
+//events, and the local resources are replicated again at the first resync after the connection is restored
+func (d *CRDReplicatorReconciler) tearDownConnection(remoteClusterID string) {
+	for res, ch := range d.RemoteWatchers[remoteClusterID] {
+		close(ch)
+		klog.Infof("%s -> stopping remote watcher for resource: %s", remoteClusterID, res)
+	}
+	delete(d.RemoteWatchers, remoteClusterID)
+	d.remoteMutex.Lock()
+	defer d.remoteMutex.Unlock()
+	delete(d.RemoteDynClients, remoteClusterID)
+	delete(d.RemoteDynSharedInformerFactory, remoteClusterID)
+}
+
+//stops replicating the resources to a peering cluster whose ForeignCluster has been deleted or which is not peered
+//anymore: the connection is torn down and the local watchers and the workers of the peering cluster are stopped
+func (d *CRDReplicatorReconciler) removePeer(remoteClusterID string) {
+	klog.Infof("%s -> removing the peering cluster", remoteClusterID)
+	d.tearDownConnection(remoteClusterID)
+	for res, ch := range d.LocalWatchers[remoteClusterID] {
+		close(ch)
+		klog.Infof("%s -> stopping local watcher for resource: %s", remoteClusterID, res)
+	}
+	delete(d.LocalWatchers, remoteClusterID)
+	d.removeQueue(remoteClusterID)
+	delete(d.RemoteConnections, remoteClusterID)
+	if d.Backoff != nil {
+		d.Backoff.Reset(remoteClusterID)
+	}
+	d.remoteMutex.Lock()
+	defer d.remoteMutex.Unlock()
+	delete(d.peerLabels, remoteClusterID)
+}
+
+//writes the replication status in the ForeignCluster, if it is only the time of the last sync that has changed the
+//status is written once per resync period, to not trigger a new reconciliation at each update
+func (d *CRDReplicatorReconciler) updateReplicationStatus(fc *v1alpha1.ForeignCluster, status *v1alpha1.ReplicationStatus) error {
+	current := fc.Status.Replication
+	if current != nil && !isReplicationStatusChanged(current, status) {
+		return nil
+	}
+	fc.Status.Replication = status.DeepCopy()
+	return d.Update(context.TODO(), fc)
+}
+
+func isReplicationStatusChanged(current, status *v1alpha1.ReplicationStatus) bool {
+	if current.Connected != status.Connected || current.Error != status.Error || !isSameTime(current.NextRetry, status.NextRetry) {
+		return true
+	}
+	if current.LastSync == nil || status.LastSync == nil {
+		return current.LastSync != status.LastSync
+	}
+	return status.LastSync.Sub(current.LastSync.Time) >= ResyncPeriod
+}
+
+//compares two times with the precision they are serialized with in the ForeignCluster
+func isSameTime(t1, t2 *metav1.Time) bool {
+	if t1 == nil || t2 == nil {
+		return t1 == t2
+	}
+	return t1.Unix() == t2.Unix()
+}
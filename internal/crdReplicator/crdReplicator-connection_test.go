@@ -0,0 +1,235 @@
+package crdReplicator
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/flowcontrol"
+	"net/http"
+	"net/http/httptest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakectrl "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+//a remote API server whose health can be switched
+type fakeRemoteServer struct {
+	*httptest.Server
+	healthy bool
+}
+
+func newFakeRemoteServer() *fakeRemoteServer {
+	s := &fakeRemoteServer{healthy: true}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(version.Info{GitVersion: "v1.19.0"})
+	}))
+	return s
+}
+
+func getKubeconfigSecret(t *testing.T, server string, resourceVersion string) *corev1.Secret {
+	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"remote": {Server: server},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"remote": {Token: "token"},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"remote": {Cluster: "remote", AuthInfo: "remote"},
+		},
+		CurrentContext: "remote",
+	})
+	assert.Nil(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "remote-identity",
+			Namespace:       "default",
+			ResourceVersion: resourceVersion,
+		},
+		Data: map[string][]byte{
+			"kubeconfig": kubeconfig,
+		},
+	}
+}
+
+func getConnectionCRDReplicator(secret *corev1.Secret) *CRDReplicatorReconciler {
+	return &CRDReplicatorReconciler{
+		ClientSet:                      fake.NewSimpleClientset(secret),
+		ClusterID:                      localClusterID,
+		RemoteDynClients:               make(map[string]dynamic.Interface),
+		RemoteDynSharedInformerFactory: make(map[string]dynamicinformer.DynamicSharedInformerFactory),
+		LocalWatchers:                  make(map[string]map[string]chan struct{}),
+		RemoteWatchers:                 make(map[string]map[string]chan struct{}),
+		Backoff:                        flowcontrol.NewBackOff(100*time.Millisecond, 400*time.Millisecond),
+	}
+}
+
+//the connection is torn down when the remote API server is not reachable and recreated with an exponential backoff
+func TestCRDReplicatorReconciler_ConnectionBackoff(t *testing.T) {
+	server := newFakeRemoteServer()
+	defer server.Close()
+	secret := getKubeconfigSecret(t, server.URL, "1")
+	d := getConnectionCRDReplicator(secret)
+	ref := &corev1.ObjectReference{Namespace: secret.Namespace, Name: secret.Name}
+	conn := d.getConnection(remoteClusterID)
+
+	res := d.checkConnection(conn, ref, remoteClusterID)
+	assert.Equal(t, result, res)
+	assert.True(t, d.isConnected(remoteClusterID), "the connection should be set up")
+	assert.True(t, conn.Status.Connected)
+	assert.NotNil(t, conn.Status.LastSync)
+
+	//the remote API server becomes unreachable, the remote watchers are stopped
+	stopCh := make(chan struct{})
+	d.RemoteWatchers[remoteClusterID] = map[string]chan struct{}{gvr.String(): stopCh}
+	server.healthy = false
+	res = d.checkConnection(conn, ref, remoteClusterID)
+	assert.Equal(t, 100*time.Millisecond, res.RequeueAfter)
+	assert.False(t, d.isConnected(remoteClusterID), "the connection should be torn down")
+	assert.False(t, conn.Status.Connected)
+	assert.NotEmpty(t, conn.Status.Error)
+	assert.NotNil(t, conn.Status.NextRetry)
+	_, open := <-stopCh
+	assert.False(t, open, "the remote watcher should be stopped")
+	_, ok := d.RemoteWatchers[remoteClusterID]
+	assert.False(t, ok)
+
+	//no attempt is made before the backoff expires
+	res = d.checkConnection(conn, ref, remoteClusterID)
+	assert.True(t, res.RequeueAfter <= 100*time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, d.Backoff.Get(remoteClusterID))
+
+	//each failed attempt doubles the backoff
+	time.Sleep(150 * time.Millisecond)
+	res = d.checkConnection(conn, ref, remoteClusterID)
+	assert.Equal(t, 200*time.Millisecond, res.RequeueAfter)
+
+	//the connection is restored and the backoff is reset
+	server.healthy = true
+	time.Sleep(250 * time.Millisecond)
+	res = d.checkConnection(conn, ref, remoteClusterID)
+	assert.Equal(t, result, res)
+	assert.True(t, d.isConnected(remoteClusterID))
+	assert.True(t, conn.Status.Connected)
+	assert.Empty(t, conn.Status.Error)
+	assert.Nil(t, conn.Status.NextRetry)
+	assert.Equal(t, time.Duration(0), d.Backoff.Get(remoteClusterID))
+}
+
+//the connection is recreated when the secret containing the kubeconfig changes
+func TestCRDReplicatorReconciler_ConnectionReload(t *testing.T) {
+	server := newFakeRemoteServer()
+	defer server.Close()
+	secret := getKubeconfigSecret(t, server.URL, "1")
+	d := getConnectionCRDReplicator(secret)
+	ref := &corev1.ObjectReference{Namespace: secret.Namespace, Name: secret.Name}
+	conn := d.getConnection(remoteClusterID)
+
+	d.checkConnection(conn, ref, remoteClusterID)
+	dynClient := d.RemoteDynClients[remoteClusterID]
+	assert.NotNil(t, dynClient)
+	d.checkConnection(conn, ref, remoteClusterID)
+	assert.True(t, dynClient == d.RemoteDynClients[remoteClusterID], "the connection should not be recreated")
+
+	//the kubeconfig is rotated, pointing to a new API server
+	newServer := newFakeRemoteServer()
+	defer newServer.Close()
+	_, err := d.ClientSet.CoreV1().Secrets(secret.Namespace).Update(context.TODO(), getKubeconfigSecret(t, newServer.URL, "2"), metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	server.healthy = false
+	res := d.checkConnection(conn, ref, remoteClusterID)
+	assert.Equal(t, result, res)
+	assert.True(t, conn.Status.Connected)
+	assert.Equal(t, "2", conn.SecretVersion)
+	assert.True(t, dynClient != d.RemoteDynClients[remoteClusterID], "the connection should be recreated")
+
+	//the secret does not exist anymore
+	err = d.ClientSet.CoreV1().Secrets(secret.Namespace).Delete(context.TODO(), secret.Name, metav1.DeleteOptions{})
+	assert.Nil(t, err)
+	d.checkConnection(conn, ref, remoteClusterID)
+	assert.False(t, d.isConnected(remoteClusterID))
+	assert.False(t, conn.Status.Connected)
+}
+
+//the connection, the local watchers and the workers of the peering cluster are stopped when its ForeignCluster is deleted
+func TestCRDReplicatorReconciler_RemovePeer(t *testing.T) {
+	assert.Nil(t, v1alpha1.AddToScheme(scheme.Scheme))
+	server := newFakeRemoteServer()
+	defer server.Close()
+	secret := getKubeconfigSecret(t, server.URL, "1")
+	fc := &v1alpha1.ForeignCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foreign-cluster"},
+		Spec: v1alpha1.ForeignClusterSpec{
+			ClusterIdentity: v1alpha1.ClusterIdentity{ClusterID: remoteClusterID},
+		},
+		Status: v1alpha1.ForeignClusterStatus{
+			Outgoing: v1alpha1.Outgoing{
+				AvailableIdentity: true,
+				IdentityRef:       &corev1.ObjectReference{Namespace: secret.Namespace, Name: secret.Name},
+			},
+		},
+	}
+	d := getConnectionCRDReplicator(secret)
+	d.Client = fakectrl.NewFakeClientWithScheme(scheme.Scheme, fc)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: fc.Name}}
+
+	_, err := d.Reconcile(req)
+	assert.Nil(t, err)
+	assert.True(t, d.isConnected(remoteClusterID))
+	stopCh := make(chan struct{})
+	d.LocalWatchers[remoteClusterID] = map[string]chan struct{}{gvr.String(): stopCh}
+	q := d.getQueue(remoteClusterID)
+
+	assert.Nil(t, d.Delete(context.TODO(), fc))
+	_, err = d.Reconcile(req)
+	assert.Nil(t, err)
+	assert.False(t, d.isConnected(remoteClusterID))
+	assert.NotContains(t, d.RemoteConnections, remoteClusterID)
+	assert.NotContains(t, d.LocalWatchers, remoteClusterID)
+	_, open := <-stopCh
+	assert.False(t, open, "the local watcher should be stopped")
+	assert.True(t, q.ShuttingDown())
+	assert.NotContains(t, d.queues, remoteClusterID)
+	//the workers have already returned
+	q.workers.Wait()
+}
+
+func TestCRDReplicatorReconciler_IsReplicationStatusChanged(t *testing.T) {
+	now := metav1.Now()
+	later := metav1.NewTime(now.Add(time.Second))
+	resync := metav1.NewTime(now.Add(ResyncPeriod))
+	retry := metav1.NewTime(now.Add(time.Minute))
+	//the time serialized in the ForeignCluster loses the sub-second precision
+	retryRounded := metav1.NewTime(retry.Truncate(time.Second))
+	tests := []struct {
+		current *v1alpha1.ReplicationStatus
+		status  *v1alpha1.ReplicationStatus
+		changed bool
+	}{
+		{&v1alpha1.ReplicationStatus{Connected: true, LastSync: &now}, &v1alpha1.ReplicationStatus{Connected: true, LastSync: &later}, false},
+		{&v1alpha1.ReplicationStatus{Connected: true, LastSync: &now}, &v1alpha1.ReplicationStatus{Connected: true, LastSync: &resync}, true},
+		{&v1alpha1.ReplicationStatus{Connected: true, LastSync: &now}, &v1alpha1.ReplicationStatus{Connected: false, LastSync: &now, Error: "error", NextRetry: &retry}, true},
+		{&v1alpha1.ReplicationStatus{LastSync: &now, Error: "error", NextRetry: &retryRounded}, &v1alpha1.ReplicationStatus{LastSync: &now, Error: "error", NextRetry: &retry}, false},
+		{&v1alpha1.ReplicationStatus{Error: "error"}, &v1alpha1.ReplicationStatus{Error: "another error"}, true},
+	}
+	for i, test := range tests {
+		assert.Equal(t, test.changed, isReplicationStatusChanged(test.current, test.status), "test %d", i)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/pkg/crdClient"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,12 +18,14 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -43,7 +46,7 @@ var (
 type CRDReplicatorReconciler struct {
 	Scheme *runtime.Scheme
 	client.Client
-	ClientSet *kubernetes.Clientset
+	ClientSet kubernetes.Interface
 	ClusterID string
 	//for each remote cluster we save dynamic client connected to its API server
 	RemoteDynClients map[string]dynamic.Interface
@@ -55,6 +58,13 @@ type CRDReplicatorReconciler struct {
 	LocalDynSharedInformerFactory dynamicinformer.DynamicSharedInformerFactory
 	//a list of GVRs of resources to be replicated
 	RegisteredResources []schema.GroupVersionResource
+	//for each registered resource with replication rules, the fields replicated in each direction:(registeredResource, rules)
+	ResourceRules map[string]*ReplicationRules
+	//for each registered resource with namespace mapping or selectors, the namespaces and the peering clusters it is
+	//replicated to:(registeredResource, scope)
+	ResourceScopes map[string]*ReplicationScope
+	//protects the rules and the scopes, swapped when the configuration changes while the workers are reading them
+	configMutex sync.RWMutex
 	//each time a resource is removed from the configuration it is saved in this list,
 	//it stays here until the associated watcher, if running, is stopped
 	UnregisteredResources []string
@@ -62,6 +72,21 @@ type CRDReplicatorReconciler struct {
 	LocalWatchers map[string]map[string]chan struct{}
 	//for each peering cluster we save all the running watchers monitoring the replicated resources:(clusterID, (registeredResource, chan))
 	RemoteWatchers map[string]map[string]chan struct{}
+	//for each peering cluster the state of the connection to its API server:(clusterID, connection)
+	RemoteConnections map[string]*RemoteConnection
+	//backoff of the reconnections to the peering clusters
+	Backoff *flowcontrol.Backoff
+	//number of workers processing the events of each peering cluster
+	Workers int
+	//for each peering cluster the queue of the resources to be replicated:(clusterID, queue)
+	queues      map[string]*replicationQueue
+	queuesMutex sync.Mutex
+	//protects the remote clients, used by the workers while the connections are set up and torn down
+	remoteMutex sync.RWMutex
+	//for each peering cluster the labels of its ForeignCluster:(clusterID, labels)
+	peerLabels map[string]map[string]string
+	//for each ForeignCluster the ID of its peering cluster, to remove it when the ForeignCluster is deleted:(name, clusterID)
+	peerIDs map[string]string
 }
 
 func (d *CRDReplicatorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -76,37 +101,32 @@ func (d *CRDReplicatorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 	}
 	if apierrors.IsNotFound(err) {
 		klog.Errorf("%s -> resource %s not present, probably deleted: %s", d.ClusterID, req.NamespacedName, err)
+		if remoteClusterID, ok := d.peerIDs[req.Name]; ok {
+			delete(d.peerIDs, req.Name)
+			d.removePeer(remoteClusterID)
+		}
 		return ctrl.Result{}, nil
 	}
 	remoteClusterID := fc.Spec.ClusterIdentity.ClusterID
-	//check if the client already exists
-	//check if the dynamic dynamic client and informer factory exists
-	_, dynClientOk := d.RemoteDynClients[remoteClusterID]
-	_, dynFacOk := d.RemoteDynSharedInformerFactory[remoteClusterID]
-	if dynClientOk && dynFacOk {
+	d.setPeerLabels(remoteClusterID, fc.Labels)
+	//the connections not created by the reconciler, e.g. in the tests, are not managed
+	if _, ok := d.RemoteConnections[remoteClusterID]; !ok && d.isConnected(remoteClusterID) {
 		return result, nil
 	}
+	if d.peerIDs == nil {
+		d.peerIDs = make(map[string]string)
+	}
+	d.peerIDs[fc.Name] = remoteClusterID
 	//check if the config of the peering cluster is ready
-	//first we check the outgoing connection
-	if fc.Status.Outgoing.AvailableIdentity {
-		//retrieve the config
-		config, err := d.getKubeConfig(d.ClientSet, fc.Status.Outgoing.IdentityRef, remoteClusterID)
-		if err != nil {
-			klog.Errorf("%s -> unable to retrieve config from resource %s for remote peering cluster %s: %s", d.ClusterID, req.NamespacedName, remoteClusterID, err)
-			return result, nil
-		}
-		return result, d.setUpConnectionToPeeringCluster(config, remoteClusterID)
-
-	} else if fc.Status.Incoming.AvailableIdentity {
-		//retrieve the config
-		config, err := d.getKubeConfig(d.ClientSet, fc.Status.Incoming.IdentityRef, remoteClusterID)
-		if err != nil {
-			klog.Errorf("%s -> unable to retrieve config from resource %s for remote peering cluster %s: %s", d.ClusterID, req.NamespacedName, remoteClusterID, err)
-			return result, err
+	ref := getIdentityRef(&fc)
+	if ref == nil {
+		//the peering has been torn down
+		if _, ok := d.RemoteConnections[remoteClusterID]; ok {
+			d.removePeer(remoteClusterID)
 		}
-		return result, d.setUpConnectionToPeeringCluster(config, remoteClusterID)
+		return result, nil
 	}
-	return result, nil
+	return d.reconcileConnection(&fc, ref), nil
 }
 
 func (d *CRDReplicatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -115,32 +135,28 @@ func (d *CRDReplicatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(d)
 }
 
-func (d *CRDReplicatorReconciler) getKubeConfig(clientset kubernetes.Interface, reference *corev1.ObjectReference, remoteClusterID string) (*rest.Config, error) {
-	if reference == nil {
-		return nil, fmt.Errorf("%s -> object reference for the secret containing kubeconfig of foreign cluster %s not set yet", d.ClusterID, remoteClusterID)
-	}
-	secret, err := clientset.CoreV1().Secrets(reference.Namespace).Get(context.TODO(), reference.Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
+func (d *CRDReplicatorReconciler) getKubeConfig(clientset kubernetes.Interface, secret *corev1.Secret, remoteClusterID string) (*rest.Config, error) {
 	kubeconfig := func() (*clientcmdapi.Config, error) {
 		return clientcmd.Load(secret.Data["kubeconfig"])
 	}
 	cnf, err := clientcmd.BuildConfigFromKubeconfigGetter("", kubeconfig)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s -> unable to load the kubeconfig of foreign cluster %s: %w", d.ClusterID, remoteClusterID, err)
 	}
+	// the credentials in the secret are rotated by the foreign cluster
+	crdClient.ReloadTokenFromSecret(cnf, clientset, secret.Namespace, secret.Name)
 	return cnf, nil
 }
 
 func (d *CRDReplicatorReconciler) setUpConnectionToPeeringCluster(config *rest.Config, remoteClusterID string) error {
+	d.remoteMutex.Lock()
+	defer d.remoteMutex.Unlock()
 	//check if the dynamic dynamic client exists
 	if _, ok := d.RemoteDynClients[remoteClusterID]; !ok {
 		dynClient, err := dynamic.NewForConfig(config)
 		if err != nil {
 			klog.Errorf("%s -> unable to create dynamic client in order to create the dynamic shared informer factory: %s", remoteClusterID, err)
-			//we don't need to immediately requeue the foreign cluster but wait for the next re-sync
-			return nil
+			return err
 		} else {
 			klog.Infof("%s -> dynamic client created", remoteClusterID)
 		}
@@ -201,21 +217,20 @@ func (d *CRDReplicatorReconciler) remoteModifiedWrapper(oldObj, newObj interface
 		klog.Errorf("an error occurred while converting advertisement newObj to unstructured object")
 		return
 	}
-	gvr := d.getGVR(objUnstruct)
 	remoteClusterID := objUnstruct.GetLabels()[DestinationLabel]
-	d.RemoteResourceModifiedHandler(objUnstruct, gvr, remoteClusterID)
+	d.enqueue(remoteClusterID, objUnstruct, true)
 }
 
-func (d *CRDReplicatorReconciler) RemoteResourceModifiedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource, remoteClusterId string) {
+func (d *CRDReplicatorReconciler) RemoteResourceModifiedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource, remoteClusterId string) error {
 	name := obj.GetName()
-	namespace := obj.GetNamespace()
+	namespace := d.localNamespace(gvr, obj.GetNamespace())
 	localDynClient := d.LocalDynClient
 	clusterID := d.ClusterID
 	//we check if the resource exists in the local cluster
 	localObj, found, err := d.GetResource(localDynClient, gvr, name, namespace, clusterID)
 	if err != nil {
 		klog.Errorf("%s -> an error occurred while getting resource %s of type %s: %s", clusterID, name, gvr.String(), err)
-		return
+		return err
 	}
 	// TODO if the resource does not exist what do we do?
 	//do nothing? remove the remote replication?
@@ -223,36 +238,22 @@ func (d *CRDReplicatorReconciler) RemoteResourceModifiedHandler(obj *unstructure
 	if !found {
 		klog.Infof("%s -> resource %s in namespace %s of type %s not found", clusterID, name, namespace, gvr.String())
 		klog.Infof("%s -> removing resource %s in namespace %s of type %s", remoteClusterId, name, namespace, gvr.String())
-		err := d.DeleteResource(d.RemoteDynClients[remoteClusterId], gvr, obj, remoteClusterId)
-		if err != nil {
-			return
+		dynClient, ok := d.getRemoteDynClient(remoteClusterId)
+		if !ok {
+			return nil
 		}
-		return
+		return d.DeleteResource(dynClient, gvr, obj, remoteClusterId)
 	}
-	//if the resource exists on the local cluster then we update the status
-
-	//we reflect on the local resource only the changes of the status of the remote one
-	//we do not reflect the changes to labels, annotations or spec
+	//if the resource exists on the local cluster then we update the fields owned by the remote cluster,
+	//by default its status
+	//we do not reflect the changes to labels and annotations
 	//TODO:support labels and annotations
-
-	//get status field for both resources
-	remoteStatus, err := getStatus(obj, clusterID)
-	if err != nil {
-		return
-	}
-	if remoteStatus == nil {
-		return
-	}
-	localStatus, err := getStatus(localObj, remoteClusterId)
-	if err != nil {
-		return
-	}
-	//check if are the same and if not update the local one
-	if !reflect.DeepEqual(localStatus, remoteStatus) {
-		klog.Infof("%s -> updating status field of resource %s of type %s", clusterID, name, gvr.String())
-		_ = d.UpdateStatus(localDynClient, gvr, localObj, clusterID, remoteStatus)
-		return
+	rules := d.GetRules(gvr)
+	desired := localObj.DeepCopy()
+	if !copyFields(obj.Object, desired.Object, rules.pull, rules.pullExcluded) {
+		return nil
 	}
+	return d.updateFields(localDynClient, gvr, localObj, desired, clusterID)
 }
 
 func (d *CRDReplicatorReconciler) StartWatchers() {
@@ -333,16 +334,17 @@ func (d *CRDReplicatorReconciler) StopWatchers() {
 func (d *CRDReplicatorReconciler) CreateResource(client dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, clusterID string) error {
 	//check if the resource exists
 	name := obj.GetName()
-	namespace := obj.GetNamespace()
+	namespace := d.remoteNamespace(gvr, obj.GetNamespace())
 	klog.Infof("%s -> creating resource %s of type %s", clusterID, name, gvr.String())
 	r, found, err := d.GetResource(client, gvr, name, namespace, clusterID)
 	if err != nil {
 		klog.Errorf("%s -> an error occurred while getting resource %s of type %s: %s", clusterID, name, gvr.String(), err)
 		return err
 	}
+	rules := d.GetRules(gvr)
 	if found {
 		//the resource already exists check if the resources are the same
-		if areEqual(obj, r) {
+		if areEqual(obj, r, rules) {
 			klog.Infof("%s -> resource %s of type %s already exists", clusterID, obj.GetName(), gvr.String())
 			return nil
 		} else {
@@ -355,11 +357,6 @@ func (d *CRDReplicatorReconciler) CreateResource(client dynamic.Interface, gvr s
 		}
 	}
 	//if we come here it means that we have to create the resource on the remote cluster
-	spec, b, err := unstructured.NestedMap(obj.Object, "spec")
-	if !b || err != nil {
-		klog.Errorf("%s -> an error occurred while processing the 'spec' of the resource %s: %s ", d.ClusterID, name, err)
-		return err
-	}
 	remRes := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": obj.GetAPIVersion(),
@@ -367,11 +364,12 @@ func (d *CRDReplicatorReconciler) CreateResource(client dynamic.Interface, gvr s
 			"metadata": map[string]interface{}{
 				"name":      name,
 				"namespace": namespace,
-				"labels":    d.UpdateLabels(obj.GetLabels()),
 			},
-			"spec": spec,
 		},
 	}
+	remRes.SetLabels(d.UpdateLabels(obj.GetLabels()))
+	//the replica is created with the replicated fields of the spec, the ones of the status are set by the following update
+	copyFields(obj.Object, remRes.Object, pathsOf(rules.push, "spec"), rules.pushExcluded)
 	//create the resource on the remote cluster
 	_, err = client.Resource(gvr).Namespace(namespace).Create(context.TODO(), remRes, metav1.CreateOptions{})
 	if err != nil {
@@ -393,36 +391,6 @@ func (d *CRDReplicatorReconciler) UpdateLabels(labels map[string]string) map[str
 	return labels
 }
 
-//checks if the spec and status of two resources are the same
-func areEqual(local, remote *unstructured.Unstructured) bool {
-	localSpec, b, err := unstructured.NestedMap(local.Object, "spec")
-	if !b || err != nil {
-		return false
-	}
-	remoteSpec, b, err := unstructured.NestedMap(remote.Object, "spec")
-	if !b || err != nil {
-		return false
-	}
-	localStatus, b1, err := unstructured.NestedMap(local.Object, "status")
-	if err != nil {
-		return false
-	}
-	remoteStatus, b2, err := unstructured.NestedMap(remote.Object, "status")
-	if err != nil {
-		return false
-	}
-	if b1 != b2 {
-		return false
-	}
-
-	specEqual := reflect.DeepEqual(localSpec, remoteSpec)
-	statusEqual := reflect.DeepEqual(localStatus, remoteStatus)
-	if !specEqual || !statusEqual {
-		return false
-	}
-	return true
-}
-
 func getSpec(obj *unstructured.Unstructured, clusterID string) (map[string]interface{}, error) {
 	spec, b, err := unstructured.NestedMap(obj.Object, "spec")
 	if !b {
@@ -465,26 +433,22 @@ func (d *CRDReplicatorReconciler) AddFunc(newObj interface{}) {
 		klog.Errorf("an error occurred while converting advertisement newObj to unstructured object")
 		return
 	}
-	gvr := d.getGVR(objUnstruct)
-	d.AddedHandler(objUnstruct, gvr)
+	d.enqueueLocal(objUnstruct)
 }
 
-func (d *CRDReplicatorReconciler) AddedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) {
+func (d *CRDReplicatorReconciler) AddedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) error {
 	//check if already exists a cluster to the remote peering cluster specified in the labels
 	labels := obj.GetLabels()
 	remoteClusterID, ok := labels[DestinationLabel]
 	if !ok {
 		klog.Infof("%s -> resource %s %s of type %s has not a destination label with the ID of the peering cluster", d.ClusterID, obj.GetName(), obj.GetNamespace(), gvr.String())
-		return
+		return nil
 	}
-	if dynClient, ok := d.RemoteDynClients[remoteClusterID]; !ok {
+	if dynClient, ok := d.getRemoteDynClient(remoteClusterID); !ok {
 		klog.Infof("%s -> a connection to the peering cluster with id: %s does not exist", d.ClusterID, remoteClusterID)
-		return
+		return nil
 	} else {
-		err := d.CreateResource(dynClient, gvr, obj, remoteClusterID)
-		if err != nil {
-			klog.Error(err)
-		}
+		return d.CreateResource(dynClient, gvr, obj, remoteClusterID)
 	}
 }
 
@@ -494,94 +458,117 @@ func (d *CRDReplicatorReconciler) UpdateFunc(oldObj, newObj interface{}) {
 		klog.Errorf("an error occurred while converting advertisement newObj to unstructured object")
 		return
 	}
-	gvr := d.getGVR(objUnstruct)
-	d.ModifiedHandler(objUnstruct, gvr)
+	d.enqueueLocal(objUnstruct)
 }
 
-func (d *CRDReplicatorReconciler) ModifiedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) {
+func (d *CRDReplicatorReconciler) ModifiedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) error {
 	//check if already exists a cluster to the remote peering cluster specified in the labels
 	labels := obj.GetLabels()
 	remoteClusterID, ok := labels[DestinationLabel]
 	if !ok {
 		klog.Infof("%s -> resource %s %s of type %s has not a destination label with the ID of the peering cluster", d.ClusterID, obj.GetName(), obj.GetNamespace(), gvr.String())
-		return
+		return nil
 	}
 
-	if dynClient, ok := d.RemoteDynClients[remoteClusterID]; !ok {
+	if dynClient, ok := d.getRemoteDynClient(remoteClusterID); !ok {
 		klog.Infof("%s -> a connection to the peering cluster with id: %s does not exist", d.ClusterID, remoteClusterID)
-		return
+		return nil
 	} else {
 		name := obj.GetName()
-		namespace := obj.GetNamespace()
+		namespace := d.remoteNamespace(gvr, obj.GetNamespace())
 		clusterID := remoteClusterID
 		//we check if the resource exists in the remote cluster
 		_, found, err := d.GetResource(dynClient, gvr, name, namespace, clusterID)
 		if err != nil {
 			klog.Errorf("%s -> an error occurred while getting resource %s of type %s: %s", clusterID, name, gvr.String(), err)
-			return
+			return err
 		}
 		//if the resource does not exist then we create it
 		if !found {
-			err := d.CreateResource(dynClient, gvr, obj, clusterID)
-			if err != nil {
-				klog.Error(err)
+			if err := d.CreateResource(dynClient, gvr, obj, clusterID); err != nil {
+				return err
 			}
 		}
 		//if the resource exists or we just created it then we update the fields
 		//we do this considering that the resource existed, even if we just created it
 		if err = d.UpdateResource(dynClient, gvr, obj, clusterID); err != nil {
 			klog.Errorf("%s -> an error occurred while updating resource %s of type %s: %s", clusterID, name, gvr.String(), err)
-			return
+			return err
 		}
+		return nil
 	}
 }
 
 func (d *CRDReplicatorReconciler) DeleteFunc(newObj interface{}) {
+	//the object could be a tombstone, if the deletion has been missed by the watcher
+	if tombstone, ok := newObj.(cache.DeletedFinalStateUnknown); ok {
+		newObj = tombstone.Obj
+	}
 	objUnstruct, ok := newObj.(*unstructured.Unstructured)
 	if !ok {
 		klog.Errorf("an error occurred while converting advertisement newObj to unstructured object")
 		return
 	}
-	gvr := d.getGVR(objUnstruct)
-	d.DeletedHandler(objUnstruct, gvr)
+	d.enqueueLocal(objUnstruct)
 }
 
-func (d *CRDReplicatorReconciler) DeletedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) {
+func (d *CRDReplicatorReconciler) DeletedHandler(obj *unstructured.Unstructured, gvr schema.GroupVersionResource) error {
 	//check if already exists a cluster to the remote peering cluster specified in the labels
 	labels := obj.GetLabels()
 	remoteClusterID, ok := labels[DestinationLabel]
 	if !ok {
 		klog.Infof("%s -> resource %s %s of type %s has not a destination label with the ID of the peering cluster", d.ClusterID, obj.GetName(), obj.GetNamespace(), gvr.String())
-		return
+		return nil
 	}
 
-	if dynClient, ok := d.RemoteDynClients[remoteClusterID]; !ok {
+	if dynClient, ok := d.getRemoteDynClient(remoteClusterID); !ok {
 		klog.Infof("%s -> a connection to the peering cluster with id: %s does not exist", d.ClusterID, remoteClusterID)
-		return
+		return nil
 	} else {
 		name := obj.GetName()
-		namespace := obj.GetNamespace()
-		dynClient := dynClient
+		namespace := d.remoteNamespace(gvr, obj.GetNamespace())
 		clusterID := remoteClusterID
 		//we check if the resource exists in the remote cluster
-		_, found, err := d.GetResource(dynClient, gvr, name, namespace, clusterID)
+		r, found, err := d.GetResource(dynClient, gvr, name, namespace, clusterID)
 		if err != nil {
 			klog.Errorf("%s -> an error occurred while getting resource %s of type %s: %s", clusterID, name, gvr.String(), err)
-			return
+			return err
 		}
 		//if the resource exists on the remote cluster then we delete it
 		if found {
-			err := d.DeleteResource(dynClient, gvr, obj, clusterID)
-			if err != nil {
-				klog.Error(err)
-			}
+			return d.DeleteResource(dynClient, gvr, r, clusterID)
 		}
+		return nil
 	}
 }
 
+//adds a local resource to the queue of the peering cluster it is replicated to
+func (d *CRDReplicatorReconciler) enqueueLocal(obj *unstructured.Unstructured) {
+	remoteClusterID, ok := obj.GetLabels()[DestinationLabel]
+	if !ok {
+		klog.Infof("%s -> resource %s %s of type %s has not a destination label with the ID of the peering cluster", d.ClusterID, obj.GetName(), obj.GetNamespace(), d.getGVR(obj).String())
+		return
+	}
+	d.enqueue(remoteClusterID, obj, false)
+}
+
+func (d *CRDReplicatorReconciler) getRemoteDynClient(remoteClusterID string) (dynamic.Interface, bool) {
+	d.remoteMutex.RLock()
+	defer d.remoteMutex.RUnlock()
+	dynClient, ok := d.RemoteDynClients[remoteClusterID]
+	return dynClient, ok
+}
+
+func (d *CRDReplicatorReconciler) getRemoteDynSharedInformerFactory(remoteClusterID string) (dynamicinformer.DynamicSharedInformerFactory, bool) {
+	d.remoteMutex.RLock()
+	defer d.remoteMutex.RUnlock()
+	f, ok := d.RemoteDynSharedInformerFactory[remoteClusterID]
+	return f, ok
+}
+
 func (d *CRDReplicatorReconciler) UpdateResource(client dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, clusterID string) error {
 	name := obj.GetName()
-	namespace := obj.GetNamespace()
+	namespace := d.remoteNamespace(gvr, obj.GetNamespace())
 	// Retrieve the latest version of resource before attempting update
 	r, found, err := d.GetResource(client, gvr, name, namespace, clusterID)
 	if err != nil {
@@ -593,39 +580,45 @@ func (d *CRDReplicatorReconciler) UpdateResource(client dynamic.Interface, gvr s
 		klog.Errorf("%s -> an error occurred while getting resource %s of type %s: %s", clusterID, name, gvr.String(), err)
 		return fmt.Errorf("something strange happened, check if the resource %s of type %s on cluster %s exists on the remote cluster", name, gvr.String(), clusterID)
 	}
-	//get spec fields for both resources
-	localSpec, err := getSpec(obj, d.ClusterID)
+	//copy the fields owned by the local cluster and check if the remote resource has to be updated
+	rules := d.GetRules(gvr)
+	desired := r.DeepCopy()
+	if !copyFields(obj.Object, desired.Object, rules.push, rules.pushExcluded) {
+		return nil
+	}
+	return d.updateFields(client, gvr, r, desired, clusterID)
+}
+
+//updates the spec and the status of a resource if they differ from the desired ones
+//the status is updated on its own, since it can be a subresource
+func (d *CRDReplicatorReconciler) updateFields(client dynamic.Interface, gvr schema.GroupVersionResource, obj, desired *unstructured.Unstructured, clusterID string) error {
+	name := obj.GetName()
+	currentSpec, err := getSpec(obj, clusterID)
 	if err != nil {
 		return err
 	}
-	remoteSpec, err := getSpec(r, clusterID)
+	desiredSpec, err := getSpec(desired, clusterID)
 	if err != nil {
 		return err
 	}
-	//check if are the same and if not update the remote one
-	if !reflect.DeepEqual(localSpec, remoteSpec) {
+	if desiredSpec != nil && !reflect.DeepEqual(currentSpec, desiredSpec) {
 		klog.Infof("%s -> updating spec field of resource %s of type %s", clusterID, name, gvr.String())
-		err := d.UpdateSpec(client, gvr, r, clusterID, localSpec)
+		err := d.UpdateSpec(client, gvr, obj, clusterID, desiredSpec)
 		if err != nil {
 			return err
 		}
 	}
-	//get status field for both resources
-	localStatus, err := getStatus(obj, d.ClusterID)
+	currentStatus, err := getStatus(obj, clusterID)
 	if err != nil {
 		return err
 	}
-	if localStatus == nil {
-		return nil
-	}
-	remoteStatus, err := getStatus(r, clusterID)
+	desiredStatus, err := getStatus(desired, clusterID)
 	if err != nil {
 		return err
 	}
-	//check if are the same and if not update the remote one
-	if !reflect.DeepEqual(localStatus, remoteStatus) {
-		klog.Infof("%s -> updating status field resource %s of type %s", clusterID, name, gvr.String())
-		err := d.UpdateStatus(client, gvr, r, clusterID, localStatus)
+	if desiredStatus != nil && !reflect.DeepEqual(currentStatus, desiredStatus) {
+		klog.Infof("%s -> updating status field of resource %s of type %s", clusterID, name, gvr.String())
+		err := d.UpdateStatus(client, gvr, obj, clusterID, desiredStatus)
 		if err != nil {
 			return err
 		}
@@ -635,7 +628,7 @@ func (d *CRDReplicatorReconciler) UpdateResource(client dynamic.Interface, gvr s
 
 func (d *CRDReplicatorReconciler) DeleteResource(client dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured, clusterID string) error {
 	klog.Infof("%s -> deleting resource %s of type %s", clusterID, obj.GetName(), gvr.String())
-	err := client.Resource(gvr).Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{})
+	err := client.Resource(gvr).Namespace(obj.GetNamespace()).Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{})
 	if err != nil {
 		klog.Errorf("%s -> an error occurred while deleting resource %s of type %s: %s", clusterID, obj.GetName(), gvr.String(), err)
 		return err
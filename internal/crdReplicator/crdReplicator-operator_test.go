@@ -199,7 +199,7 @@ func TestCRDReplicatorReconciler_AddedHandler(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	obj, err := dynClient.Resource(gvr).Get(context.TODO(), test1.GetName(), metav1.GetOptions{})
 	assert.Nil(t, err, "error should be empty")
-	assert.True(t, areEqual(test1, obj), "the two objects should be equal")
+	assert.True(t, areEqual(test1, obj, defaultRules), "the two objects should be equal")
 	//remove the resource
 	err = dynClient.Resource(gvr).Delete(context.TODO(), test1.GetName(), metav1.DeleteOptions{})
 	assert.Nil(t, err, "should be nil")
@@ -223,7 +223,7 @@ func TestCRDReplicatorReconciler_ModifiedHandler(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	obj, err := dynClient.Resource(gvr).Get(context.TODO(), test1.GetName(), metav1.GetOptions{})
 	assert.Nil(t, err, "error should be empty")
-	assert.True(t, areEqual(test1, obj), "the two objects should be equal")
+	assert.True(t, areEqual(test1, obj, defaultRules), "the two objects should be equal")
 
 	//test 2
 	//the modified resource already exists on the cluster
@@ -245,7 +245,7 @@ func TestCRDReplicatorReconciler_ModifiedHandler(t *testing.T) {
 	time.Sleep(10 * time.Second)
 	newObj, err := dynClient.Resource(gvr).Get(context.TODO(), test1.GetName(), metav1.GetOptions{})
 	assert.Nil(t, err, "error should be empty")
-	assert.True(t, areEqual(newObj, obj), "the two objects should be equal")
+	assert.True(t, areEqual(newObj, obj, defaultRules), "the two objects should be equal")
 	//clean up the resource
 	err = dynClient.Resource(gvr).Delete(context.TODO(), test1.GetName(), metav1.DeleteOptions{})
 	assert.Nil(t, err, "should be nil")
@@ -291,7 +291,7 @@ func TestCRDReplicatorReconciler_DeletedHandler(t *testing.T) {
 	test1 := getObj()
 	obj, err := dynClient.Resource(gvr).Create(context.TODO(), test1, metav1.CreateOptions{})
 	assert.Nil(t, err, "error should be nil")
-	assert.True(t, areEqual(test1, obj), "the two objects should be equal")
+	assert.True(t, areEqual(test1, obj, defaultRules), "the two objects should be equal")
 	d.DeletedHandler(obj, gvr)
 	obj, err = dynClient.Resource(gvr).Get(context.TODO(), test1.GetName(), metav1.GetOptions{})
 	assert.NotNil(t, err, "error should not be empty")
@@ -0,0 +1,212 @@
+package crdReplicator
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sync"
+	"time"
+)
+
+var (
+	//number of workers processing the queue of each peering cluster, when not set in the reconciler
+	DefaultWorkers = 2
+	//number of times the replication of a resource is retried before dropping it,
+	//it will be replicated again at the next resync
+	MaxRetries = 10
+
+	//the depth of the queues and their retries are exported by the workqueue metrics, named after the queue
+	replicationLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "crdreplicator_replication_duration_seconds",
+		Help:    "Time elapsed from the first event on a resource to the end of its replication",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"peer", "direction"})
+	replicationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "crdreplicator_replication_failures_total",
+		Help: "Number of resources whose replication has been dropped after the maximum number of retries",
+	}, []string{"peer", "direction"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(replicationLatency, replicationFailures)
+}
+
+//a resource to be replicated to or from a peering cluster, the latest version is retrieved when it is processed
+type replicationItem struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+	//true if the item refers to the replica on the peering cluster, whose fields have to be copied back
+	remote bool
+}
+
+func (i replicationItem) direction() string {
+	if i.remote {
+		return "remoteToLocal"
+	}
+	return "localToRemote"
+}
+
+func (i replicationItem) key() string {
+	if i.namespace == "" {
+		return i.name
+	}
+	return i.namespace + "/" + i.name
+}
+
+//the queue of the events of a peering cluster and the time each pending item has been enqueued at
+type replicationQueue struct {
+	workqueue.RateLimitingInterface
+	enqueued map[replicationItem]time.Time
+	//the workers processing the queue, they return when it is shut down
+	workers sync.WaitGroup
+}
+
+//returns the queue of the peering cluster, its workers are started when it is created
+func (d *CRDReplicatorReconciler) getQueue(remoteClusterID string) *replicationQueue {
+	d.queuesMutex.Lock()
+	defer d.queuesMutex.Unlock()
+	if d.queues == nil {
+		d.queues = make(map[string]*replicationQueue)
+	}
+	if q, ok := d.queues[remoteClusterID]; ok {
+		return q
+	}
+	q := &replicationQueue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "crdReplicator-"+remoteClusterID),
+		enqueued:              make(map[replicationItem]time.Time),
+	}
+	d.queues[remoteClusterID] = q
+	workers := d.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	q.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.runWorker(remoteClusterID, q)
+	}
+	klog.Infof("%s -> started %d workers", remoteClusterID, workers)
+	return q
+}
+
+//shuts down the queue of the peering cluster and waits for its workers to return, the items still in the queue are
+//processed before, a new queue is created at the next event for the peering cluster
+func (d *CRDReplicatorReconciler) removeQueue(remoteClusterID string) {
+	d.queuesMutex.Lock()
+	q, ok := d.queues[remoteClusterID]
+	delete(d.queues, remoteClusterID)
+	d.queuesMutex.Unlock()
+	if !ok {
+		return
+	}
+	q.ShutDown()
+	q.workers.Wait()
+	klog.Infof("%s -> stopped the workers", remoteClusterID)
+}
+
+//adds the resource to the queue of the peering cluster
+func (d *CRDReplicatorReconciler) enqueue(remoteClusterID string, obj *unstructured.Unstructured, remote bool) {
+	item := replicationItem{
+		gvr:       d.getGVR(obj),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+		remote:    remote,
+	}
+	q := d.getQueue(remoteClusterID)
+	d.queuesMutex.Lock()
+	if _, ok := q.enqueued[item]; !ok {
+		q.enqueued[item] = time.Now()
+	}
+	d.queuesMutex.Unlock()
+	q.Add(item)
+}
+
+func (d *CRDReplicatorReconciler) runWorker(remoteClusterID string, q *replicationQueue) {
+	defer q.workers.Done()
+	for d.processNextItem(remoteClusterID, q, d.syncItem) {
+	}
+}
+
+//processes an item of the queue, the failed ones are retried with a rate limited backoff
+func (d *CRDReplicatorReconciler) processNextItem(remoteClusterID string, q *replicationQueue, sync func(string, replicationItem) error) bool {
+	obj, shutdown := q.Get()
+	if shutdown {
+		return false
+	}
+	defer q.Done(obj)
+	item, ok := obj.(replicationItem)
+	if !ok {
+		q.Forget(obj)
+		klog.Warningf("%s -> expected replication item in the queue but got %#v", remoteClusterID, obj)
+		return true
+	}
+	//the events received while the item is processed are timed from now on
+	d.queuesMutex.Lock()
+	enqueued, timed := q.enqueued[item]
+	delete(q.enqueued, item)
+	d.queuesMutex.Unlock()
+	if err := sync(remoteClusterID, item); err != nil {
+		if q.NumRequeues(item) < MaxRetries {
+			klog.Warningf("%s -> requeuing resource %s of type %s due to failed replication: %s", remoteClusterID, item.key(), item.gvr.String(), err)
+			if timed {
+				d.queuesMutex.Lock()
+				q.enqueued[item] = enqueued
+				d.queuesMutex.Unlock()
+			}
+			q.AddRateLimited(item)
+			return true
+		}
+		klog.Errorf("%s -> dropping resource %s of type %s after %d retries: %s", remoteClusterID, item.key(), item.gvr.String(), MaxRetries, err)
+		replicationFailures.WithLabelValues(remoteClusterID, item.direction()).Inc()
+	} else if timed {
+		replicationLatency.WithLabelValues(remoteClusterID, item.direction()).Observe(time.Since(enqueued).Seconds())
+	}
+	q.Forget(item)
+	return true
+}
+
+//replicates the latest version of the resource: a local one is created or updated on the peering cluster, or its
+//replica is deleted if it does not exist anymore, the fields of a remote one are copied back to the local resource
+func (d *CRDReplicatorReconciler) syncItem(remoteClusterID string, item replicationItem) error {
+	if item.remote {
+		remDynFac, ok := d.getRemoteDynSharedInformerFactory(remoteClusterID)
+		if !ok {
+			//the remote watchers are stopped
+			return nil
+		}
+		obj, found, err := getFromCache(remDynFac.ForResource(item.gvr).Informer().GetIndexer().GetByKey(item.key()))
+		if err != nil || !found {
+			return err
+		}
+		return d.RemoteResourceModifiedHandler(obj, item.gvr, remoteClusterID)
+	}
+	obj, found, err := getFromCache(d.LocalDynSharedInformerFactory.ForResource(item.gvr).Informer().GetIndexer().GetByKey(item.key()))
+	if err != nil {
+		return err
+	}
+	//the replica of a resource which is not replicated anymore to the peering cluster is deleted
+	if !found || !d.isReplicated(obj, item.gvr, remoteClusterID) {
+		deleted := &unstructured.Unstructured{}
+		deleted.SetName(item.name)
+		deleted.SetNamespace(item.namespace)
+		deleted.SetLabels(map[string]string{DestinationLabel: remoteClusterID})
+		return d.DeletedHandler(deleted, item.gvr)
+	}
+	return d.ModifiedHandler(obj, item.gvr)
+}
+
+func getFromCache(obj interface{}, found bool, err error) (*unstructured.Unstructured, bool, error) {
+	if err != nil || !found {
+		return nil, false, err
+	}
+	objUnstruct, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false, fmt.Errorf("an error occurred while converting the cached object to unstructured object")
+	}
+	//the objects in the cache must not be modified
+	return objUnstruct.DeepCopy(), true, nil
+}
@@ -0,0 +1,93 @@
+package crdReplicator
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/workqueue"
+	"testing"
+	"time"
+)
+
+func createFakeQueue() *replicationQueue {
+	return &replicationQueue{
+		RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond)),
+		enqueued:              make(map[replicationItem]time.Time),
+	}
+}
+
+func getQueueTestResource(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "net.liqo.io/v1alpha1",
+			"kind":       "NetworkConfig",
+			"metadata": map[string]interface{}{
+				"name": name,
+				"labels": map[string]interface{}{
+					DestinationLabel: remoteClusterID,
+				},
+			},
+		},
+	}
+}
+
+//the events on the same resource are coalesced in a single item of the queue of the peering cluster
+func TestCRDReplicatorReconciler_Enqueue(t *testing.T) {
+	d := &CRDReplicatorReconciler{}
+	q := createFakeQueue()
+	d.queues = map[string]*replicationQueue{remoteClusterID: q}
+
+	d.enqueueLocal(getQueueTestResource("test1"))
+	d.enqueueLocal(getQueueTestResource("test1"))
+	d.enqueue(remoteClusterID, getQueueTestResource("test1"), true)
+	obj := getQueueTestResource("test2")
+	obj.SetLabels(nil)
+	d.enqueueLocal(obj)
+	assert.Equal(t, 2, q.Len(), "the local and the remote events should be queued once")
+
+	item, _ := q.Get()
+	assert.Equal(t, replicationItem{gvr: gvr, name: "test1"}, item)
+	assert.Equal(t, 2, len(q.enqueued))
+	q.ShutDown()
+}
+
+//a failed replication is retried until it succeeds or the maximum number of retries is reached
+func TestCRDReplicatorReconciler_ProcessNextItem(t *testing.T) {
+	d := &CRDReplicatorReconciler{}
+	q := createFakeQueue()
+	defer q.ShutDown()
+	d.queues = map[string]*replicationQueue{remoteClusterID: q}
+	attempts := 0
+	sync := func(clusterID string, item replicationItem) error {
+		assert.Equal(t, remoteClusterID, clusterID)
+		attempts++
+		if attempts < 3 {
+			return errors.New("replication failed")
+		}
+		return nil
+	}
+
+	replicationLatency.Reset()
+	replicationFailures.Reset()
+	d.enqueueLocal(getQueueTestResource("test1"))
+	for i := 0; i < 3; i++ {
+		assert.True(t, d.processNextItem(remoteClusterID, q, sync))
+	}
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, 0, q.NumRequeues(replicationItem{gvr: gvr, name: "test1"}), "the item should be forgotten")
+	assert.Equal(t, 0, len(q.enqueued))
+	assert.Equal(t, 1, testutil.CollectAndCount(replicationLatency), "the latency should be observed")
+
+	//the replication always fails
+	sync = func(clusterID string, item replicationItem) error {
+		return errors.New("replication failed")
+	}
+	d.enqueue(remoteClusterID, getQueueTestResource("test1"), true)
+	for i := 0; i <= MaxRetries; i++ {
+		assert.True(t, d.processNextItem(remoteClusterID, q, sync))
+	}
+	assert.Equal(t, 0, q.Len(), "the item should be dropped")
+	assert.Equal(t, float64(1), testutil.ToFloat64(replicationFailures.WithLabelValues(remoteClusterID, "remoteToLocal")))
+}
@@ -0,0 +1,156 @@
+package crdReplicator
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"reflect"
+	"strings"
+)
+
+//the rules of the resources registered without rules: the spec and the status are copied to the remote replica
+//and its status is copied back, the last change wins
+var defaultRules = newReplicationRules(&configv1alpha1.ReplicationRules{
+	LocalToRemote: []string{"spec", "status"},
+	RemoteToLocal: []string{"status"},
+})
+
+//a field path, e.g. [status podCIDR]
+type fieldPath []string
+
+//ReplicationRules contains the field paths replicated in each direction, derived from the configured rules
+type ReplicationRules struct {
+	//fields copied from the local resource to the remote replica
+	push []fieldPath
+	//fields of the remote replica not overwritten by the push
+	pushExcluded []fieldPath
+	//fields copied from the remote replica to the local resource
+	pull []fieldPath
+	//fields of the local resource not overwritten by the pull
+	pullExcluded []fieldPath
+}
+
+func newReplicationRules(rules *configv1alpha1.ReplicationRules) *ReplicationRules {
+	localToRemote := parsePaths(rules.LocalToRemote)
+	remoteToLocal := parsePaths(rules.RemoteToLocal)
+	ignored := parsePaths(rules.Ignored)
+	res := &ReplicationRules{
+		push:         localToRemote,
+		pushExcluded: ignored,
+		pull:         remoteToLocal,
+		pullExcluded: ignored,
+	}
+	//the fields replicated in both directions are not overwritten by the side that does not prevail
+	switch rules.ConflictResolution {
+	case configv1alpha1.LocalWins:
+		res.pullExcluded = append(res.pullExcluded, localToRemote...)
+	case configv1alpha1.RemoteWins:
+		res.pushExcluded = append(res.pushExcluded, remoteToLocal...)
+	}
+	return res
+}
+
+func parsePaths(paths []string) []fieldPath {
+	res := []fieldPath{}
+	for _, path := range paths {
+		p := fieldPath(strings.Split(path, "."))
+		if p[0] != "spec" && p[0] != "status" {
+			klog.Warningf("replication rule for field %s ignored, only the fields of the spec and of the status are replicated", path)
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+//returns true if p is equal to or contains other
+func (p fieldPath) contains(other fieldPath) bool {
+	if len(p) > len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//GetRules returns the replication rules of the resource, the default ones if it has been registered without rules
+func (d *CRDReplicatorReconciler) GetRules(gvr schema.GroupVersionResource) *ReplicationRules {
+	d.configMutex.RLock()
+	defer d.configMutex.RUnlock()
+	if rules, ok := d.ResourceRules[gvr.String()]; ok {
+		return rules
+	}
+	return defaultRules
+}
+
+//copies the fields at the given paths from src to dst, leaving untouched the fields of dst at the excluded paths
+//the fields missing in src are left untouched in dst, it returns true if dst has been modified
+func copyFields(src, dst map[string]interface{}, paths []fieldPath, excluded []fieldPath) bool {
+	modified := false
+	for _, path := range paths {
+		if isExcluded(path, excluded) {
+			continue
+		}
+		value, found, err := unstructured.NestedFieldCopy(src, path...)
+		if err != nil || !found {
+			continue
+		}
+		current, currentFound, err := unstructured.NestedFieldCopy(dst, path...)
+		if err != nil {
+			continue
+		}
+		//restore the excluded fields nested in the copied one
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			currentMap, _ := current.(map[string]interface{})
+			for _, ex := range excluded {
+				if len(ex) <= len(path) || !path.contains(ex) {
+					continue
+				}
+				rel := ex[len(path):]
+				unstructured.RemoveNestedField(valueMap, rel...)
+				if old, found, err := unstructured.NestedFieldCopy(currentMap, rel...); err == nil && found {
+					_ = unstructured.SetNestedField(valueMap, old, rel...)
+				}
+			}
+		}
+		if currentFound && reflect.DeepEqual(value, current) {
+			continue
+		}
+		if err = unstructured.SetNestedField(dst, value, path...); err != nil {
+			klog.Errorf("an error occurred while setting field %s: %s", strings.Join(path, "."), err)
+			continue
+		}
+		modified = true
+	}
+	return modified
+}
+
+func isExcluded(path fieldPath, excluded []fieldPath) bool {
+	for _, ex := range excluded {
+		if ex.contains(path) {
+			return true
+		}
+	}
+	return false
+}
+
+//returns the paths rooted at the given field, e.g. the ones of the spec
+func pathsOf(paths []fieldPath, root string) []fieldPath {
+	res := []fieldPath{}
+	for _, p := range paths {
+		if p[0] == root {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+//checks if the fields replicated from the local resource to the remote one are the same
+func areEqual(local, remote *unstructured.Unstructured, rules *ReplicationRules) bool {
+	desired := remote.DeepCopy()
+	return !copyFields(local.Object, desired.Object, rules.push, rules.pushExcluded)
+}
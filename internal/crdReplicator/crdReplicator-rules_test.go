@@ -0,0 +1,116 @@
+package crdReplicator
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func getRulesObj(spec, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "net.liqo.io/v1alpha1",
+			"kind":       "NetworkConfig",
+			"metadata": map[string]interface{}{
+				"name": "test-networkconfig",
+			},
+		},
+	}
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+//the "spec mine, status theirs" rules of the NetworkConfigs
+func TestReplicationRules_SpecMineStatusTheirs(t *testing.T) {
+	rules := newReplicationRules(&configv1alpha1.ReplicationRules{
+		LocalToRemote: []string{"spec"},
+		RemoteToLocal: []string{"status"},
+	})
+	local := getRulesObj(map[string]interface{}{"podCIDR": "10.0.0.0/16"}, map[string]interface{}{"podCIDRNAT": "local"})
+	remote := getRulesObj(map[string]interface{}{"podCIDR": "10.1.0.0/16"}, map[string]interface{}{"podCIDRNAT": "10.2.0.0/16"})
+
+	//only the spec is pushed to the remote replica
+	desired := remote.DeepCopy()
+	assert.True(t, copyFields(local.Object, desired.Object, rules.push, rules.pushExcluded), "the spec should be copied")
+	spec, _ := getSpec(desired, remoteClusterID)
+	status, _ := getStatus(desired, remoteClusterID)
+	assert.Equal(t, map[string]interface{}{"podCIDR": "10.0.0.0/16"}, spec)
+	assert.Equal(t, map[string]interface{}{"podCIDRNAT": "10.2.0.0/16"}, status, "the remote status should not be overwritten")
+
+	//only the status is pulled back to the local resource
+	desired = local.DeepCopy()
+	assert.True(t, copyFields(remote.Object, desired.Object, rules.pull, rules.pullExcluded), "the status should be copied")
+	spec, _ = getSpec(desired, localClusterID)
+	status, _ = getStatus(desired, localClusterID)
+	assert.Equal(t, map[string]interface{}{"podCIDR": "10.0.0.0/16"}, spec, "the local spec should not be overwritten")
+	assert.Equal(t, map[string]interface{}{"podCIDRNAT": "10.2.0.0/16"}, status)
+
+	//a replica with a different status is equal to the local resource, since its status is not replicated
+	remote.Object["spec"] = map[string]interface{}{"podCIDR": "10.0.0.0/16"}
+	assert.True(t, areEqual(local, remote, rules), "the two objects should be equal")
+}
+
+//the ignored fields are never copied, even if they are nested in a replicated field
+func TestReplicationRules_Ignored(t *testing.T) {
+	rules := newReplicationRules(&configv1alpha1.ReplicationRules{
+		LocalToRemote: []string{"spec"},
+		Ignored:       []string{"spec.tunnelPublicIP"},
+	})
+	local := getRulesObj(map[string]interface{}{"podCIDR": "10.0.0.0/16", "tunnelPublicIP": "1.1.1.1"}, nil)
+	remote := getRulesObj(map[string]interface{}{"podCIDR": "10.0.0.0/16", "tunnelPublicIP": "2.2.2.2"}, nil)
+	assert.True(t, areEqual(local, remote, rules), "the ignored fields should not be compared")
+
+	local.Object["spec"] = map[string]interface{}{"podCIDR": "10.1.0.0/16", "tunnelPublicIP": "1.1.1.1"}
+	desired := remote.DeepCopy()
+	assert.True(t, copyFields(local.Object, desired.Object, rules.push, rules.pushExcluded))
+	spec, _ := getSpec(desired, remoteClusterID)
+	assert.Equal(t, map[string]interface{}{"podCIDR": "10.1.0.0/16", "tunnelPublicIP": "2.2.2.2"}, spec)
+}
+
+//the fields replicated in both directions are resolved by the conflict resolution policy
+func TestReplicationRules_ConflictResolution(t *testing.T) {
+	local := getRulesObj(nil, map[string]interface{}{"phase": "local"})
+	remote := getRulesObj(nil, map[string]interface{}{"phase": "remote"})
+	tests := []struct {
+		policy     configv1alpha1.ConflictResolution
+		pushed     bool
+		pulled     bool
+		remoteWins bool
+	}{
+		{configv1alpha1.LastWriterWins, true, true, false},
+		{configv1alpha1.LocalWins, true, false, false},
+		{configv1alpha1.RemoteWins, false, true, true},
+	}
+	for _, test := range tests {
+		rules := newReplicationRules(&configv1alpha1.ReplicationRules{
+			LocalToRemote:      []string{"status"},
+			RemoteToLocal:      []string{"status"},
+			ConflictResolution: test.policy,
+		})
+		assert.Equal(t, test.pushed, copyFields(local.Object, remote.DeepCopy().Object, rules.push, rules.pushExcluded), string(test.policy))
+		assert.Equal(t, test.pulled, copyFields(remote.Object, local.DeepCopy().Object, rules.pull, rules.pullExcluded), string(test.policy))
+		assert.Equal(t, test.remoteWins, areEqual(local, remote, rules), string(test.policy))
+	}
+}
+
+//the default rules copy the spec and the status to the remote replica and the status back, the fields of the
+//metadata cannot be replicated
+func TestReplicationRules_Default(t *testing.T) {
+	local := getRulesObj(map[string]interface{}{"podCIDR": "10.0.0.0/16"}, nil)
+	remote := getRulesObj(map[string]interface{}{"podCIDR": "10.0.0.0/16"}, map[string]interface{}{"podCIDRNAT": "10.2.0.0/16"})
+	assert.True(t, areEqual(local, remote, defaultRules), "the local resource without status should be equal to its replica")
+
+	local.Object["status"] = map[string]interface{}{"podCIDRNAT": "None"}
+	assert.False(t, areEqual(local, remote, defaultRules), "the local status should be replicated")
+
+	rules := newReplicationRules(&configv1alpha1.ReplicationRules{
+		LocalToRemote: []string{"metadata.labels", "spec"},
+	})
+	assert.Equal(t, 1, len(rules.push), "only the fields of the spec and of the status should be replicated")
+}
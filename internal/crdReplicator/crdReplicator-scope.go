@@ -0,0 +1,114 @@
+package crdReplicator
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+//ReplicationScope contains the namespaces a resource is replicated to and which resources are replicated to which
+//peering clusters, derived from the configured resource
+type ReplicationScope struct {
+	//namespaces of the remote replicas of the local resources:(localNamespace, remoteNamespace)
+	natting map[string]string
+	//namespaces of the local resources of the remote replicas:(remoteNamespace, localNamespace)
+	deNatting map[string]string
+	//selector of the replicated resources
+	selector labels.Selector
+	//selector of the ForeignClusters of the peering clusters the resources are replicated to
+	peerSelector labels.Selector
+}
+
+//the scope of the resources registered without namespace mapping and selectors
+var defaultScope = &ReplicationScope{
+	natting:      map[string]string{},
+	deNatting:    map[string]string{},
+	selector:     labels.Everything(),
+	peerSelector: labels.Everything(),
+}
+
+func newReplicationScope(res *configv1alpha1.Resource) *ReplicationScope {
+	scope := &ReplicationScope{
+		natting:      map[string]string{},
+		deNatting:    map[string]string{},
+		selector:     parseSelector(res.Selector, res.Resource),
+		peerSelector: parseSelector(res.PeerSelector, res.Resource),
+	}
+	for local, remote := range res.NamespaceMapping {
+		if other, ok := scope.deNatting[remote]; ok {
+			klog.Warningf("namespace mapping %s -> %s of resource %s ignored, namespace %s is already mapped to %s", local, remote, res.Resource, other, remote)
+			continue
+		}
+		scope.natting[local] = remote
+		scope.deNatting[remote] = local
+	}
+	return scope
+}
+
+//an invalid selector selects nothing, so that a wrong configuration does not replicate unwanted resources
+func parseSelector(selector *metav1.LabelSelector, resource string) labels.Selector {
+	if selector == nil {
+		return labels.Everything()
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("invalid selector for resource %s, no resource will be replicated: %s", resource, err)
+		return labels.Nothing()
+	}
+	return s
+}
+
+//GetScope returns the replication scope of the resource, the default one if it has been registered without
+//namespace mapping and selectors
+func (d *CRDReplicatorReconciler) GetScope(gvr schema.GroupVersionResource) *ReplicationScope {
+	d.configMutex.RLock()
+	defer d.configMutex.RUnlock()
+	if scope, ok := d.ResourceScopes[gvr.String()]; ok {
+		return scope
+	}
+	return defaultScope
+}
+
+//returns the namespace of the remote replica of a local resource
+func (d *CRDReplicatorReconciler) remoteNamespace(gvr schema.GroupVersionResource, namespace string) string {
+	if remote, ok := d.GetScope(gvr).natting[namespace]; ok {
+		return remote
+	}
+	return namespace
+}
+
+//returns the namespace of the local resource of a remote replica
+func (d *CRDReplicatorReconciler) localNamespace(gvr schema.GroupVersionResource, namespace string) string {
+	if local, ok := d.GetScope(gvr).deNatting[namespace]; ok {
+		return local
+	}
+	return namespace
+}
+
+//checks if the local resource has to be replicated to the peering cluster
+func (d *CRDReplicatorReconciler) isReplicated(obj *unstructured.Unstructured, gvr schema.GroupVersionResource, remoteClusterID string) bool {
+	scope := d.GetScope(gvr)
+	if !scope.selector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	return scope.peerSelector.Matches(labels.Set(d.getPeerLabels(remoteClusterID)))
+}
+
+func (d *CRDReplicatorReconciler) getPeerLabels(remoteClusterID string) map[string]string {
+	d.remoteMutex.RLock()
+	defer d.remoteMutex.RUnlock()
+	return d.peerLabels[remoteClusterID]
+}
+
+//saves the labels of the ForeignCluster of the peering cluster, matched by the peer selectors
+func (d *CRDReplicatorReconciler) setPeerLabels(remoteClusterID string, peerLabels map[string]string) {
+	d.remoteMutex.Lock()
+	defer d.remoteMutex.Unlock()
+	if d.peerLabels == nil {
+		d.peerLabels = make(map[string]map[string]string)
+	}
+	d.peerLabels[remoteClusterID] = peerLabels
+}
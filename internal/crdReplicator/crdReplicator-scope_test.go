@@ -0,0 +1,150 @@
+package crdReplicator
+
+import (
+	"context"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+func getScopeTestConfig() *configv1alpha1.ClusterConfig {
+	return &configv1alpha1.ClusterConfig{
+		Spec: configv1alpha1.ClusterConfigSpec{
+			DispatcherConfig: configv1alpha1.DispatcherConfig{
+				ResourcesToReplicate: []configv1alpha1.Resource{
+					{
+						Group:    gvr.Group,
+						Version:  gvr.Version,
+						Resource: gvr.Resource,
+						NamespaceMapping: map[string]string{
+							"tenant-a": "remote-tenant-a",
+						},
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"tier": "gold"},
+						},
+						PeerSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "discovery-type", Operator: metav1.LabelSelectorOpIn, Values: []string{"LAN", "Manual"}},
+							},
+						},
+					},
+					{
+						Group:    "discovery.liqo.io",
+						Version:  "v1alpha1",
+						Resource: "foreignclusters",
+					},
+				},
+			},
+		},
+	}
+}
+
+func getScopeTestResource(namespace string, tier string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "net.liqo.io/v1alpha1",
+			"kind":       "NetworkConfig",
+			"metadata": map[string]interface{}{
+				"name":      "test-scope",
+				"namespace": namespace,
+				"labels": map[string]interface{}{
+					LocalLabelSelector: "true",
+					DestinationLabel:   remoteClusterID,
+					"tier":             tier,
+				},
+			},
+			"spec": map[string]interface{}{
+				"podCIDR": "10.0.0.0/16",
+			},
+		},
+	}
+}
+
+func TestCRDReplicatorReconciler_GetScopesConfig(t *testing.T) {
+	d := &CRDReplicatorReconciler{}
+	d.ResourceScopes = d.GetScopesConfig(getScopeTestConfig())
+	assert.Equal(t, 1, len(d.ResourceScopes), "only the resources with a scope should be in the map")
+
+	assert.Equal(t, "remote-tenant-a", d.remoteNamespace(gvr, "tenant-a"))
+	assert.Equal(t, "tenant-b", d.remoteNamespace(gvr, "tenant-b"), "the namespaces not in the mapping should not be mapped")
+	assert.Equal(t, "tenant-a", d.localNamespace(gvr, "remote-tenant-a"))
+	assert.Equal(t, "tenant-a", d.localNamespace(gvr, "tenant-a"))
+	fcGVR := gvr
+	fcGVR.Resource = "foreignclusters"
+	assert.Equal(t, defaultScope, d.GetScope(fcGVR))
+
+	d.setPeerLabels(remoteClusterID, map[string]string{"discovery-type": "LAN"})
+	assert.True(t, d.isReplicated(getScopeTestResource("tenant-a", "gold"), gvr, remoteClusterID))
+	assert.False(t, d.isReplicated(getScopeTestResource("tenant-a", "silver"), gvr, remoteClusterID), "the resource should not match the selector")
+	d.setPeerLabels(remoteClusterID, map[string]string{"discovery-type": "WAN"})
+	assert.False(t, d.isReplicated(getScopeTestResource("tenant-a", "gold"), gvr, remoteClusterID), "the peer should not match the selector")
+	assert.True(t, d.isReplicated(getScopeTestResource("tenant-a", "silver"), fcGVR, remoteClusterID), "the default scope should replicate everything")
+}
+
+func TestCRDReplicatorReconciler_NewReplicationScope(t *testing.T) {
+	//two namespaces cannot be mapped to the same remote one
+	scope := newReplicationScope(&configv1alpha1.Resource{
+		Resource: gvr.Resource,
+		NamespaceMapping: map[string]string{
+			"tenant-a": "remote",
+			"tenant-b": "remote",
+		},
+	})
+	assert.Equal(t, 1, len(scope.natting))
+	assert.Equal(t, 1, len(scope.deNatting))
+	local := scope.deNatting["remote"]
+	assert.Equal(t, "remote", scope.natting[local])
+
+	//an invalid selector selects nothing
+	scope = newReplicationScope(&configv1alpha1.Resource{
+		Resource: gvr.Resource,
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			},
+		},
+	})
+	assert.False(t, scope.selector.Empty())
+	assert.False(t, scope.selector.Matches(nil))
+	assert.True(t, scope.peerSelector.Empty())
+}
+
+//the resources are replicated in the mapped namespace, and their replicas are deleted when they do not match the
+//selector anymore
+func TestCRDReplicatorReconciler_SyncItemScope(t *testing.T) {
+	local := getScopeTestResource("tenant-a", "gold")
+	localDynClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), local.DeepCopy())
+	remoteDynClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	localDynFac := dynamicinformer.NewDynamicSharedInformerFactory(localDynClient, 0)
+	indexer := localDynFac.ForResource(gvr).Informer().GetIndexer()
+	assert.Nil(t, indexer.Add(local.DeepCopy()))
+	d := &CRDReplicatorReconciler{
+		ClusterID:                      localClusterID,
+		LocalDynClient:                 localDynClient,
+		LocalDynSharedInformerFactory:  localDynFac,
+		RemoteDynClients:               map[string]dynamic.Interface{remoteClusterID: remoteDynClient},
+		RemoteDynSharedInformerFactory: map[string]dynamicinformer.DynamicSharedInformerFactory{},
+	}
+	d.ResourceScopes = d.GetScopesConfig(getScopeTestConfig())
+	d.setPeerLabels(remoteClusterID, map[string]string{"discovery-type": "Manual"})
+	item := replicationItem{gvr: gvr, namespace: "tenant-a", name: "test-scope"}
+
+	assert.Nil(t, d.syncItem(remoteClusterID, item))
+	replica, err := remoteDynClient.Resource(gvr).Namespace("remote-tenant-a").Get(context.TODO(), "test-scope", metav1.GetOptions{})
+	assert.Nil(t, err, "the replica should be created in the mapped namespace")
+	assert.Equal(t, local.Object["spec"], replica.Object["spec"])
+	assert.Equal(t, localClusterID, replica.GetLabels()[RemoteLabelSelector])
+
+	//the local resource does not match the selector anymore
+	assert.Nil(t, indexer.Update(getScopeTestResource("tenant-a", "silver")))
+	assert.Nil(t, d.syncItem(remoteClusterID, item))
+	_, err = remoteDynClient.Resource(gvr).Namespace("remote-tenant-a").Get(context.TODO(), "test-scope", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the replica should be deleted")
+}
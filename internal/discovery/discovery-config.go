@@ -9,14 +9,19 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
+	"reflect"
 )
 
-func (discovery *DiscoveryCtrl) GetDiscoveryConfig(crdClient *crdClient.CRDClient, kubeconfigPath string) error {
+// GetDiscoveryConfig waits for the ClusterConfig and keeps watching it until the context is done
+func (discovery *DiscoveryCtrl) GetDiscoveryConfig(ctx context.Context, crdClient *crdClient.CRDClient, kubeconfigPath string) error {
 	waitFirst := make(chan bool)
 	isFirst := true
-	go clusterConfig.WatchConfiguration(func(configuration *configv1alpha1.ClusterConfig) {
+	go clusterConfig.WatchConfigurationContext(ctx, func(configuration *configv1alpha1.ClusterConfig) {
+		discovery.handleAdvertisementConfig(configuration.Spec.AdvertisementConfig)
 		discovery.handleConfiguration(configuration.Spec.DiscoveryConfig)
 		discovery.handleDispatcherConfig(configuration.Spec.DispatcherConfig)
+		// read when a kubeconfig is created for a foreign cluster
+		discovery.ApiServerConfig = configuration.Spec.ApiServerConfig.DeepCopy()
 		if isFirst {
 			waitFirst <- true
 			isFirst = false
@@ -68,9 +73,35 @@ func (discovery *DiscoveryCtrl) handleDispatcherConfig(config configv1alpha1.Dis
 	}
 }
 
+// the clusters joining the local one receive its Advertisement only if the broadcaster is enabled:
+// announce if incoming peerings are accepted
+func (discovery *DiscoveryCtrl) handleAdvertisementConfig(config configv1alpha1.AdvertisementConfig) {
+	acceptsPeering := config.OutgoingConfig.EnableBroadcaster
+	if discovery.acceptsPeering == acceptsPeering {
+		return
+	}
+	discovery.acceptsPeering = acceptsPeering
+	if discovery.Config == nil {
+		// first iteration, nothing has been published yet
+		return
+	}
+	// publish the updated capabilities
+	if discovery.Config.EnableAdvertisement {
+		discovery.reloadServer()
+	}
+	if discovery.Config.DnsPublication != nil {
+		discovery.reloadDnsPublisher()
+	}
+	if discovery.Config.RegistryPublication != nil {
+		discovery.reloadRegistryPublisher()
+	}
+}
+
 func (discovery *DiscoveryCtrl) handleConfiguration(config configv1alpha1.DiscoveryConfig) {
 	reloadServer := false
 	reloadClient := false
+	reloadPublisher := false
+	reloadRegistryPublisher := false
 	if discovery.Config == nil {
 		// first iteration
 		discovery.Config = &config
@@ -107,6 +138,11 @@ func (discovery *DiscoveryCtrl) handleConfiguration(config configv1alpha1.Discov
 			discovery.Config.Ttl = config.Ttl
 			reloadServer = true
 		}
+		if !reflect.DeepEqual(discovery.Config.Interfaces, config.Interfaces) {
+			discovery.Config.Interfaces = config.Interfaces
+			reloadServer = true
+			reloadClient = true
+		}
 		if discovery.Config.AutoJoin != config.AutoJoin {
 			discovery.Config.AutoJoin = config.AutoJoin
 			reloadClient = true
@@ -119,12 +155,43 @@ func (discovery *DiscoveryCtrl) handleConfiguration(config configv1alpha1.Discov
 			discovery.Config.EnableDiscovery = config.EnableDiscovery
 			reloadClient = true
 		}
+		if !reflect.DeepEqual(discovery.Config.AutoJoinRequirements, config.AutoJoinRequirements) {
+			// read by the ForeignCluster operator when a cluster is discovered
+			discovery.Config.AutoJoinRequirements = config.AutoJoinRequirements
+		}
+		if !reflect.DeepEqual(discovery.Config.Unpeering, config.Unpeering) {
+			// read by the ForeignCluster operator when an unpeering starts
+			discovery.Config.Unpeering = config.Unpeering
+		}
+		if !reflect.DeepEqual(discovery.Config.Dnssec, config.Dnssec) {
+			// read by the SearchDomain operator on every reconciliation
+			discovery.Config.Dnssec = config.Dnssec
+		}
+		if !reflect.DeepEqual(discovery.Config.DnsPublication, config.DnsPublication) {
+			discovery.Config.DnsPublication = config.DnsPublication
+			reloadPublisher = true
+		}
+		if !reflect.DeepEqual(discovery.Config.RegistryPublication, config.RegistryPublication) {
+			discovery.Config.RegistryPublication = config.RegistryPublication
+			reloadRegistryPublisher = true
+		}
+		if !reflect.DeepEqual(discovery.Config.GarbageCollection, config.GarbageCollection) {
+			// read by the garbage collector on every collection
+			discovery.Config.GarbageCollection = config.GarbageCollection
+		}
 		if reloadServer {
 			discovery.reloadServer()
 		}
 		if reloadClient {
 			discovery.reloadClient()
 		}
+		if reloadPublisher || (discovery.Config.DnsPublication != nil && reloadServer) {
+			// the published TXT record depends on the cluster name too
+			discovery.reloadDnsPublisher()
+		}
+		if reloadRegistryPublisher || (discovery.Config.RegistryPublication != nil && reloadServer) {
+			discovery.reloadRegistryPublisher()
+		}
 	}
 }
 
@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"github.com/grandcat/zeroconf"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
@@ -22,12 +23,23 @@ type DiscoveryCtrl struct {
 	advClient      *crdClient.CRDClient
 	ClusterId      *clusterID.ClusterID
 
-	mdnsServer                *zeroconf.Server
+	// announced in the discovery records
+	acceptsPeering bool
+	// used to create the kubeconfigs for the foreign clusters
+	ApiServerConfig *configv1alpha1.ApiServerConfig
+
+	dnsPublisher      *publisherRunner
+	registryPublisher *publisherRunner
+	// protects the publishers, reloaded by the configuration watcher while they may be stopped before exiting
+	publisherMux      sync.Mutex
+	publishersStopped bool
+
+	mdnsServers               []*zeroconf.Server
 	serverMux                 sync.Mutex
 	resolveContextRefreshTime int
 }
 
-func NewDiscoveryCtrl(namespace string, clusterId *clusterID.ClusterID, kubeconfigPath string, resolveContextRefreshTime int) (*DiscoveryCtrl, error) {
+func NewDiscoveryCtrl(ctx context.Context, namespace string, clusterId *clusterID.ClusterID, kubeconfigPath string, resolveContextRefreshTime int) (*DiscoveryCtrl, error) {
 	config, err := crdClient.NewKubeconfig(kubeconfigPath, &discoveryv1alpha1.GroupVersion)
 	if err != nil {
 		return nil, err
@@ -50,7 +62,7 @@ func NewDiscoveryCtrl(namespace string, clusterId *clusterID.ClusterID, kubeconf
 		clusterId,
 		resolveContextRefreshTime,
 	)
-	if discoveryCtrl.GetDiscoveryConfig(nil, kubeconfigPath) != nil {
+	if discoveryCtrl.GetDiscoveryConfig(ctx, nil, kubeconfigPath) != nil {
 		os.Exit(1)
 	}
 	return &discoveryCtrl, nil
@@ -74,4 +86,18 @@ func (discovery *DiscoveryCtrl) StartDiscovery() {
 	go discovery.StartResolver(discovery.stopMDNSClient)
 	go discovery.StartGratuitousAnswers()
 	go discovery.StartGarbageCollector()
+	discovery.publisherMux.Lock()
+	defer discovery.publisherMux.Unlock()
+	discovery.startDnsPublisher()
+	discovery.startRegistryPublisher()
+}
+
+// Stop the goroutines that have to clean up before exiting, i.e. withdraw the records published in DNS and in the registry
+// the publishers are not started again by the following configuration changes
+func (discovery *DiscoveryCtrl) StopDiscovery() {
+	discovery.publisherMux.Lock()
+	defer discovery.publisherMux.Unlock()
+	discovery.publishersStopped = true
+	discovery.stopDnsPublisher()
+	discovery.stopRegistryPublisher()
 }
@@ -0,0 +1,210 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/miekg/dns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DnsPublisher publishes the records of a cluster in a DNS zone through dynamic updates (RFC 2136),
+// with the same layout read by the WAN discovery:
+//
+//	<zone>              PTR <cluster-id>.<zone>
+//	<cluster-id>.<zone> SRV 0 0 <api-server-port> <api-server-host>
+//	<cluster-id>.<zone> TXT <TxtData>
+//
+// if the API server is exposed on an IP address, an A (or AAAA) record for <cluster-id>.<zone> is published too,
+// and used as SRV target
+type DnsPublisher struct {
+	client        *dns.Client
+	server        string
+	zone          string
+	ttl           uint32
+	tsigKeyName   string
+	tsigAlgorithm string
+
+	// the name of the last published records, to be withdrawn
+	published string
+}
+
+// NewDnsPublisher creates a publisher sending the updates to the server in config
+// tsigSecret is the base64 encoded secret of the TSIG key, used only if config contains a TSIG configuration
+func NewDnsPublisher(config *configv1alpha1.DnsPublicationConfig, tsigSecret string) (*DnsPublisher, error) {
+	if config == nil || config.Zone == "" || config.Server == "" {
+		return nil, errors.New("zone and server are required to publish the cluster in DNS")
+	}
+	server := config.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	p := &DnsPublisher{
+		client: new(dns.Client),
+		server: server,
+		zone:   strings.ToLower(dns.Fqdn(config.Zone)),
+		ttl:    config.Ttl,
+	}
+	p.client.DialTimeout = 30 * time.Second
+	if p.ttl == 0 {
+		p.ttl = 300
+	}
+	if config.Tsig != nil {
+		if config.Tsig.KeyName == "" || tsigSecret == "" {
+			return nil, errors.New("TSIG key name and secret are required")
+		}
+		algorithm := config.Tsig.Algorithm
+		if algorithm == "" {
+			algorithm = dns.HmacSHA256
+		}
+		p.tsigKeyName = strings.ToLower(dns.Fqdn(config.Tsig.KeyName))
+		p.tsigAlgorithm = dns.Fqdn(algorithm)
+		p.client.TsigSecret = map[string]string{p.tsigKeyName: tsigSecret}
+	}
+	return p, nil
+}
+
+// RefreshPeriod returns the period after that the records have to be published again
+func (p *DnsPublisher) RefreshPeriod() time.Duration {
+	return time.Duration(p.ttl) * time.Second / 2
+}
+
+// Publish adds (or replaces) the records of the cluster described by txtData to the zone
+func (p *DnsPublisher) Publish(txtData *TxtData) error {
+	instance := strings.ToLower(dns.Fqdn(txtData.ID + "." + p.zone))
+	apiUrl, err := url.Parse(txtData.ApiUrl)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(apiUrl.Port(), 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid API server port in %v: %w", txtData.ApiUrl, err)
+	}
+	txt, err := txtData.Encode()
+	if err != nil {
+		return err
+	}
+
+	header := func(name string, rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: p.ttl}
+	}
+	var records []dns.RR
+	target := dns.Fqdn(apiUrl.Hostname())
+	if ip := net.ParseIP(apiUrl.Hostname()); ip != nil {
+		// a SRV target has to be a domain name
+		target = instance
+		if ip4 := ip.To4(); ip4 != nil {
+			records = append(records, &dns.A{Hdr: header(instance, dns.TypeA), A: ip4})
+		} else {
+			records = append(records, &dns.AAAA{Hdr: header(instance, dns.TypeAAAA), AAAA: ip})
+		}
+	}
+	records = append(records,
+		&dns.PTR{Hdr: header(p.zone, dns.TypePTR), Ptr: instance},
+		&dns.SRV{Hdr: header(instance, dns.TypeSRV), Port: uint16(port), Target: target},
+		&dns.TXT{Hdr: header(instance, dns.TypeTXT), Txt: txt},
+	)
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(p.zone)
+	if p.published != "" && p.published != instance {
+		p.removeInstance(msg, p.published)
+	}
+	// replace the previous records of the cluster, the PTR records of the other clusters are kept
+	msg.RemoveName([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: instance}}})
+	msg.Insert(records)
+	if err = p.send(msg); err != nil {
+		return err
+	}
+	p.published = instance
+	return nil
+}
+
+// Withdraw removes the published records from the zone
+func (p *DnsPublisher) Withdraw() error {
+	if p.published == "" {
+		return nil
+	}
+	msg := new(dns.Msg)
+	msg.SetUpdate(p.zone)
+	p.removeInstance(msg, p.published)
+	if err := p.send(msg); err != nil {
+		return err
+	}
+	p.published = ""
+	return nil
+}
+
+func (p *DnsPublisher) removeInstance(msg *dns.Msg, instance string) {
+	msg.Remove([]dns.RR{&dns.PTR{Hdr: dns.RR_Header{Name: p.zone, Rrtype: dns.TypePTR, Class: dns.ClassINET}, Ptr: instance}})
+	msg.RemoveName([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: instance}}})
+}
+
+func (p *DnsPublisher) send(msg *dns.Msg) error {
+	if p.tsigKeyName != "" {
+		msg.SetTsig(p.tsigKeyName, p.tsigAlgorithm, 300, time.Now().Unix())
+	}
+	in, _, err := p.client.Exchange(msg, p.server)
+	if err != nil {
+		return err
+	}
+	if p.tsigKeyName != "" && in.IsTsig() == nil {
+		// the client only checks the signature of signed answers
+		return errors.New("the answer to the DNS update is not signed with TSIG")
+	}
+	if in.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("DNS update of zone %v refused: %v", p.zone, dns.RcodeToString[in.Rcode])
+	}
+	return nil
+}
+
+// GetTsigSecret returns the base64 encoded TSIG secret stored in the Secret referenced by the TSIG configuration
+func (discovery *DiscoveryCtrl) GetTsigSecret(config *configv1alpha1.TsigConfig) (string, error) {
+	if config == nil {
+		return "", nil
+	}
+	secret, err := discovery.crdClient.Client().CoreV1().Secrets(config.SecretRef.Namespace).Get(context.TODO(), config.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data["secret"]), nil
+}
+
+// start publishing the local cluster in DNS, if configured
+func (discovery *DiscoveryCtrl) startDnsPublisher() {
+	if discovery.Config == nil || discovery.Config.DnsPublication == nil {
+		return
+	}
+	config := discovery.Config.DnsPublication.DeepCopy()
+	discovery.dnsPublisher = discovery.startPublisher(func() (recordPublisher, error) {
+		tsigSecret, err := discovery.GetTsigSecret(config.Tsig)
+		if err != nil {
+			return nil, err
+		}
+		return NewDnsPublisher(config, tsigSecret)
+	})
+}
+
+// stop publishing the local cluster in DNS, waiting for its records to be withdrawn
+func (discovery *DiscoveryCtrl) stopDnsPublisher() {
+	discovery.dnsPublisher.Stop()
+	discovery.dnsPublisher = nil
+}
+
+func (discovery *DiscoveryCtrl) reloadDnsPublisher() {
+	discovery.publisherMux.Lock()
+	defer discovery.publisherMux.Unlock()
+	if discovery.publishersStopped {
+		return
+	}
+	klog.Info("Reload DNS publisher")
+	discovery.stopDnsPublisher()
+	discovery.startDnsPublisher()
+}
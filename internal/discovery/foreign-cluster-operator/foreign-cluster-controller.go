@@ -19,6 +19,8 @@ package foreign_cluster_operator
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	nettypes "github.com/liqotech/liqo/apis/net/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
@@ -62,6 +64,9 @@ type ForeignClusterReconciler struct {
 
 // +kubebuilder:rbac:groups=discovery.liqo.io,resources=foreignclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=discovery.liqo.io,resources=foreignclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;update
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=list
+// +kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
 
 func (r *ForeignClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
@@ -99,8 +104,9 @@ func (r *ForeignClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, err
 			fc.Status.TrustMode = discoveryv1alpha1.TrustModeUntrusted
 		}
 		// set join flag
-		// if it was discovery with WAN discovery, this value is overwritten by SearchDomain value
-		if fc.Spec.DiscoveryType != discoveryv1alpha1.WanDiscovery && fc.Spec.DiscoveryType != discoveryv1alpha1.IncomingPeeringDiscovery && fc.Spec.DiscoveryType != discoveryv1alpha1.ManualDiscovery {
+		// if it was discovery with WAN or registry discovery, this value is overwritten by SearchDomain value
+		if fc.Spec.DiscoveryType != discoveryv1alpha1.WanDiscovery && fc.Spec.DiscoveryType != discoveryv1alpha1.RegistryDiscovery &&
+			fc.Spec.DiscoveryType != discoveryv1alpha1.IncomingPeeringDiscovery && fc.Spec.DiscoveryType != discoveryv1alpha1.ManualDiscovery {
 			fc.Spec.Join = (r.getAutoJoin(fc) && fc.Status.TrustMode == discoveryv1alpha1.TrustModeTrusted) || (r.getAutoJoinUntrusted(fc) && fc.Status.TrustMode == discoveryv1alpha1.TrustModeUntrusted)
 		}
 
@@ -300,10 +306,47 @@ func (r *ForeignClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, err
 		requireUpdate = true
 	}
 
+	// if join is required again while the virtual nodes are being drained, stop the unpeering
+	if fc.Spec.Join && fc.DeletionTimestamp.IsZero() && fc.Status.Outgoing.Unpeering != nil &&
+		fc.Status.Outgoing.Unpeering.Phase != discoveryv1alpha1.UnpeeringTearingDown {
+		err = r.cancelUnpeering(fc)
+		if err != nil {
+			klog.Error(err)
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: r.RequeueAfter,
+			}, err
+		}
+		requireUpdate = true
+	}
+
 	// if join is no more required and status is set to joined
 	// or if this foreign cluster is being deleted
-	// delete peering request
+	// drain the virtual nodes and then delete peering request
 	if (!fc.Spec.Join || !fc.DeletionTimestamp.IsZero()) && fc.Status.Outgoing.Joined {
+		drained, err := r.drain(fc)
+		if err != nil {
+			klog.Error(err)
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: r.RequeueAfter,
+			}, err
+		}
+		if !drained {
+			// save the progress and check it again shortly
+			_, err = r.Update(fc)
+			if err != nil {
+				klog.Error(err)
+				return ctrl.Result{
+					Requeue:      true,
+					RequeueAfter: r.RequeueAfter,
+				}, err
+			}
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: drainCheckPeriod,
+			}, nil
+		}
 		fc, err = r.Unpeer(fc, foreignDiscoveryClient)
 		if err != nil {
 			return ctrl.Result{
@@ -314,6 +357,19 @@ func (r *ForeignClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, err
 		requireUpdate = true
 	}
 
+	// if the credentials sent to the foreign cluster are going to expire, replace them
+	if fc.Spec.Join && fc.Status.Outgoing.Joined && kubeconfig.NeedsRotation(fc.Status.Outgoing.CredentialsExpiration, r.getCredentialsTtl()) {
+		err = r.rotateCredentials(fc, foreignDiscoveryClient)
+		if err != nil {
+			klog.Error(err)
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: r.RequeueAfter,
+			}, err
+		}
+		requireUpdate = true
+	}
+
 	if !fc.Spec.Join && !fc.Status.Outgoing.Joined && slice.ContainsString(fc.Finalizers, FinalizerString, nil) {
 		fc.Finalizers = slice.RemoveString(fc.Finalizers, FinalizerString, nil)
 		requireUpdate = true
@@ -389,13 +445,14 @@ func (r *ForeignClusterReconciler) Update(fc *discoveryv1alpha1.ForeignCluster)
 func (r *ForeignClusterReconciler) Peer(fc *discoveryv1alpha1.ForeignCluster, foreignDiscoveryClient *crdClient.CRDClient) (*discoveryv1alpha1.ForeignCluster, error) {
 	// create PeeringRequest
 	klog.Info("Creating PeeringRequest")
-	pr, err := r.createPeeringRequestIfNotExists(fc.Name, fc, foreignDiscoveryClient)
+	pr, expiration, err := r.createPeeringRequestIfNotExists(fc.Name, fc, foreignDiscoveryClient)
 	if err != nil {
 		klog.Error(err)
 		return nil, err
 	}
 	fc.Status.Outgoing.Joined = true
 	fc.Status.Outgoing.RemotePeeringRequestName = pr.Name
+	fc.Status.Outgoing.CredentialsExpiration = expiration
 	// add finalizer
 	if !slice.ContainsString(fc.Finalizers, FinalizerString, nil) {
 		fc.Finalizers = append(fc.Finalizers, FinalizerString)
@@ -417,8 +474,16 @@ func (r *ForeignClusterReconciler) Unpeer(fc *discoveryv1alpha1.ForeignCluster,
 		klog.Error(err)
 		return nil, err
 	}
+	// the credentials sent to the foreign cluster have to be revoked
+	err = r.revokeCredentials(fc.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Error(err)
+		return nil, err
+	}
 	fc.Status.Outgoing.Joined = false
 	fc.Status.Outgoing.RemotePeeringRequestName = ""
+	fc.Status.Outgoing.CredentialsExpiration = nil
+	fc.Status.Outgoing.Unpeering = nil
 	if slice.ContainsString(fc.Finalizers, FinalizerString, nil) {
 		fc.Finalizers = slice.RemoveString(fc.Finalizers, FinalizerString, nil)
 	}
@@ -465,11 +530,11 @@ func (r *ForeignClusterReconciler) checkJoined(fc *discoveryv1alpha1.ForeignClus
 	return fc, nil
 }
 
-func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID string, owner *discoveryv1alpha1.ForeignCluster, foreignClient *crdClient.CRDClient) (*discoveryv1alpha1.PeeringRequest, error) {
+func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID string, owner *discoveryv1alpha1.ForeignCluster, foreignClient *crdClient.CRDClient) (*discoveryv1alpha1.PeeringRequest, *metav1.Time, error) {
 	// get config to send to foreign cluster
-	fConfig, err := r.getForeignConfig(clusterID, owner)
+	fConfig, expiration, err := r.getForeignConfig(clusterID, owner)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	localClusterID := r.clusterID.GetClusterID()
@@ -477,7 +542,7 @@ func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID str
 	// check if a peering request with our cluster id already exists on remote cluster
 	tmp, err := foreignClient.Resource("peeringrequests").Get(localClusterID, metav1.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
-		return nil, err
+		return nil, nil, err
 	}
 	pr, ok := tmp.(*discoveryv1alpha1.PeeringRequest)
 	inf := errors.IsNotFound(err) || !ok // inf -> IsNotFound
@@ -504,12 +569,12 @@ func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID str
 			}
 			tmp, err = foreignClient.Resource("peeringrequests").Create(pr, metav1.CreateOptions{})
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			var ok bool
 			pr, ok = tmp.(*discoveryv1alpha1.PeeringRequest)
 			if !ok {
-				return nil, goerrors.New("created object is not a ForeignCluster")
+				return nil, nil, goerrors.New("created object is not a ForeignCluster")
 			}
 		}
 		secret := &apiv1.Secret{
@@ -536,9 +601,9 @@ func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID str
 			err2 := foreignClient.Resource("peeringrequests").Delete(pr.Name, metav1.DeleteOptions{})
 			if err2 != nil {
 				klog.Error(err2)
-				return nil, err2
+				return nil, nil, err2
 			}
-			return nil, err
+			return nil, nil, err
 		}
 		pr.Spec.KubeConfigRef = &apiv1.ObjectReference{
 			Kind:       "Secret",
@@ -556,56 +621,57 @@ func (r *ForeignClusterReconciler) createPeeringRequestIfNotExists(clusterID str
 			err2 := foreignClient.Resource("peeringrequests").Delete(pr.Name, metav1.DeleteOptions{})
 			if err2 != nil {
 				klog.Error(err2)
-				return nil, err2
+				return nil, nil, err2
 			}
-			return nil, err
+			return nil, nil, err
 		}
 		pr, ok = tmp.(*discoveryv1alpha1.PeeringRequest)
 		if !ok {
-			return nil, goerrors.New("created object is not a PeeringRequest")
+			return nil, nil, goerrors.New("created object is not a PeeringRequest")
 		}
-		return pr, nil
+		return pr, expiration, nil
 	}
-	// already exists
-	return pr, nil
+	// already exists, the credentials it refers to are unknown
+	return pr, nil, nil
 }
 
 // this function return a kube-config file to send to foreign cluster and crate everything needed for it
-func (r *ForeignClusterReconciler) getForeignConfig(clusterID string, owner *discoveryv1alpha1.ForeignCluster) (string, error) {
+func (r *ForeignClusterReconciler) getForeignConfig(clusterID string, owner *discoveryv1alpha1.ForeignCluster) (string, *metav1.Time, error) {
 	_, err := r.createClusterRoleIfNotExists(clusterID, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	_, err = r.createRoleIfNotExists(clusterID, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	sa, err := r.createServiceAccountIfNotExists(clusterID, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	_, err = r.createClusterRoleBindingIfNotExists(clusterID, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	_, err = r.createRoleBindingIfNotExists(clusterID, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// crdReplicator role binding
-	err = r.setDispatcherRole(clusterID, sa)
+	err = r.setDispatcherRole(clusterID, sa, owner)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// check if ServiceAccount already has a secret, wait if not
-	if len(sa.Secrets) == 0 {
+	// it is not needed if the token is requested through the TokenRequest API
+	if len(sa.Secrets) == 0 && r.getCredentialsTtl() == 0 {
 		wa, err := r.crdClient.Client().CoreV1().ServiceAccounts(r.Namespace).Watch(context.TODO(), metav1.ListOptions{
 			FieldSelector: "metadata.name=" + clusterID,
 		})
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		timeout := time.NewTimer(500 * time.Millisecond)
 		ch := wa.ResultChan()
@@ -624,15 +690,15 @@ func (r *ForeignClusterReconciler) getForeignConfig(clusterID string, owner *dis
 				// try to use default config
 				if r.ForeignConfig != nil {
 					klog.Warning("using default ForeignConfig")
-					return r.ForeignConfig.String(), nil
+					return r.ForeignConfig.String(), nil, nil
 				}
 				// ServiceAccount not updated with secrets and no default config
-				return "", errors.NewTimeoutError("ServiceAccount's Secret was not created", 0)
+				return "", nil, errors.NewTimeoutError("ServiceAccount's Secret was not created", 0)
 			}
 		}
 	}
-	cnf, err := kubeconfig.CreateKubeConfig(r.crdClient.Client(), clusterID, r.Namespace)
-	return cnf, err
+	// the tokens are not bound to any object, they are revoked deleting the ServiceAccount
+	return kubeconfig.CreateKubeConfig(r.crdClient.Client(), r.getApiServerConfig(), clusterID, r.Namespace, nil)
 }
 
 func (r *ForeignClusterReconciler) createClusterRoleIfNotExists(clusterID string, owner *discoveryv1alpha1.ForeignCluster) (*rbacv1.ClusterRole, error) {
@@ -644,7 +710,7 @@ func (r *ForeignClusterReconciler) createClusterRoleIfNotExists(clusterID string
 				Name: clusterID,
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1alpha1",
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
 						Kind:       "ForeignCluster",
 						Name:       owner.Name,
 						UID:        owner.UID,
@@ -677,7 +743,7 @@ func (r *ForeignClusterReconciler) createRoleIfNotExists(clusterID string, owner
 				Name: clusterID,
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1alpha1",
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
 						Kind:       "ForeignCluster",
 						Name:       owner.Name,
 						UID:        owner.UID,
@@ -710,7 +776,7 @@ func (r *ForeignClusterReconciler) createServiceAccountIfNotExists(clusterID str
 				Name: clusterID,
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1alpha1",
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
 						Kind:       "ForeignCluster",
 						Name:       owner.Name,
 						UID:        owner.UID,
@@ -736,7 +802,7 @@ func (r *ForeignClusterReconciler) createClusterRoleBindingIfNotExists(clusterID
 				Name: clusterID,
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1alpha1",
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
 						Kind:       "ForeignCluster",
 						Name:       owner.Name,
 						UID:        owner.UID,
@@ -774,7 +840,7 @@ func (r *ForeignClusterReconciler) createRoleBindingIfNotExists(clusterID string
 				Name: clusterID,
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1alpha1",
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
 						Kind:       "ForeignCluster",
 						Name:       owner.Name,
 						UID:        owner.UID,
@@ -803,7 +869,8 @@ func (r *ForeignClusterReconciler) createRoleBindingIfNotExists(clusterID string
 	}
 }
 
-func (r *ForeignClusterReconciler) setDispatcherRole(clusterID string, sa *apiv1.ServiceAccount) error {
+// a cluster-scoped ClusterRoleBinding cannot be owned by the namespaced ServiceAccount, it is owned by the ForeignCluster
+func (r *ForeignClusterReconciler) setDispatcherRole(clusterID string, sa *apiv1.ServiceAccount, owner *discoveryv1alpha1.ForeignCluster) error {
 	_, err := r.crdClient.Client().RbacV1().ClusterRoleBindings().Get(context.TODO(), clusterID+"-crdReplicator", metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		// does not exist
@@ -812,10 +879,10 @@ func (r *ForeignClusterReconciler) setDispatcherRole(clusterID string, sa *apiv1
 				Name: clusterID + "-crdReplicator",
 				OwnerReferences: []metav1.OwnerReference{
 					{
-						APIVersion: "v1",
-						Kind:       "ServiceAccount",
-						Name:       sa.Name,
-						UID:        sa.UID,
+						APIVersion: discoveryv1alpha1.GroupVersion.String(),
+						Kind:       "ForeignCluster",
+						Name:       owner.Name,
+						UID:        owner.UID,
 					},
 				},
 			},
@@ -858,7 +925,68 @@ func (r *ForeignClusterReconciler) getAutoJoin(fc *discoveryv1alpha1.ForeignClus
 		klog.Warning("Discovery Config is not set, using default value")
 		return fc.Spec.Join
 	}
-	return r.DiscoveryCtrl.Config.AutoJoin
+	return r.DiscoveryCtrl.Config.AutoJoin && fc.Status.Capabilities.Satisfies(r.getAutoJoinRequirements())
+}
+
+// the configured requirements have the same fields of the SearchDomain ones
+func (r *ForeignClusterReconciler) getAutoJoinRequirements() *discoveryv1alpha1.CapabilityRequirements {
+	return (*discoveryv1alpha1.CapabilityRequirements)(r.DiscoveryCtrl.Config.AutoJoinRequirements)
+}
+
+func (r *ForeignClusterReconciler) getApiServerConfig() *configv1alpha1.ApiServerConfig {
+	if r.DiscoveryCtrl == nil {
+		return nil
+	}
+	return r.DiscoveryCtrl.ApiServerConfig
+}
+
+// get the lifetime of the credentials sent to the foreign clusters, 0 if they do not expire
+func (r *ForeignClusterReconciler) getCredentialsTtl() int64 {
+	apiServerConfig := r.getApiServerConfig()
+	if apiServerConfig == nil {
+		return 0
+	}
+	return apiServerConfig.CredentialsTtl
+}
+
+// create new credentials for the foreign cluster and replace the ones in the Secret referenced by our PeeringRequest
+func (r *ForeignClusterReconciler) rotateCredentials(fc *discoveryv1alpha1.ForeignCluster, foreignDiscoveryClient *crdClient.CRDClient) error {
+	tmp, err := foreignDiscoveryClient.Resource("peeringrequests").Get(fc.Status.Outgoing.RemotePeeringRequestName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	pr, ok := tmp.(*discoveryv1alpha1.PeeringRequest)
+	if !ok {
+		return goerrors.New("retrieved object is not a PeeringRequest")
+	}
+	if pr.Spec.KubeConfigRef == nil {
+		return fmt.Errorf("PeeringRequest %v has no kubeconfig Secret", pr.Name)
+	}
+
+	fConfig, expiration, err := r.getForeignConfig(fc.Name, fc)
+	if err != nil {
+		return err
+	}
+	secret, err := foreignDiscoveryClient.Client().CoreV1().Secrets(pr.Spec.KubeConfigRef.Namespace).Get(context.TODO(), pr.Spec.KubeConfigRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	secret.StringData = map[string]string{
+		"kubeconfig": fConfig,
+	}
+	_, err = foreignDiscoveryClient.Client().CoreV1().Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	fc.Status.Outgoing.CredentialsExpiration = expiration
+	klog.Infof("Credentials sent to cluster %v rotated, they expire at %v", fc.Name, expiration)
+	return nil
+}
+
+// revoke the credentials sent to the foreign cluster deleting the ServiceAccount they belong to, this invalidates both
+// its long-lived tokens and the ones issued through the TokenRequest API
+func (r *ForeignClusterReconciler) revokeCredentials(clusterID string) error {
+	return r.crdClient.Client().CoreV1().ServiceAccounts(r.Namespace).Delete(context.TODO(), clusterID, metav1.DeleteOptions{})
 }
 
 func (r *ForeignClusterReconciler) getAutoJoinUntrusted(fc *discoveryv1alpha1.ForeignCluster) bool {
@@ -866,7 +994,7 @@ func (r *ForeignClusterReconciler) getAutoJoinUntrusted(fc *discoveryv1alpha1.Fo
 		klog.Warning("Discovery Config is not set, using default value")
 		return fc.Spec.Join
 	}
-	return r.DiscoveryCtrl.Config.AutoJoinUntrusted
+	return r.DiscoveryCtrl.Config.AutoJoinUntrusted && fc.Status.Capabilities.Satisfies(r.getAutoJoinRequirements())
 }
 
 func (r *ForeignClusterReconciler) checkNetwork(fc *discoveryv1alpha1.ForeignCluster, requireUpdate *bool) error {
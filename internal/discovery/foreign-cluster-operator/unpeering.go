@@ -0,0 +1,190 @@
+package foreign_cluster_operator
+
+import (
+	"context"
+	"fmt"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+	"time"
+)
+
+// period of the checks of the pods still running on a virtual node being drained
+const drainCheckPeriod = 5 * time.Second
+
+// CordonNode sets the node as (un)schedulable
+func CordonNode(client kubernetes.Interface, nodeName string, unschedulable bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if node.Spec.Unschedulable == unschedulable {
+			return nil
+		}
+		node.Spec.Unschedulable = unschedulable
+		_, err = client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// EvictPods evicts the pods running on the node through the eviction API, which honors their PodDisruptionBudgets,
+// and returns the number of pods still running on it, including the ones whose eviction has been refused.
+// The DaemonSet pods are ignored, since they are not offloaded by the virtual kubelet
+func EvictPods(client kubernetes.Interface, nodeName string) (int, error) {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	remaining := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeName || isDaemonSetPod(pod) ||
+			pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			// already evicted, waiting for its termination
+			remaining++
+			continue
+		}
+		err = client.PolicyV1beta1().Evictions(pod.Namespace).Evict(context.TODO(), &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		})
+		if errors.IsNotFound(err) {
+			continue
+		} else if errors.IsTooManyRequests(err) {
+			// the eviction would violate a PodDisruptionBudget, it will be retried
+			klog.V(4).Infof("eviction of pod %v/%v refused: %v", pod.Namespace, pod.Name, err)
+		} else if err != nil {
+			return 0, err
+		}
+		remaining++
+	}
+	return remaining, nil
+}
+
+// VirtualNodes returns the names of the virtual nodes of the foreign cluster: the cluster may be mapped on several
+// virtual nodes, one per partition, which are labeled with its cluster id
+func VirtualNodes(client kubernetes.Interface, clusterID string) ([]string, error) {
+	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "type=virtual-node",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := range nodes.Items {
+		if virtualKubelet.IsVirtualNodeOf(&nodes.Items[i], clusterID) {
+			names = append(names, nodes.Items[i].Name)
+		}
+	}
+	return names, nil
+}
+
+func isDaemonSetPod(pod *apiv1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// drain the virtual nodes of the foreign cluster before unpeering from it: the nodes are cordoned, then their pods are evicted
+// until they are empty or the drain timeout expires. Each step updates the unpeering progress in the ForeignCluster status,
+// it returns true when the peering can be torn down
+func (r *ForeignClusterReconciler) drain(fc *discoveryv1alpha1.ForeignCluster) (bool, error) {
+	timeout := r.getDrainTimeout()
+	unpeering := fc.Status.Outgoing.Unpeering
+	if unpeering == nil {
+		if timeout == 0 {
+			return true, nil
+		}
+		klog.Infof("Unpeering from cluster %v, draining its virtual node", fc.Name)
+		fc.Status.Outgoing.Unpeering = &discoveryv1alpha1.Unpeering{
+			Phase:     discoveryv1alpha1.UnpeeringCordoning,
+			StartTime: metav1.Now(),
+		}
+		return false, nil
+	}
+
+	switch unpeering.Phase {
+	case discoveryv1alpha1.UnpeeringCordoning:
+		if err := r.cordonVirtualNodes(fc, true); err != nil {
+			return false, err
+		}
+		unpeering.Phase = discoveryv1alpha1.UnpeeringDraining
+		return false, nil
+	case discoveryv1alpha1.UnpeeringDraining:
+		nodes, err := VirtualNodes(r.crdClient.Client(), fc.Spec.ClusterIdentity.ClusterID)
+		if err != nil {
+			return false, err
+		}
+		remaining := 0
+		for _, nodeName := range nodes {
+			n, err := EvictPods(r.crdClient.Client(), nodeName)
+			if err != nil {
+				return false, err
+			}
+			remaining += n
+		}
+		unpeering.RemainingPods = int32(remaining)
+		if remaining == 0 {
+			unpeering.Phase = discoveryv1alpha1.UnpeeringTearingDown
+			unpeering.Message = ""
+		} else if time.Since(unpeering.StartTime.Time) > timeout {
+			unpeering.Phase = discoveryv1alpha1.UnpeeringTearingDown
+			unpeering.Message = fmt.Sprintf("drain timeout expired, %v pods have not been evicted", remaining)
+			klog.Warningf("Unpeering from cluster %v: %v", fc.Name, unpeering.Message)
+		} else {
+			unpeering.Message = fmt.Sprintf("waiting for the eviction of %v pods", remaining)
+		}
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// stop an unpeering in progress, e.g. because the join has been required again, making the virtual nodes schedulable
+func (r *ForeignClusterReconciler) cancelUnpeering(fc *discoveryv1alpha1.ForeignCluster) error {
+	if err := r.cordonVirtualNodes(fc, false); err != nil {
+		return err
+	}
+	klog.Infof("Unpeering from cluster %v canceled", fc.Name)
+	fc.Status.Outgoing.Unpeering = nil
+	return nil
+}
+
+// cordonVirtualNodes sets all the virtual nodes of the foreign cluster as (un)schedulable
+func (r *ForeignClusterReconciler) cordonVirtualNodes(fc *discoveryv1alpha1.ForeignCluster, unschedulable bool) error {
+	nodes, err := VirtualNodes(r.crdClient.Client(), fc.Spec.ClusterIdentity.ClusterID)
+	if err != nil {
+		return err
+	}
+	for _, nodeName := range nodes {
+		if err = CordonNode(r.crdClient.Client(), nodeName, unschedulable); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ForeignClusterReconciler) getDrainTimeout() time.Duration {
+	if r.DiscoveryCtrl == nil || r.DiscoveryCtrl.Config == nil {
+		return 0
+	}
+	return time.Duration(r.DiscoveryCtrl.Config.Unpeering.DrainTimeout) * time.Second
+}
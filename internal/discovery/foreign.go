@@ -31,8 +31,15 @@ func (discovery *DiscoveryCtrl) UpdateForeignLAN(data *TxtData) {
 }
 
 func (discovery *DiscoveryCtrl) UpdateForeignWAN(data []*TxtData, sd *v1alpha1.SearchDomain) []*v1alpha1.ForeignCluster {
+	return discovery.updateForeignFromSearchDomain(data, sd, v1alpha1.WanDiscovery)
+}
+
+func (discovery *DiscoveryCtrl) UpdateForeignRegistry(data []*TxtData, sd *v1alpha1.SearchDomain) []*v1alpha1.ForeignCluster {
+	return discovery.updateForeignFromSearchDomain(data, sd, v1alpha1.RegistryDiscovery)
+}
+
+func (discovery *DiscoveryCtrl) updateForeignFromSearchDomain(data []*TxtData, sd *v1alpha1.SearchDomain, discoveryType v1alpha1.DiscoveryType) []*v1alpha1.ForeignCluster {
 	createdUpdatedForeign := []*v1alpha1.ForeignCluster{}
-	discoveryType := v1alpha1.WanDiscovery
 	for _, txtData := range data {
 		if txtData.ID == discovery.ClusterId.GetClusterID() {
 			// is local cluster
@@ -153,15 +160,16 @@ func (discovery *DiscoveryCtrl) createForeign(txtData *TxtData, sd *v1alpha1.Sea
 				ClusterID:   txtData.ID,
 				ClusterName: txtData.Name,
 			},
-			Namespace:     txtData.Namespace,
-			ApiUrl:        txtData.ApiUrl,
-			DiscoveryType: discoveryType,
+			Namespace:       txtData.Namespace,
+			ApiUrl:          txtData.ApiUrl,
+			DiscoveryType:   discoveryType,
+			DnssecValidated: txtData.DnssecValidated,
 		},
 	}
 	fc.LastUpdateNow()
 
 	if sd != nil {
-		fc.Spec.Join = sd.Spec.AutoJoin
+		fc.Spec.Join = txtData.CanAutoJoin(sd)
 		fc.ObjectMeta.OwnerReferences = []metav1.OwnerReference{
 			{
 				APIVersion: "discovery.liqo.io/v1alpha1",
@@ -171,7 +179,8 @@ func (discovery *DiscoveryCtrl) createForeign(txtData *TxtData, sd *v1alpha1.Sea
 			},
 		}
 	}
-	if discoveryType == v1alpha1.LanDiscovery {
+	fc.Status.Capabilities = txtData.Capabilities.DeepCopy()
+	if discoveryType == v1alpha1.LanDiscovery || discoveryType == v1alpha1.RegistryDiscovery {
 		// set TTL
 		fc.Status.Ttl = txtData.Ttl
 	}
@@ -193,8 +202,12 @@ func (discovery *DiscoveryCtrl) CheckUpdate(txtData *TxtData, fc *v1alpha1.Forei
 		fc.Spec.ApiUrl = txtData.ApiUrl
 		fc.Spec.Namespace = txtData.Namespace
 		fc.Spec.DiscoveryType = discoveryType
-		if searchDomain != nil && discoveryType == v1alpha1.WanDiscovery {
-			fc.Spec.Join = searchDomain.Spec.AutoJoin
+		if searchDomain != nil && (discoveryType == v1alpha1.WanDiscovery || discoveryType == v1alpha1.RegistryDiscovery) {
+			fc.Spec.Join = txtData.CanAutoJoin(searchDomain)
+			fc.Spec.DnssecValidated = txtData.DnssecValidated
+		}
+		if discoveryType == v1alpha1.RegistryDiscovery {
+			fc.Status.Ttl = txtData.Ttl
 		}
 		if fc.Status.Outgoing.CaDataRef != nil {
 			err := discovery.crdClient.Client().CoreV1().Secrets(fc.Status.Outgoing.CaDataRef.Namespace).Delete(context.TODO(), fc.Status.Outgoing.CaDataRef.Name, metav1.DeleteOptions{})
@@ -204,6 +217,7 @@ func (discovery *DiscoveryCtrl) CheckUpdate(txtData *TxtData, fc *v1alpha1.Forei
 			}
 		}
 		fc.Status.Outgoing.CaDataRef = nil
+		fc.Status.Capabilities = txtData.Capabilities.DeepCopy()
 		fc.LastUpdateNow()
 		tmp, err := discovery.crdClient.Resource("foreignclusters").Update(fc.Name, fc, metav1.UpdateOptions{})
 		if err != nil {
@@ -241,6 +255,18 @@ func (discovery *DiscoveryCtrl) CheckUpdate(txtData *TxtData, fc *v1alpha1.Forei
 		}
 		return fc, true, nil
 	} else {
+		if discoveryType == v1alpha1.WanDiscovery && fc.Spec.DiscoveryType == v1alpha1.WanDiscovery {
+			// the validation of the records does not affect the current peering
+			fc.Spec.DnssecValidated = txtData.DnssecValidated
+		}
+		if discoveryType == v1alpha1.RegistryDiscovery && fc.Spec.DiscoveryType == v1alpha1.RegistryDiscovery {
+			// the TTL of the record may have been changed
+			fc.Status.Ttl = txtData.Ttl
+		}
+		if fc.Spec.DiscoveryType == discoveryType {
+			// the capabilities may have been changed, e.g. after an upgrade of the foreign cluster
+			fc.Status.Capabilities = txtData.Capabilities.DeepCopy()
+		}
 		// update "lastUpdate" annotation
 		fc.LastUpdateNow()
 		tmp, err := discovery.crdClient.Resource("foreignclusters").Update(fc.Name, fc, metav1.UpdateOptions{})
@@ -259,14 +285,13 @@ func (discovery *DiscoveryCtrl) CheckUpdate(txtData *TxtData, fc *v1alpha1.Forei
 }
 
 func (discovery *DiscoveryCtrl) GetForeignClusterByID(clusterID string) (*v1alpha1.ForeignCluster, error) {
-	tmp, err := discovery.crdClient.Resource("foreignclusters").List(metav1.ListOptions{
+	fcs, err := discovery.foreignClusterClient().List(context.TODO(), metav1.ListOptions{
 		LabelSelector: strings.Join([]string{"cluster-id", clusterID}, "="),
 	})
 	if err != nil {
 		return nil, err
 	}
-	fcs, ok := tmp.(*v1alpha1.ForeignClusterList)
-	if !ok || len(fcs.Items) == 0 {
+	if len(fcs.Items) == 0 {
 		return nil, k8serror.NewNotFound(schema.GroupResource{
 			Group:    v1alpha1.GroupVersion.Group,
 			Resource: "foreignclusters",
@@ -274,3 +299,7 @@ func (discovery *DiscoveryCtrl) GetForeignClusterByID(clusterID string) (*v1alph
 	}
 	return &fcs.Items[0], nil
 }
+
+func (discovery *DiscoveryCtrl) foreignClusterClient() *v1alpha1.ForeignClusterClient {
+	return v1alpha1.NewForeignClusterClient(discovery.crdClient)
+}
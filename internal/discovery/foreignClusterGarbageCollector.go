@@ -1,44 +1,340 @@
 package discovery
 
 import (
-	goerrors "errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 	"strings"
 	"time"
 )
 
+// GarbageReportName is the name of the ConfigMap listing the resources that the garbage collector would delete
+// if it was not in dry run mode
+const GarbageReportName = "discovery-gc-report"
+
+const defaultGarbageCollectionPeriod = 30 * time.Second
+
+// Garbage is a resource to be deleted by the garbage collector
+type Garbage struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+
+	delete func() error
+}
+
+func (g *Garbage) String() string {
+	if g.Namespace == "" {
+		return fmt.Sprintf("%v %v", g.Kind, g.Name)
+	}
+	return fmt.Sprintf("%v %v/%v", g.Kind, g.Namespace, g.Name)
+}
+
 func (discovery *DiscoveryCtrl) StartGarbageCollector() {
-	for range time.NewTicker(30 * time.Second).C {
+	for {
+		time.Sleep(discovery.getGarbageCollectionPeriod())
 		_ = discovery.CollectGarbage()
 	}
 }
 
-// The GarbageCollector deletes all ForeignClusters discovered with LAN that have expired TTL
+// The GarbageCollector deletes the stale ForeignClusters and the resources created for the peerings with ForeignClusters
+// that no longer exist. A ForeignCluster is stale if:
+// - it has been discovered with LAN or with a registry and its TTL has expired
+// - it has been discovered in WAN, we are not peered with it and its SearchDomain has not found it again in the configured WanTtl
+// - it has been discovered thanks to an incoming PeeringRequest and it has no active peering
+// the manually added ForeignClusters are never collected.
+// In dry run mode nothing is deleted, the garbage is written in the report ConfigMap
 func (discovery *DiscoveryCtrl) CollectGarbage() error {
-	tmp, err := discovery.crdClient.Resource("foreignclusters").List(metav1.ListOptions{
-		LabelSelector: strings.Join([]string{"discovery-type", string(v1alpha1.LanDiscovery)}, "="),
-	})
+	config := discovery.getGarbageCollectionConfig()
+
+	// the resources are listed before the ForeignClusters: the ones created for a ForeignCluster added in the
+	// meantime are found with their owner
+	resources, err := discovery.listOwnedResources(context.TODO())
 	if err != nil {
 		klog.Error(err)
 		return err
 	}
-	fcs, ok := tmp.(*v1alpha1.ForeignClusterList)
-	if !ok {
-		err = goerrors.New("retrieved object is not a ForeignCluster")
+	fcs, err := discovery.foreignClusterClient().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
 		klog.Error(err)
 		return err
 	}
 
-	for _, fc := range fcs.Items {
-		if fc.IsExpired() {
-			err = discovery.crdClient.Resource("foreignclusters").Delete(fc.Name, metav1.DeleteOptions{})
-			if err != nil {
-				klog.Error(err)
-				continue
+	garbage := discovery.getStaleForeignClusters(fcs.Items, &config)
+	garbage = append(garbage, discovery.getOrphanedResources(resources, fcs.Items)...)
+
+	if config.DryRun {
+		for _, g := range garbage {
+			klog.Infof("[dry run] %v would be deleted: %v", g.String(), g.Reason)
+		}
+		return discovery.writeGarbageReport(garbage)
+	}
+	for _, g := range garbage {
+		if err = g.delete(); err != nil && !errors.IsNotFound(err) {
+			klog.Error(err)
+			continue
+		}
+		klog.Infof("%v deleted: %v", g.String(), g.Reason)
+	}
+	return nil
+}
+
+func (discovery *DiscoveryCtrl) getGarbageCollectionConfig() configv1alpha1.GarbageCollectionConfig {
+	if discovery.Config == nil {
+		return configv1alpha1.GarbageCollectionConfig{}
+	}
+	return discovery.Config.GarbageCollection
+}
+
+func (discovery *DiscoveryCtrl) getGarbageCollectionPeriod() time.Duration {
+	config := discovery.getGarbageCollectionConfig()
+	if config.Period == 0 {
+		return defaultGarbageCollectionPeriod
+	}
+	return time.Duration(config.Period) * time.Second
+}
+
+func (discovery *DiscoveryCtrl) getStaleForeignClusters(fcs []v1alpha1.ForeignCluster, config *configv1alpha1.GarbageCollectionConfig) []*Garbage {
+	var res []*Garbage
+	for i := range fcs {
+		fc := &fcs[i]
+		var reason string
+		switch fc.Spec.DiscoveryType {
+		case v1alpha1.LanDiscovery:
+			if fc.IsExpired() {
+				reason = fmt.Sprintf("discovered with %v, TTL expired", fc.Spec.DiscoveryType)
+			}
+		case v1alpha1.RegistryDiscovery:
+			// the record may expire between two polls of the registry, the peering is kept as for the WAN discovery
+			if !isPeered(fc) && fc.IsExpired() {
+				reason = fmt.Sprintf("discovered with %v, TTL expired", fc.Spec.DiscoveryType)
+			}
+		case v1alpha1.WanDiscovery:
+			// the peering does not depend on the DNS records, it is kept if they are temporarily unavailable
+			if config.WanTtl > 0 && !isPeered(fc) && fc.IsOutdated(config.WanTtl) {
+				reason = fmt.Sprintf("discovered in WAN, not found by its SearchDomain in the last %vs", config.WanTtl)
+			}
+		case v1alpha1.IncomingPeeringDiscovery:
+			// the reference to the PeeringRequest is set after the creation of the ForeignCluster
+			if !isPeered(fc) && !discovery.hasPeeringRequest(fc) &&
+				fc.CreationTimestamp.Add(discovery.getGarbageCollectionPeriod()).Before(time.Now()) {
+				reason = "discovered thanks to an incoming PeeringRequest, no active peering"
 			}
 		}
+		if reason == "" {
+			continue
+		}
+		name := fc.Name
+		res = append(res, &Garbage{
+			Kind:   "ForeignCluster",
+			Name:   name,
+			Reason: reason,
+			delete: func() error {
+				return discovery.foreignClusterClient().Delete(context.TODO(), name, metav1.DeleteOptions{})
+			},
+		})
+	}
+	return res
+}
+
+func isPeered(fc *v1alpha1.ForeignCluster) bool {
+	return fc.Status.Outgoing.Joined || fc.Status.Outgoing.Advertisement != nil || fc.Status.Incoming.Joined
+}
+
+func (discovery *DiscoveryCtrl) hasPeeringRequest(fc *v1alpha1.ForeignCluster) bool {
+	if fc.Status.Incoming.PeeringRequest == nil {
+		return false
+	}
+	_, err := discovery.crdClient.Resource("peeringrequests").Get(fc.Status.Incoming.PeeringRequest.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Error(err)
+		// keep it until we know
+		return true
+	}
+	return err == nil
+}
+
+// the resources the ForeignCluster controller creates for a ForeignCluster
+type ownedResources struct {
+	clusterRoles        []rbacv1.ClusterRole
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+	roles               []rbacv1.Role
+	roleBindings        []rbacv1.RoleBinding
+	serviceAccounts     []corev1.ServiceAccount
+	secrets             []corev1.Secret
+}
+
+func (discovery *DiscoveryCtrl) listOwnedResources(ctx context.Context) (*ownedResources, error) {
+	client := discovery.crdClient.Client()
+	res := &ownedResources{}
+
+	clusterRoles, err := client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.clusterRoles = clusterRoles.Items
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.clusterRoleBindings = clusterRoleBindings.Items
+	roles, err := client.RbacV1().Roles(discovery.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.roles = roles.Items
+	roleBindings, err := client.RbacV1().RoleBindings(discovery.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.roleBindings = roleBindings.Items
+	serviceAccounts, err := client.CoreV1().ServiceAccounts(discovery.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.serviceAccounts = serviceAccounts.Items
+	secrets, err := client.CoreV1().Secrets(discovery.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res.secrets = secrets.Items
+	return res, nil
+}
+
+// get the resources created by the ForeignCluster controller for a ForeignCluster that no longer exists:
+// if their owner reference cannot be resolved, e.g. because of a wrong APIVersion, they are not deleted by Kubernetes.
+// The ClusterRoleBindings of the CRDReplicator are owned by the ServiceAccount of the foreign cluster in the
+// older versions, they are collected when the ServiceAccount no longer exists.
+// The resources have to be listed before the ForeignClusters, not to collect the ones of a new ForeignCluster
+func (discovery *DiscoveryCtrl) getOrphanedResources(resources *ownedResources, fcs []v1alpha1.ForeignCluster) []*Garbage {
+	owners := map[string]bool{}
+	for i := range fcs {
+		owners[string(fcs[i].UID)] = true
+		owners[fcs[i].Name] = true
+	}
+	// the owner is not found neither by UID nor by name, a ForeignCluster with the same name reuses its resources
+	isOrphan := func(refs []metav1.OwnerReference) (string, bool) {
+		for _, ref := range refs {
+			if ref.Kind == "ForeignCluster" && !owners[string(ref.UID)] && !owners[ref.Name] {
+				return fmt.Sprintf("owner ForeignCluster %v no longer exists", ref.Name), true
+			}
+		}
+		return "", false
+	}
+
+	client := discovery.crdClient.Client()
+	ctx := context.TODO()
+	var res []*Garbage
+
+	for _, item := range resources.clusterRoles {
+		if reason, ok := isOrphan(item.OwnerReferences); ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "ClusterRole", Name: name, Reason: reason, delete: func() error {
+				return client.RbacV1().ClusterRoles().Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	saUIDs := map[types.UID]bool{}
+	for _, sa := range resources.serviceAccounts {
+		saUIDs[sa.UID] = true
+	}
+	for _, item := range resources.clusterRoleBindings {
+		reason, ok := isOrphan(item.OwnerReferences)
+		if !ok && strings.HasSuffix(item.Name, "-crdReplicator") {
+			for _, ref := range item.OwnerReferences {
+				if ref.Kind == "ServiceAccount" && !saUIDs[ref.UID] {
+					reason, ok = fmt.Sprintf("owner ServiceAccount %v no longer exists", ref.Name), true
+				}
+			}
+		}
+		if ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "ClusterRoleBinding", Name: name, Reason: reason, delete: func() error {
+				return client.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	for _, item := range resources.roles {
+		if reason, ok := isOrphan(item.OwnerReferences); ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "Role", Namespace: discovery.Namespace, Name: name, Reason: reason, delete: func() error {
+				return client.RbacV1().Roles(discovery.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	for _, item := range resources.roleBindings {
+		if reason, ok := isOrphan(item.OwnerReferences); ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "RoleBinding", Namespace: discovery.Namespace, Name: name, Reason: reason, delete: func() error {
+				return client.RbacV1().RoleBindings(discovery.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	for _, item := range resources.serviceAccounts {
+		if reason, ok := isOrphan(item.OwnerReferences); ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "ServiceAccount", Namespace: discovery.Namespace, Name: name, Reason: reason, delete: func() error {
+				return client.CoreV1().ServiceAccounts(discovery.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	for _, item := range resources.secrets {
+		if reason, ok := isOrphan(item.OwnerReferences); ok {
+			name := item.Name
+			res = append(res, &Garbage{Kind: "Secret", Namespace: discovery.Namespace, Name: name, Reason: reason, delete: func() error {
+				return client.CoreV1().Secrets(discovery.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			}})
+		}
+	}
+
+	return res
+}
+
+// write the garbage found by the last collection in the report ConfigMap
+func (discovery *DiscoveryCtrl) writeGarbageReport(garbage []*Garbage) error {
+	if garbage == nil {
+		garbage = []*Garbage{}
+	}
+	report, err := json.Marshal(garbage)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	data := map[string]string{
+		"lastRun": time.Now().UTC().Format(time.RFC3339),
+		"garbage": string(report),
+	}
+
+	client := discovery.crdClient.Client().CoreV1().ConfigMaps(discovery.Namespace)
+	cm, err := client.Get(context.TODO(), GarbageReportName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = client.Create(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: GarbageReportName,
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		cm.Data = data
+		_, err = client.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		klog.Error(err)
+		return err
 	}
 	return nil
 }
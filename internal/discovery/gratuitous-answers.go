@@ -13,7 +13,7 @@ func (discovery *DiscoveryCtrl) StartGratuitousAnswers() {
 func (discovery *DiscoveryCtrl) sendAnswer() {
 	discovery.serverMux.Lock()
 	defer discovery.serverMux.Unlock()
-	if discovery.mdnsServer != nil {
-		discovery.mdnsServer.SendMulticast()
+	for _, server := range discovery.mdnsServers {
+		server.SendMulticast()
 	}
 }
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"k8s.io/klog"
+	"net"
+	"path"
+)
+
+var errNoInterface = errors.New("no network interface matches the mDNS interface configuration")
+
+// InterfaceFilter selects the network interfaces used by the mDNS discovery
+type InterfaceFilter struct {
+	allow []string
+	deny  []string
+	cidrs []*net.IPNet
+}
+
+// NewInterfaceFilter creates a filter from the configured allow, deny and CIDR lists
+// a nil config accepts every interface
+func NewInterfaceFilter(config *configv1alpha1.MdnsInterfaceConfig) (*InterfaceFilter, error) {
+	filter := &InterfaceFilter{}
+	if config == nil {
+		return filter, nil
+	}
+	for _, pattern := range append(append([]string{}, config.Allow...), config.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid interface pattern %v: %v", pattern, err)
+		}
+	}
+	filter.allow = config.Allow
+	filter.deny = config.Deny
+	for _, cidr := range config.Cidrs {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		filter.cidrs = append(filter.cidrs, ipnet)
+	}
+	return filter, nil
+}
+
+// Accept returns true if the interface with the given name and addresses can be used
+func (f *InterfaceFilter) Accept(name string, ips []net.IP) bool {
+	if len(f.allow) > 0 && !matchName(f.allow, name) {
+		return false
+	}
+	if matchName(f.deny, name) {
+		return false
+	}
+	if len(f.cidrs) == 0 {
+		return true
+	}
+	for _, ip := range ips {
+		for _, ipnet := range f.cidrs {
+			if ipnet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectApiAddress returns the API server address reachable through an interface connected to the given networks,
+// i.e. the first candidate belonging to one of them. If there is no such candidate, the first one is returned
+func SelectApiAddress(networks []*net.IPNet, candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	for _, candidate := range candidates {
+		ip := net.ParseIP(candidate)
+		if ip == nil {
+			continue
+		}
+		for _, ipnet := range networks {
+			if ipnet.Contains(ip) {
+				return candidate
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// get the URL of the API server to be announced on each interface, grouping the interfaces announcing the same URL
+// if no interface is given, the default URL is announced on every interface
+func (discovery *DiscoveryCtrl) getInterfaceUrls(ifaces []net.Interface) (map[string][]net.Interface, error) {
+	addresses, err := discovery.getAPIAddresses()
+	if err != nil {
+		return nil, err
+	}
+	port := getAPIPort()
+	if len(ifaces) == 0 {
+		return map[string][]net.Interface{
+			getURL(addresses[0], port): nil,
+		}, nil
+	}
+
+	var apiUrls map[string]string
+	if discovery.Config.Interfaces != nil {
+		apiUrls = discovery.Config.Interfaces.ApiUrls
+	}
+	res := map[string][]net.Interface{}
+	for _, ifi := range ifaces {
+		url, ok := apiUrls[ifi.Name]
+		if !ok {
+			url = getURL(SelectApiAddress(getNetworks(&ifi), addresses), port)
+		}
+		res[url] = append(res[url], ifi)
+	}
+	return res, nil
+}
+
+func getNetworks(ifi *net.Interface) []*net.IPNet {
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		klog.Warning(err)
+		return nil
+	}
+	var res []*net.IPNet
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			res = append(res, ipnet)
+		}
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package kubeconfig
+
+import (
+	"context"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"time"
+)
+
+// request a token for the ServiceAccount, expiring after ttl seconds and optionally bound to a Secret
+func requestToken(clientset kubernetes.Interface, serviceAccountName string, namespace string, ttl int64, boundTo *corev1.Secret) (string, *metav1.Time, error) {
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &ttl,
+		},
+	}
+	if boundTo != nil {
+		tokenRequest.Spec.BoundObjectRef = &authenticationv1.BoundObjectReference{
+			Kind:       "Secret",
+			APIVersion: "v1",
+			Name:       boundTo.Name,
+			UID:        boundTo.UID,
+		}
+	}
+	tokenRequest, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.TODO(), serviceAccountName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+	return tokenRequest.Status.Token, &tokenRequest.Status.ExpirationTimestamp, nil
+}
+
+// GetCredentialsSecret returns the Secret the tokens issued for a foreign cluster are bound to, creating it if it does
+// not exist. The tokens are revoked when the Secret is deleted, e.g. by the garbage collector when owner is deleted
+func GetCredentialsSecret(clientset kubernetes.Interface, namespace string, name string, owner metav1.OwnerReference) (*corev1.Secret, error) {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil || !errors.IsNotFound(err) {
+		return secret, err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	}
+	return clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+}
+
+// RotationTime returns when the credentials expiring at expiration have to be rotated, i.e. when 80% of their ttl elapsed
+func RotationTime(expiration *metav1.Time, ttl int64) time.Time {
+	return expiration.Add(-time.Duration(ttl) * time.Second / 5)
+}
+
+// NeedsRotation returns true if credentials with the given ttl are required and the current ones, expiring at
+// expiration, have to be rotated. Credentials without expiration have to be replaced too
+func NeedsRotation(expiration *metav1.Time, ttl int64) bool {
+	if ttl <= 0 {
+		return false
+	}
+	return expiration == nil || !time.Now().Before(RotationTime(expiration, ttl))
+}
@@ -3,30 +3,124 @@ package kubeconfig
 import (
 	"context"
 	"errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
 	"k8s.io/klog"
 	kubeconfigutil "k8s.io/kubernetes/cmd/kubeadm/app/util/kubeconfig"
 	"os"
 )
 
+const clusterName = "service-cluster"
+
 // this function creates a kube-config file for a specified ServiceAccount
-func CreateKubeConfig(clientset kubernetes.Interface, serviceAccountName string, namespace string) (string, error) {
-	serviceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), serviceAccountName, v1.GetOptions{})
+// the API server endpoints and CA are taken from apiServerConfig, if set
+//
+// if apiServerConfig sets a CredentialsTtl, the embedded token is requested through the TokenRequest API and expires
+// at the returned time; if boundTo is set, the token is bound to that Secret, whose deletion revokes it.
+// Otherwise, the long-lived token of the ServiceAccount is embedded and the returned expiration is nil
+func CreateKubeConfig(clientset kubernetes.Interface, apiServerConfig *configv1alpha1.ApiServerConfig, serviceAccountName string, namespace string, boundTo *corev1.Secret) (string, *metav1.Time, error) {
+	serviceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), serviceAccountName, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), serviceAccount.Secrets[0].Name, v1.GetOptions{})
+	var secret *corev1.Secret
+	if len(serviceAccount.Secrets) > 0 {
+		secret, err = clientset.CoreV1().Secrets(namespace).Get(context.TODO(), serviceAccount.Secrets[0].Name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	expiring := apiServerConfig != nil && apiServerConfig.CredentialsTtl > 0
+	if secret == nil && !expiring {
+		return "", nil, fmt.Errorf("ServiceAccount %v has no Secret", serviceAccountName)
+	}
+
+	var caData []byte
+	if secret != nil {
+		caData = secret.Data["ca.crt"]
+	}
+	if apiServerConfig != nil && len(apiServerConfig.CaData) > 0 {
+		caData = apiServerConfig.CaData
+	}
+	servers, err := getServers(clientset, apiServerConfig, caData)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
+	var token string
+	var expiration *metav1.Time
+	if expiring {
+		token, expiration, err = requestToken(clientset, serviceAccountName, namespace, apiServerConfig.CredentialsTtl, boundTo)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		token = string(secret.Data["token"])
+	}
+
+	cnf := kubeconfigutil.CreateWithToken(servers[0], clusterName, serviceAccountName, caData, token)
+	// the other servers are added as further contexts, to be used if the current one is not reachable
+	for i, server := range servers[1:] {
+		name := fmt.Sprintf("%v-%v", clusterName, i+1)
+		cnf.Clusters[name] = &clientcmdapi.Cluster{
+			Server:                   server,
+			CertificateAuthorityData: caData,
+		}
+		cnf.Contexts[fmt.Sprintf("%s@%s", serviceAccountName, name)] = &clientcmdapi.Context{
+			Cluster:  name,
+			AuthInfo: serviceAccountName,
+		}
+	}
+	r, err := runtime.Encode(clientcmdlatest.Codec, cnf)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(r), expiration, nil
+}
+
+// get the API server URLs to be written in the kubeconfig, the reachable ones first
+// the configured endpoints are checked, at least one of them has to be reachable
+func getServers(clientset kubernetes.Interface, apiServerConfig *configv1alpha1.ApiServerConfig, caData []byte) ([]string, error) {
+	if apiServerConfig == nil || len(apiServerConfig.Endpoints) == 0 {
+		server, err := getDefaultServer(clientset)
+		if err != nil {
+			return nil, err
+		}
+		return []string{server}, nil
+	}
+
+	var reachable, unreachable []string
+	for _, endpoint := range apiServerConfig.Endpoints {
+		if err := crdClient.CheckConnectivity(endpoint, caData); err != nil {
+			klog.Warningf("API server endpoint %v is not reachable: %v", endpoint, err)
+			unreachable = append(unreachable, endpoint)
+			continue
+		}
+		reachable = append(reachable, endpoint)
+	}
+	if len(reachable) == 0 {
+		err := errors.New("none of the configured API server endpoints is reachable")
+		klog.Error(err)
+		return nil, err
+	}
+	// the unreachable endpoints may be temporarily down, they are kept for failover
+	return append(reachable, unreachable...), nil
+}
+
+// get the API server URL from the APISERVER env variable or the address of the first master,
+// and from the APISERVER_PORT env variable or the default port
+func getDefaultServer(clientset kubernetes.Interface) (string, error) {
 	address, ok := os.LookupEnv("APISERVER")
 	if !ok || address == "" {
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), v1.ListOptions{
+		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
 			LabelSelector: "node-role.kubernetes.io/master",
 		})
 		if err != nil {
@@ -45,13 +139,5 @@ func CreateKubeConfig(clientset kubernetes.Interface, serviceAccountName string,
 		port = "6443"
 	}
 
-	token := string(secret.Data["token"])
-	server := "https://" + address + ":" + port
-
-	cnf := kubeconfigutil.CreateWithToken(server, "service-cluster", serviceAccountName, secret.Data["ca.crt"], token)
-	r, err := runtime.Encode(clientcmdlatest.Codec, cnf)
-	if err != nil {
-		return "", err
-	}
-	return string(r), nil
+	return "https://" + address + ":" + port, nil
 }
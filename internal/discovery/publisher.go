@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"k8s.io/klog"
+	"time"
+)
+
+// recordPublisher publishes the local cluster in a discovery backend
+type recordPublisher interface {
+	// Publish adds or replaces the records of the cluster
+	Publish(txtData *TxtData) error
+	// Withdraw removes the published records
+	Withdraw() error
+	// RefreshPeriod returns the period after that the records have to be published again
+	RefreshPeriod() time.Duration
+}
+
+// publisherRunner controls a goroutine periodically publishing the local cluster
+type publisherRunner struct {
+	stop chan bool
+	done chan struct{}
+}
+
+// start a goroutine periodically publishing the local cluster with the publisher returned by newPublisher,
+// which is called again on the next period if it fails
+func (discovery *DiscoveryCtrl) startPublisher(newPublisher func() (recordPublisher, error)) *publisherRunner {
+	runner := &publisherRunner{
+		stop: make(chan bool),
+		done: make(chan struct{}),
+	}
+	go discovery.runPublisher(newPublisher, runner.stop, runner.done)
+	return runner
+}
+
+// Stop stops the publication, waiting for the records to be withdrawn
+func (runner *publisherRunner) Stop() {
+	if runner == nil {
+		return
+	}
+	close(runner.stop)
+	<-runner.done
+}
+
+func (discovery *DiscoveryCtrl) runPublisher(newPublisher func() (recordPublisher, error), stop chan bool, done chan struct{}) {
+	defer close(done)
+
+	var publisher recordPublisher
+	period := 30 * time.Second
+	for {
+		if publisher == nil {
+			var err error
+			if publisher, err = newPublisher(); err != nil {
+				klog.Error(err)
+				publisher = nil
+			} else {
+				period = publisher.RefreshPeriod()
+			}
+		}
+		if publisher != nil {
+			if txtData, err := discovery.GetTxtData(); err != nil {
+				klog.Error(err)
+			} else if err = publisher.Publish(txtData); err != nil {
+				klog.Error(err)
+			}
+		}
+
+		select {
+		case <-stop:
+			if publisher != nil {
+				if err := publisher.Withdraw(); err != nil {
+					klog.Error(err)
+				}
+			}
+			return
+		case <-time.After(period):
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sync"
+	"testing"
+)
+
+func TestDiscoveryCtrl_ReloadAndStopPublishers(t *testing.T) {
+	crdClient.Fake = true
+	client, err := crdClient.NewFromConfig(nil)
+	assert.NoError(t, err)
+
+	// the key Secret does not exist, the publishers keep retrying until they are stopped
+	discovery := &DiscoveryCtrl{
+		crdClient: client,
+		Config: &configv1alpha1.DiscoveryConfig{
+			RegistryPublication: &configv1alpha1.RegistryPublicationConfig{
+				Url:          "http://127.0.0.1:1",
+				KeySecretRef: corev1.SecretReference{Name: "registry-key", Namespace: "default"},
+			},
+		},
+	}
+	discovery.publisherMux.Lock()
+	discovery.startRegistryPublisher()
+	discovery.publisherMux.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			discovery.reloadRegistryPublisher()
+		}()
+	}
+	discovery.StopDiscovery()
+	wg.Wait()
+
+	// the reloads following the stop do not start the publisher again
+	assert.Nil(t, discovery.registryPublisher)
+	discovery.reloadRegistryPublisher()
+	assert.Nil(t, discovery.registryPublisher)
+}
@@ -15,7 +15,14 @@ func (discovery *DiscoveryCtrl) Register() {
 			klog.Error(err)
 			return
 		}
-		txt, err := txtData.Encode()
+		ifaces := discovery.getInterfaces()
+		if len(ifaces) == 0 && discovery.Config.Interfaces != nil {
+			// the selection has to be respected, not falling back to all the interfaces
+			klog.Error(errNoInterface)
+			return
+		}
+		// every interface announces the URL reachable on it
+		ifaceUrls, err := discovery.getInterfaceUrls(ifaces)
 		if err != nil {
 			klog.Error(err)
 			return
@@ -23,13 +30,27 @@ func (discovery *DiscoveryCtrl) Register() {
 
 		var ttl = discovery.Config.Ttl
 		discovery.serverMux.Lock()
-		discovery.mdnsServer, err = zeroconf.Register(discovery.Config.Name+"_"+discovery.ClusterId.GetClusterID(), discovery.Config.Service, discovery.Config.Domain, discovery.Config.Port, txt, discovery.getInterfaces(), ttl)
+		for url, urlIfaces := range ifaceUrls {
+			data := *txtData
+			data.ApiUrl = url
+			var txt []string
+			txt, err = data.Encode()
+			if err != nil {
+				break
+			}
+			var server *zeroconf.Server
+			server, err = zeroconf.Register(discovery.Config.Name+"_"+discovery.ClusterId.GetClusterID(), discovery.Config.Service, discovery.Config.Domain, discovery.Config.Port, txt, urlIfaces, ttl)
+			if err != nil {
+				break
+			}
+			discovery.mdnsServers = append(discovery.mdnsServers, server)
+		}
 		discovery.serverMux.Unlock()
+		defer discovery.shutdownServer()
 		if err != nil {
 			klog.Error(err)
 			return
 		}
-		defer discovery.shutdownServer()
 		<-discovery.stopMDNS
 	}
 }
@@ -37,7 +58,10 @@ func (discovery *DiscoveryCtrl) Register() {
 func (discovery *DiscoveryCtrl) shutdownServer() {
 	discovery.serverMux.Lock()
 	defer discovery.serverMux.Unlock()
-	discovery.mdnsServer.Shutdown()
+	for _, server := range discovery.mdnsServers {
+		server.Shutdown()
+	}
+	discovery.mdnsServers = nil
 }
 
 func (discovery *DiscoveryCtrl) getInterfaces() []net.Interface {
@@ -50,6 +74,11 @@ func (discovery *DiscoveryCtrl) getInterfaces() []net.Interface {
 	if err != nil {
 		return nil
 	}
+	filter, err := NewInterfaceFilter(discovery.Config.Interfaces)
+	if err != nil {
+		klog.Error(err)
+		return nil
+	}
 	for _, ifi := range ifaces {
 		addrs, err := ifi.Addrs()
 		if err != nil {
@@ -57,6 +86,7 @@ func (discovery *DiscoveryCtrl) getInterfaces() []net.Interface {
 		}
 		// select interfaces with IP addresses not in pod local network
 		sel := false
+		ips := make([]net.IP, 0, len(addrs))
 		for _, addr := range addrs {
 			ip := getIP(addr)
 			if !isPod(podNets, ip) {
@@ -64,8 +94,11 @@ func (discovery *DiscoveryCtrl) getInterfaces() []net.Interface {
 					sel = true
 				}
 			}
+			if ip != nil {
+				ips = append(ips, ip)
+			}
 		}
-		if !sel {
+		if !sel || !filter.Accept(ifi.Name, ips) {
 			continue
 		}
 
@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RegistryPublisher publishes the signed record of a cluster in a discovery registry
+type RegistryPublisher struct {
+	client *registry.Client
+	key    ed25519.PrivateKey
+	ttl    uint32
+
+	// the ID of the last published cluster, to be withdrawn
+	published string
+}
+
+// NewRegistryPublisher creates a publisher for the registry in config, signing the records with privateKey
+// if httpClient is nil, the default registry client is used
+func NewRegistryPublisher(config *configv1alpha1.RegistryPublicationConfig, privateKey ed25519.PrivateKey, httpClient *http.Client) (*RegistryPublisher, error) {
+	if config == nil || config.Url == "" {
+		return nil, errors.New("url is required to publish the cluster in a registry")
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	p := &RegistryPublisher{
+		client: registry.NewClient(config.Url, httpClient),
+		key:    privateKey,
+		ttl:    config.Ttl,
+	}
+	if p.ttl == 0 {
+		p.ttl = 300
+	}
+	return p, nil
+}
+
+// RefreshPeriod returns the period after that the record has to be published again
+func (p *RegistryPublisher) RefreshPeriod() time.Duration {
+	return time.Duration(p.ttl) * time.Second / 2
+}
+
+// Publish adds (or replaces) the record of the cluster described by txtData to the registry
+func (p *RegistryPublisher) Publish(txtData *TxtData) error {
+	if p.published != "" && p.published != txtData.ID {
+		if err := p.Withdraw(); err != nil {
+			return err
+		}
+	}
+	record := &registry.Record{
+		ClusterID:    txtData.ID,
+		ClusterName:  txtData.Name,
+		Namespace:    txtData.Namespace,
+		ApiUrl:       txtData.ApiUrl,
+		Capabilities: append([]string{"txtvers=" + strconv.Itoa(TxtVersion)}, txtData.EncodeCapabilities()...),
+		Ttl:          p.ttl,
+	}
+	if err := record.Sign(p.key); err != nil {
+		return err
+	}
+	if err := p.client.Publish(record); err != nil {
+		return err
+	}
+	p.published = txtData.ID
+	return nil
+}
+
+// Withdraw removes the published record from the registry
+func (p *RegistryPublisher) Withdraw() error {
+	if p.published == "" {
+		return nil
+	}
+	record := &registry.Record{
+		ClusterID: p.published,
+		Ttl:       0,
+	}
+	if err := record.Sign(p.key); err != nil {
+		return err
+	}
+	if err := p.client.Withdraw(record); err != nil {
+		return err
+	}
+	p.published = ""
+	return nil
+}
+
+// TxtDataFromRecord converts a registry record to the TxtData used to create the ForeignCluster
+func TxtDataFromRecord(record *registry.Record) (*TxtData, error) {
+	txtData := &TxtData{
+		ID:        record.ClusterID,
+		Name:      record.ClusterName,
+		Namespace: record.Namespace,
+		ApiUrl:    record.ApiUrl,
+		Ttl:       record.Ttl,
+	}
+	txtData.Capabilities.TxtVersion = 1
+	for _, d := range record.Capabilities {
+		txtData.DecodeCapability(d)
+	}
+	if txtData.ID == "" || txtData.Namespace == "" || txtData.ApiUrl == "" {
+		return nil, errors.New("TxtData missing required field")
+	}
+	return txtData, nil
+}
+
+// get the ed25519 private key stored in the Secret referenced by the configuration
+func (discovery *DiscoveryCtrl) getRegistryKey(config *configv1alpha1.RegistryPublicationConfig) (ed25519.PrivateKey, error) {
+	secret, err := discovery.crdClient.Client().CoreV1().Secrets(config.KeySecretRef.Namespace).Get(context.TODO(), config.KeySecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return registry.ParsePrivateKey(secret.Data["privateKey"])
+}
+
+// start publishing the local cluster in the registry, if configured
+func (discovery *DiscoveryCtrl) startRegistryPublisher() {
+	if discovery.Config == nil || discovery.Config.RegistryPublication == nil {
+		return
+	}
+	config := discovery.Config.RegistryPublication.DeepCopy()
+	discovery.registryPublisher = discovery.startPublisher(func() (recordPublisher, error) {
+		key, err := discovery.getRegistryKey(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewRegistryPublisher(config, key, nil)
+	})
+}
+
+// stop publishing the local cluster in the registry, waiting for its record to be withdrawn
+func (discovery *DiscoveryCtrl) stopRegistryPublisher() {
+	discovery.registryPublisher.Stop()
+	discovery.registryPublisher = nil
+}
+
+func (discovery *DiscoveryCtrl) reloadRegistryPublisher() {
+	discovery.publisherMux.Lock()
+	defer discovery.publisherMux.Unlock()
+	if discovery.publishersStopped {
+		return
+	}
+	klog.Info("Reload registry publisher")
+	discovery.stopRegistryPublisher()
+	discovery.startRegistryPublisher()
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client publishes and retrieves the records stored in a discovery registry
+//
+// the registry exposes the following HTTP API:
+//
+//	GET    <url>/records        lists the records, as a JSON array
+//	PUT    <url>/records/<id>   publishes the signed record of cluster <id>
+//	DELETE <url>/records/<id>   withdraws the record of cluster <id>, the body is a signed record with zero TTL
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the registry at the given url
+// if httpClient is nil, a client with a 30 seconds timeout is used
+func NewClient(registryUrl string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		url:        strings.TrimSuffix(registryUrl, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// List returns the records stored in the registry which are correctly signed by one of the trusted keys and not expired
+func (c *Client) List(trustedKeys []string) ([]*Record, error) {
+	resp, err := c.httpClient.Get(c.url + "/records")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %v answered with status %v", c.url, resp.Status)
+	}
+
+	var records []*Record
+	if err = json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return nil, err
+	}
+	var res []*Record
+	for _, record := range records {
+		if record == nil || record.Ttl == 0 || record.Verify() != nil || !record.IsSignedBy(trustedKeys) {
+			continue
+		}
+		res = append(res, record)
+	}
+	return res, nil
+}
+
+// Publish stores a signed record in the registry
+func (c *Client) Publish(record *Record) error {
+	return c.send(http.MethodPut, record)
+}
+
+// Withdraw removes the record of a cluster from the registry
+// record has to be signed with zero TTL and with the same key of the published one
+func (c *Client) Withdraw(record *Record) error {
+	return c.send(http.MethodDelete, record)
+}
+
+func (c *Client) send(method string, record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, c.url+"/records/"+url.PathEscape(record.ClusterID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("registry %v answered with status %v: %v", c.url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
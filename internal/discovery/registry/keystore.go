@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+// KeyStore persists the public keys the cluster IDs are pinned to, so that the registry keeps refusing the records
+// signed with another key also after it has been restarted or the record of the cluster has expired
+type KeyStore interface {
+	// Keys returns the pinned public keys by cluster ID
+	Keys() (map[string][]byte, error)
+	// Pin persists the public key the cluster ID is pinned to
+	Pin(clusterID string, publicKey []byte) error
+}
+
+// ConfigMapKeyStore stores the pinned keys in a ConfigMap, each base64 encoded key in the entry named after its cluster ID
+// a cluster can be registered again with another key by removing its entry
+type ConfigMapKeyStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapKeyStore creates a KeyStore using the ConfigMap with the given namespace and name, created when the
+// first key is pinned
+func NewConfigMapKeyStore(clientset kubernetes.Interface, namespace, name string) *ConfigMapKeyStore {
+	return &ConfigMapKeyStore{
+		clientset: clientset,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (s *ConfigMapKeyStore) Keys() (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for clusterID, value := range cm.Data {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			klog.Errorf("invalid key of cluster %v in ConfigMap %v/%v: %v", clusterID, s.namespace, s.name, err)
+			continue
+		}
+		keys[clusterID] = key
+	}
+	return keys, nil
+}
+
+func (s *ConfigMapKeyStore) Pin(clusterID string, publicKey []byte) error {
+	retriable := func(err error) bool {
+		return kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, retriable, func() error {
+		cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+				},
+				Data: map[string]string{
+					clusterID: base64.StdEncoding.EncodeToString(publicKey),
+				},
+			}
+			_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[clusterID] = base64.StdEncoding.EncodeToString(publicKey)
+		_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// the clocks of the clusters and of the registry may be not perfectly synchronized
+const maxClockSkew = 5 * time.Minute
+
+// Record is the information a cluster publishes in the registry to be discovered by the other clusters,
+// the same carried by the TXT and SRV records in DNS discovery
+type Record struct {
+	ClusterID   string `json:"clusterID"`
+	ClusterName string `json:"clusterName,omitempty"`
+	Namespace   string `json:"namespace"`
+	ApiUrl      string `json:"apiUrl"`
+	// capabilities of the cluster, encoded as the TXT entries of the DNS discovery
+	Capabilities []string `json:"capabilities,omitempty"`
+	// seconds after Timestamp the record expires, a record with zero TTL withdraws the previous ones
+	Ttl uint32 `json:"ttl"`
+	// unix time when the record has been signed
+	Timestamp int64 `json:"timestamp"`
+
+	// ed25519 public key of the cluster
+	PublicKey []byte `json:"publicKey"`
+	// ed25519 signature of the record, computed with Signature empty
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// Sign sets the timestamp, the public key and the signature of the record
+func (r *Record) Sign(key ed25519.PrivateKey) error {
+	r.Timestamp = time.Now().Unix()
+	r.PublicKey = key.Public().(ed25519.PublicKey)
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	r.Signature = ed25519.Sign(key, payload)
+	return nil
+}
+
+// Verify checks that the record is correctly signed by its public key and that it is not expired
+func (r *Record) Verify() error {
+	if r.ClusterID == "" {
+		return errors.New("record missing cluster ID")
+	}
+	if len(r.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key in record for cluster %v", r.ClusterID)
+	}
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(r.PublicKey, payload, r.Signature) {
+		return fmt.Errorf("invalid signature in record for cluster %v", r.ClusterID)
+	}
+	if time.Unix(r.Timestamp, 0).After(time.Now().Add(maxClockSkew)) {
+		return fmt.Errorf("record for cluster %v signed in the future", r.ClusterID)
+	}
+	if r.Ttl > 0 && r.IsExpired() {
+		return fmt.Errorf("record for cluster %v expired", r.ClusterID)
+	}
+	return nil
+}
+
+// IsExpired returns true if the TTL of the record expired
+func (r *Record) IsExpired() bool {
+	return time.Unix(r.Timestamp+int64(r.Ttl), 0).Before(time.Now())
+}
+
+// IsSignedBy returns true if the record has been signed by one of the given base64 encoded public keys
+// the signature has to be checked with Verify
+func (r *Record) IsSignedBy(keys []string) bool {
+	for _, key := range keys {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err == nil && bytes.Equal(decoded, r.PublicKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Record) payload() ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = nil
+	return json.Marshal(&unsigned)
+}
+
+// ParsePrivateKey parses a PEM encoded (PKCS #8) ed25519 private key
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not an ed25519 key")
+	}
+	return edKey, nil
+}
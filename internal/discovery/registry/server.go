@@ -0,0 +1,157 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maximum size of the body of the requests, far larger than a record
+const maxRecordSize = 64 << 10
+
+// Handler is an in-memory discovery registry, serving the HTTP API used by Client
+// a cluster ID is pinned to the key it has been first published with, and its record can only be replaced or
+// withdrawn with that key
+type Handler struct {
+	mtx     sync.Mutex
+	records map[string]*Record
+	// the public keys the cluster IDs are pinned to, persisted in store
+	keys  map[string][]byte
+	store KeyStore
+}
+
+// NewHandler creates an empty registry, reading the pinned keys from store
+func NewHandler(store KeyStore) (*Handler, error) {
+	keys, err := store.Keys()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		records: map[string]*Record{},
+		keys:    keys,
+		store:   store,
+	}, nil
+}
+
+// ListenAndServe serves an empty registry on the given TCP address until stop is closed
+func ListenAndServe(addr string, store KeyStore, stop <-chan struct{}) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(listener, store, stop)
+}
+
+// Serve serves an empty registry on the given listener until stop is closed
+// the records are kept in memory only, the clusters publish them again when they are refreshed, while the keys the
+// cluster IDs are pinned to are persisted in store
+func Serve(listener net.Listener, store KeyStore, stop <-chan struct{}) error {
+	handler, err := NewHandler(store)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: handler}
+	go func() {
+		<-stop
+		if err := server.Close(); err != nil {
+			klog.Error(err)
+		}
+	}()
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case path == "/records" && req.Method == http.MethodGet:
+		h.list(w)
+	case strings.HasPrefix(path, "/records/") && (req.Method == http.MethodPut || req.Method == http.MethodDelete):
+		h.update(w, req, strings.TrimPrefix(path, "/records/"))
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (h *Handler) list(w http.ResponseWriter) {
+	h.mtx.Lock()
+	records := []*Record{}
+	for id, record := range h.records {
+		if record.IsExpired() {
+			delete(h.records, id)
+			continue
+		}
+		records = append(records, record)
+	}
+	h.mtx.Unlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ClusterID < records[j].ClusterID
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		klog.Error(err)
+	}
+}
+
+func (h *Handler) update(w http.ResponseWriter, req *http.Request, clusterID string) {
+	// the cluster IDs are the names of the entries of the key store
+	if errs := validation.IsConfigMapKey(clusterID); len(errs) > 0 {
+		http.Error(w, "invalid cluster ID: "+strings.Join(errs, ", "), http.StatusBadRequest)
+		return
+	}
+	var record Record
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRecordSize)).Decode(&record); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if record.ClusterID != clusterID {
+		http.Error(w, "the cluster ID of the record does not match the path", http.StatusBadRequest)
+		return
+	}
+	if err := record.Verify(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	withdraw := req.Method == http.MethodDelete
+	if withdraw != (record.Ttl == 0) {
+		http.Error(w, "only records with zero TTL can withdraw a cluster", http.StatusBadRequest)
+		return
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	key, pinned := h.keys[clusterID]
+	if pinned && !bytes.Equal(key, record.PublicKey) {
+		http.Error(w, "the cluster is registered with another key", http.StatusForbidden)
+		return
+	}
+	if old, ok := h.records[clusterID]; ok && !old.IsExpired() && record.Timestamp < old.Timestamp {
+		http.Error(w, "the record is older than the registered one", http.StatusConflict)
+		return
+	}
+	if withdraw {
+		// the key stays pinned, the cluster can be published again only with the same key
+		delete(h.records, clusterID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !pinned {
+		if err := h.store.Pin(clusterID, record.PublicKey); err != nil {
+			klog.Error(err)
+			http.Error(w, "unable to register the key of the cluster", http.StatusInternalServerError)
+			return
+		}
+		h.keys[clusterID] = record.PublicKey
+	}
+	h.records[clusterID] = &record
+	w.WriteHeader(http.StatusNoContent)
+}
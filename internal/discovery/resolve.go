@@ -20,7 +20,19 @@ func (discovery *DiscoveryCtrl) StartResolver(stopChan <-chan bool) {
 }
 
 func (discovery *DiscoveryCtrl) Resolve(ctx context.Context, service string, domain string, stopChan <-chan bool, resultChan chan *TxtData) {
-	resolver, err := zeroconf.NewResolver(zeroconf.SelectIPTraffic(zeroconf.IPv4))
+	options := []zeroconf.ClientOption{zeroconf.SelectIPTraffic(zeroconf.IPv4)}
+	if discovery.Config.Interfaces != nil {
+		ifaces := discovery.getInterfaces()
+		if len(ifaces) == 0 {
+			// the selection has to be respected, not falling back to all the interfaces;
+			// the interfaces will be checked again when the resolver is restarted
+			klog.Error(errNoInterface)
+			discovery.waitResolver(ctx, stopChan)
+			return
+		}
+		options = append(options, zeroconf.SelectIfaces(ifaces))
+	}
+	resolver, err := zeroconf.NewResolver(options...)
 	if err != nil {
 		klog.Error(err, err.Error())
 		os.Exit(1)
@@ -52,6 +64,10 @@ func (discovery *DiscoveryCtrl) Resolve(ctx context.Context, service string, dom
 		klog.Error(err, err.Error())
 		os.Exit(1)
 	}
+	discovery.waitResolver(ctx, stopChan)
+}
+
+func (discovery *DiscoveryCtrl) waitResolver(ctx context.Context, stopChan <-chan bool) {
 	select {
 	case <-stopChan:
 		return
@@ -0,0 +1,329 @@
+package search_domain_operator
+
+import (
+	"errors"
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/miekg/dns"
+	"k8s.io/klog"
+	"net"
+	"strings"
+	"time"
+)
+
+// DnsResolver performs the DNS queries of the WAN discovery and, if configured, validates their answers
+// the answers are validated locally, checking their DNSSEC signatures up to a trust anchor, or by the DNS server,
+// which has to set the Authenticated Data flag on answers authenticated with TSIG
+type DnsResolver struct {
+	client  *dns.Client
+	address string
+
+	trustAnchors  []dns.RR
+	tsigKeyName   string
+	tsigAlgorithm string
+
+	// DNSKEYs already validated, indexed by zone
+	validatedKeys map[string][]*dns.DNSKEY
+}
+
+// NewDnsResolver creates a resolver querying the given DNS server, or the first server in /etc/resolv.conf if dnsAddr is empty
+// tsigSecret is the base64 encoded secret of the TSIG key, used only if config contains a TSIG configuration
+func NewDnsResolver(dnsAddr string, config *configv1alpha1.DnssecConfig, tsigSecret string) (*DnsResolver, error) {
+	if dnsAddr == "" {
+		clientConfig, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		if len(clientConfig.Servers) == 0 {
+			err = errors.New("no DNS server config found")
+			klog.Error(err)
+			return nil, err
+		}
+		dnsAddr = net.JoinHostPort(clientConfig.Servers[0], "53")
+	}
+
+	r := &DnsResolver{
+		client:        new(dns.Client),
+		address:       dnsAddr,
+		validatedKeys: map[string][]*dns.DNSKEY{},
+	}
+	r.client.DialTimeout = 30 * time.Second
+
+	if config == nil {
+		return r, nil
+	}
+	for _, anchor := range config.TrustAnchors {
+		rr, err := dns.NewRR(anchor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust anchor %v: %w", anchor, err)
+		}
+		switch rr.(type) {
+		case *dns.DS, *dns.DNSKEY:
+			r.trustAnchors = append(r.trustAnchors, rr)
+		default:
+			return nil, fmt.Errorf("invalid trust anchor %v: only DS and DNSKEY records are allowed", anchor)
+		}
+	}
+	if config.Tsig != nil {
+		if config.Tsig.KeyName == "" || tsigSecret == "" {
+			return nil, errors.New("TSIG key name and secret are required")
+		}
+		algorithm := config.Tsig.Algorithm
+		if algorithm == "" {
+			algorithm = dns.HmacSHA256
+		}
+		r.tsigKeyName = strings.ToLower(dns.Fqdn(config.Tsig.KeyName))
+		r.tsigAlgorithm = dns.Fqdn(algorithm)
+		r.client.TsigSecret = map[string]string{r.tsigKeyName: tsigSecret}
+	}
+	return r, nil
+}
+
+// CanValidate returns true if the resolver is configured to validate the answers
+func (r *DnsResolver) CanValidate() bool {
+	return len(r.trustAnchors) > 0 || r.tsigKeyName != ""
+}
+
+// Query returns the records of the given type for a name, and whether the whole answer has been validated
+func (r *DnsResolver) Query(name string, qType uint16) ([]dns.RR, bool, error) {
+	in, err := r.exchange(name, qType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var records []dns.RR
+	for _, ans := range in.Answer {
+		if ans.Header().Rrtype == qType {
+			records = append(records, ans)
+		}
+	}
+
+	validated := false
+	switch {
+	case len(r.trustAnchors) > 0:
+		if err = r.verifyAnswer(in.Answer); err != nil {
+			klog.Warningf("DNSSEC validation of %v records for %v failed: %v", dns.TypeToString[qType], name, err)
+		} else {
+			validated = true
+		}
+	case r.tsigKeyName != "":
+		// the DNS server is authenticated by TSIG: trust its validation
+		validated = in.AuthenticatedData
+	}
+	return records, validated, nil
+}
+
+func (r *DnsResolver) exchange(name string, qType uint16) (*dns.Msg, error) {
+	msg := GetDnsMsg(name, qType)
+	if r.CanValidate() {
+		// ask for the DNSSEC records
+		msg.SetEdns0(4096, true)
+	}
+	if r.tsigKeyName != "" {
+		msg.SetTsig(r.tsigKeyName, r.tsigAlgorithm, 300, time.Now().Unix())
+	}
+
+	in, _, err := r.client.Exchange(msg, r.address)
+	if err == nil && in.Truncated {
+		// the answer does not fit in a UDP message: retry over TCP
+		tcpClient := &dns.Client{
+			Net:         "tcp",
+			DialTimeout: r.client.DialTimeout,
+			TsigSecret:  r.client.TsigSecret,
+		}
+		in, _, err = tcpClient.Exchange(msg, r.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.tsigKeyName != "" && in.IsTsig() == nil {
+		// the client only checks the signature of signed answers
+		return nil, fmt.Errorf("the answer for %v is not signed with TSIG", name)
+	}
+	return in, nil
+}
+
+// verify every RRset in the answer, including the CNAMEs
+func (r *DnsResolver) verifyAnswer(answer []dns.RR) error {
+	type rrsetKey struct {
+		name   string
+		rrtype uint16
+	}
+	var keys []rrsetKey
+	rrsets := map[rrsetKey][]dns.RR{}
+	sigs := map[rrsetKey][]*dns.RRSIG{}
+	for _, rr := range answer {
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			key := rrsetKey{strings.ToLower(sig.Hdr.Name), sig.TypeCovered}
+			sigs[key] = append(sigs[key], sig)
+			continue
+		}
+		key := rrsetKey{strings.ToLower(rr.Header().Name), rr.Header().Rrtype}
+		if _, ok := rrsets[key]; !ok {
+			keys = append(keys, key)
+		}
+		rrsets[key] = append(rrsets[key], rr)
+	}
+	if len(keys) == 0 {
+		return errors.New("empty answer")
+	}
+	for _, key := range keys {
+		if err := r.verifyRRset(rrsets[key], sigs[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify that a RRset is signed by a validated key of one of the zones it belongs to
+func (r *DnsResolver) verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG) error {
+	if len(rrset) == 0 {
+		return errors.New("empty RRset")
+	}
+	owner := rrset[0].Header().Name
+	if len(sigs) == 0 {
+		return fmt.Errorf("no RRSIG found for %v %v", owner, dns.TypeToString[rrset[0].Header().Rrtype])
+	}
+
+	err := fmt.Errorf("no valid RRSIG found for %v %v", owner, dns.TypeToString[rrset[0].Header().Rrtype])
+	for _, sig := range sigs {
+		if !dns.IsSubDomain(sig.SignerName, owner) {
+			// a zone can only sign its own records
+			continue
+		}
+		if sig.TypeCovered == dns.TypeDS && strings.EqualFold(sig.SignerName, owner) {
+			// a DS record has to be signed by the parent zone
+			continue
+		}
+		if !sig.ValidityPeriod(time.Now()) {
+			continue
+		}
+		keys, keyErr := r.getZoneKeys(sig.SignerName)
+		if keyErr != nil {
+			err = keyErr
+			continue
+		}
+		for _, key := range keys {
+			if key.KeyTag() == sig.KeyTag && key.Algorithm == sig.Algorithm && sig.Verify(key, rrset) == nil {
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// get the DNSKEYs of a zone, after having validated them through the chain of trust
+// the key signing keys of a zone are trusted if they match a trust anchor, or a DS record validated in the parent zone
+func (r *DnsResolver) getZoneKeys(zone string) ([]*dns.DNSKEY, error) {
+	zone = strings.ToLower(dns.Fqdn(zone))
+	if keys, ok := r.validatedKeys[zone]; ok {
+		return keys, nil
+	}
+
+	in, err := r.exchange(zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*dns.DNSKEY
+	var keySet []dns.RR
+	var keySigs []*dns.RRSIG
+	for _, ans := range in.Answer {
+		if !strings.EqualFold(ans.Header().Name, zone) {
+			continue
+		}
+		switch rr := ans.(type) {
+		case *dns.DNSKEY:
+			keys = append(keys, rr)
+			keySet = append(keySet, rr)
+		case *dns.RRSIG:
+			if rr.TypeCovered == dns.TypeDNSKEY {
+				keySigs = append(keySigs, rr)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no DNSKEY found for zone %v", zone)
+	}
+
+	var entryPoints []*dns.DNSKEY
+	if anchors := r.getTrustAnchors(zone); len(anchors) > 0 {
+		entryPoints = matchKeys(keys, anchors)
+	} else {
+		if zone == "." {
+			return nil, errors.New("no trust anchor found in the chain of trust")
+		}
+		dsIn, err := r.exchange(zone, dns.TypeDS)
+		if err != nil {
+			return nil, err
+		}
+		var dsSet []dns.RR
+		var dsSigs []*dns.RRSIG
+		for _, ans := range dsIn.Answer {
+			if !strings.EqualFold(ans.Header().Name, zone) {
+				continue
+			}
+			switch rr := ans.(type) {
+			case *dns.DS:
+				dsSet = append(dsSet, rr)
+			case *dns.RRSIG:
+				if rr.TypeCovered == dns.TypeDS {
+					dsSigs = append(dsSigs, rr)
+				}
+			}
+		}
+		if err = r.verifyRRset(dsSet, dsSigs); err != nil {
+			return nil, err
+		}
+		entryPoints = matchKeys(keys, dsSet)
+	}
+	if len(entryPoints) == 0 {
+		return nil, fmt.Errorf("no DNSKEY of zone %v matches the chain of trust", zone)
+	}
+
+	// the DNSKEY RRset has to be signed by one of the trusted keys
+	for _, sig := range keySigs {
+		if !sig.ValidityPeriod(time.Now()) {
+			continue
+		}
+		for _, key := range entryPoints {
+			if key.KeyTag() == sig.KeyTag && key.Algorithm == sig.Algorithm && sig.Verify(key, keySet) == nil {
+				r.validatedKeys[zone] = keys
+				return keys, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("the DNSKEY RRset of zone %v is not signed by a trusted key", zone)
+}
+
+func (r *DnsResolver) getTrustAnchors(zone string) []dns.RR {
+	var anchors []dns.RR
+	for _, anchor := range r.trustAnchors {
+		if strings.EqualFold(anchor.Header().Name, zone) {
+			anchors = append(anchors, anchor)
+		}
+	}
+	return anchors
+}
+
+// get the keys matching at least one of the given DS or DNSKEY records
+func matchKeys(keys []*dns.DNSKEY, anchors []dns.RR) []*dns.DNSKEY {
+	var matching []*dns.DNSKEY
+	for _, key := range keys {
+		for _, anchor := range anchors {
+			match := false
+			switch a := anchor.(type) {
+			case *dns.DS:
+				ds := key.ToDS(a.DigestType)
+				match = ds != nil && ds.KeyTag == a.KeyTag && ds.Algorithm == a.Algorithm && strings.EqualFold(ds.Digest, a.Digest)
+			case *dns.DNSKEY:
+				match = key.Flags == a.Flags && key.Protocol == a.Protocol && key.Algorithm == a.Algorithm && key.PublicKey == a.PublicKey
+			}
+			if match {
+				matching = append(matching, key)
+				break
+			}
+		}
+	}
+	return matching
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	"github.com/liqotech/liqo/internal/discovery"
+	"github.com/liqotech/liqo/internal/discovery/registry"
 	"github.com/liqotech/liqo/pkg/crdClient"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -51,7 +52,7 @@ func (r *SearchDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 
 	update := false
 
-	txts, err := Wan(r.DnsAddress, sd.Spec.Domain)
+	txts, err := r.getTxtData(sd)
 	if err != nil {
 		klog.Error(err, err.Error())
 		return ctrl.Result{
@@ -59,7 +60,12 @@ func (r *SearchDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 			RequeueAfter: r.requeueAfter,
 		}, err
 	}
-	fcs := r.DiscoveryCtrl.UpdateForeignWAN(txts, sd)
+	var fcs []*discoveryv1alpha1.ForeignCluster
+	if sd.Spec.Registry != nil {
+		fcs = r.DiscoveryCtrl.UpdateForeignRegistry(txts, sd)
+	} else {
+		fcs = r.DiscoveryCtrl.UpdateForeignWAN(txts, sd)
+	}
 	if len(fcs) > 0 {
 		// new FCs added, so update the list
 		AddToList(sd, ForeignClustersToObjectReferences(fcs))
@@ -119,6 +125,48 @@ func (r *SearchDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 	}, nil
 }
 
+// get the clusters registered in the DNS domain or in the registry of the SearchDomain
+func (r *SearchDomainReconciler) getTxtData(sd *discoveryv1alpha1.SearchDomain) ([]*discovery.TxtData, error) {
+	if sd.Spec.Registry == nil {
+		if sd.Spec.Domain == "" {
+			return nil, errors.New("SearchDomain " + sd.Name + " has neither a domain nor a registry")
+		}
+		resolver, err := r.getResolver()
+		if err != nil {
+			return nil, err
+		}
+		return resolver.Wan(sd.Spec.Domain, sd.Spec.RequireDnssec)
+	}
+
+	records, err := registry.NewClient(sd.Spec.Registry.Url, nil).List(sd.Spec.Registry.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
+	txts := []*discovery.TxtData{}
+	for _, record := range records {
+		txt, err := discovery.TxtDataFromRecord(record)
+		if err != nil {
+			klog.Warningf("invalid record for cluster %v in registry %v: %v", record.ClusterID, sd.Spec.Registry.Url, err)
+			continue
+		}
+		txts = append(txts, txt)
+	}
+	return txts, nil
+}
+
+// get a resolver validating the DNS answers as defined in the DNSSEC configuration
+func (r *SearchDomainReconciler) getResolver() (*DnsResolver, error) {
+	if r.DiscoveryCtrl == nil || r.DiscoveryCtrl.Config == nil {
+		return NewDnsResolver(r.DnsAddress, nil, "")
+	}
+	config := r.DiscoveryCtrl.Config.Dnssec.DeepCopy()
+	tsigSecret, err := r.DiscoveryCtrl.GetTsigSecret(config.Tsig)
+	if err != nil {
+		return nil, err
+	}
+	return NewDnsResolver(r.DnsAddress, config, tsigSecret)
+}
+
 func (r *SearchDomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&discoveryv1alpha1.SearchDomain{}).
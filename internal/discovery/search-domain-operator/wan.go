@@ -5,77 +5,86 @@ import (
 	"github.com/liqotech/liqo/internal/discovery"
 	"github.com/miekg/dns"
 	"k8s.io/klog"
-	"net"
 	"strconv"
-	"time"
 )
 
+// Wan retrieves the clusters registered in the given domain, without validating the DNS answers
 func Wan(dnsAddr string, name string) ([]*discovery.TxtData, error) {
+	resolver, err := NewDnsResolver(dnsAddr, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resolver.Wan(name, false)
+}
+
+// Wan retrieves the clusters registered in the given domain
+// if requireDnssec is set, the clusters whose records cannot be validated are ignored and a PTR answer which cannot be validated
+// is an error, to not delete the clusters already discovered
+func (r *DnsResolver) Wan(name string, requireDnssec bool) ([]*discovery.TxtData, error) {
 	txtData := []*discovery.TxtData{}
 
-	if dnsAddr == "" {
-		clientConfig, err := dns.ClientConfigFromFile("/etc/resolv.conf")
-		if err != nil {
-			klog.Error(err)
-			return nil, err
-		}
-		if len(clientConfig.Servers) == 0 {
-			err = errors.New("no DNS server config found")
-			klog.Error(err)
-			return nil, err
-		}
-		dnsAddr = net.JoinHostPort(clientConfig.Servers[0], "53")
+	if requireDnssec && !r.CanValidate() {
+		err := errors.New("DNSSEC required for domain " + name + ", but neither trust anchors nor TSIG are configured")
+		klog.Error(err)
+		return nil, err
 	}
 
-	c := new(dns.Client)
-	c.DialTimeout = 30 * time.Second
-
 	// PTR query
-	msg := GetDnsMsg(name, dns.TypePTR)
-	in, _, err := c.Exchange(msg, dnsAddr)
+	answer, validated, err := r.Query(name, dns.TypePTR)
 	if err != nil {
 		klog.Error(err, err.Error())
 		return nil, err
 	}
+	if requireDnssec && !validated {
+		err = errors.New("PTR records for " + name + " cannot be validated with DNSSEC")
+		klog.Error(err)
+		return nil, err
+	}
 
-	for _, ans := range in.Answer {
+	for _, ans := range answer {
 		ptr, ok := ans.(*dns.PTR)
 		if !ok {
 			klog.Warning("Not PTR record: ", ans)
 			continue
 		}
-		txt, err := ResolveWan(c, dnsAddr, ptr)
+		txt, err := r.ResolveWan(ptr)
 		if err != nil {
 			klog.Error(err, err.Error())
 			return nil, err
 		}
+		txt.DnssecChecked = r.CanValidate()
+		txt.DnssecValidated = txt.DnssecValidated && validated
+		if requireDnssec && !txt.DnssecValidated {
+			klog.Warningf("records for %v cannot be validated with DNSSEC, ignoring it", ptr.Ptr)
+			continue
+		}
 		txtData = append(txtData, txt)
 	}
 	return txtData, nil
 }
 
-func ResolveWan(c *dns.Client, dnsAddr string, ptr *dns.PTR) (*discovery.TxtData, error) {
+// ResolveWan retrieves the SRV and the TXT records of a cluster
+// the returned data is marked as validated if both the answers have been validated
+func (r *DnsResolver) ResolveWan(ptr *dns.PTR) (*discovery.TxtData, error) {
 	// SRV query
-	msg := GetDnsMsg(ptr.Ptr, dns.TypeSRV)
-	in, _, err := c.Exchange(msg, dnsAddr)
+	answer, srvValidated, err := r.Query(ptr.Ptr, dns.TypeSRV)
 	if err != nil {
 		klog.Error(err, err.Error())
 		return nil, err
 	}
-	if len(in.Answer) == 0 {
+	if len(answer) == 0 {
 		klog.Error("SRV record is not set for " + ptr.Ptr)
 		return nil, errors.New("SRV record is not set for " + ptr.Ptr)
 	}
-	srv := in.Answer[0].(*dns.SRV)
+	srv := answer[0].(*dns.SRV)
 
 	// TXT query
-	msg = GetDnsMsg(ptr.Ptr, dns.TypeTXT)
-	in, _, err = c.Exchange(msg, dnsAddr)
+	answer, txtValidated, err := r.Query(ptr.Ptr, dns.TypeTXT)
 	if err != nil {
 		klog.Error(err, err.Error())
 		return nil, err
 	}
-	txt, err := AnswerToTxt(in.Answer)
+	txt, err := AnswerToTxt(answer)
 	if err != nil {
 		klog.Error(err, err.Error())
 		return nil, err
@@ -87,6 +96,7 @@ func ResolveWan(c *dns.Client, dnsAddr string, ptr *dns.PTR) (*discovery.TxtData
 		return nil, err
 	}
 	txtData.Ttl = srv.Header().Ttl
+	txtData.DnssecValidated = srvValidated && txtValidated
 	return txtData, nil
 }
 
@@ -3,22 +3,57 @@ package discovery
 import (
 	"context"
 	"errors"
+	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/pkg/liqonet"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+// version of the TXT schema written by Encode
+// version 1 records only carry id, name, namespace and url; version 2 adds the capabilities of the cluster
+const TxtVersion = 2
+
+// max length of a character-string in a TXT record
+const maxTxtLength = 255
+
 type TxtData struct {
 	ID        string
 	Name      string
 	Namespace string
 	ApiUrl    string
 	Ttl       uint32
+
+	// capabilities announced by the cluster, TxtVersion is 1 for records without version
+	Capabilities v1alpha1.ClusterCapabilities
+
+	// set by the WAN discovery if the DNS answers have been checked with DNSSEC
+	DnssecChecked bool
+	// set by the WAN discovery if the DNS answers have been successfully validated with DNSSEC
+	DnssecValidated bool
+}
+
+// CanAutoJoin returns true if the cluster retrieved through the SearchDomain can be automatically joined, i.e. if
+// it announces the required capabilities and its records are authenticated: either validated with DNSSEC or signed
+// by a trusted key of the registry.
+// The clusters whose records cannot be checked with DNSSEC are joined only if the SearchDomain explicitly allows it
+func (txtData TxtData) CanAutoJoin(sd *v1alpha1.SearchDomain) bool {
+	if !sd.Spec.AutoJoin || !txtData.Capabilities.Satisfies(sd.Spec.AutoJoinRequirements) {
+		return false
+	}
+	// the records retrieved from a registry are verified against its trusted keys
+	if sd.Spec.Registry != nil || txtData.DnssecValidated {
+		return true
+	}
+	return !txtData.DnssecChecked && sd.Spec.AutoJoinWithoutDnssec
 }
 
 func (txtData TxtData) Encode() ([]string, error) {
 	res := []string{
+		"txtvers=" + strconv.Itoa(TxtVersion),
 		"id=" + txtData.ID,
 		"namespace=" + txtData.Namespace,
 		"url=" + txtData.ApiUrl,
@@ -26,11 +61,47 @@ func (txtData TxtData) Encode() ([]string, error) {
 	if txtData.Name != "" {
 		res = append(res, "name="+txtData.Name)
 	}
-	return res, nil
+	return append(res, txtData.EncodeCapabilities()...), nil
+}
+
+// EncodeCapabilities returns the TXT entries describing the capabilities of the cluster
+// the lists are split in more entries with the same key, if they do not fit in a single one
+func (txtData TxtData) EncodeCapabilities() []string {
+	var res []string
+	caps := txtData.Capabilities
+	if caps.LiqoVersion != "" {
+		res = append(res, "version="+caps.LiqoVersion)
+	}
+	res = append(res, encodeList("tunnels=", caps.TunnelBackends)...)
+	res = append(res, encodeList("apigroups=", caps.ApiGroups)...)
+	if caps.AcceptsPeering != nil {
+		res = append(res, "peering="+strconv.FormatBool(*caps.AcceptsPeering))
+	}
+	return res
+}
+
+func encodeList(prefix string, list []string) []string {
+	var res []string
+	current := ""
+	for _, item := range list {
+		if current != "" && len(prefix)+len(current)+1+len(item) > maxTxtLength {
+			res = append(res, prefix+current)
+			current = ""
+		}
+		if current != "" {
+			current += ","
+		}
+		current += item
+	}
+	if current != "" {
+		res = append(res, prefix+current)
+	}
+	return res
 }
 
 func Decode(address string, port string, data []string) (*TxtData, error) {
 	var res = TxtData{}
+	res.Capabilities.TxtVersion = 1
 	for _, d := range data {
 		if strings.HasPrefix(d, "id=") {
 			res.ID = d[len("id="):]
@@ -41,6 +112,9 @@ func Decode(address string, port string, data []string) (*TxtData, error) {
 		} else if strings.HasPrefix(d, "url=") {
 			// used in LAN discovery
 			res.ApiUrl = d[len("url="):]
+		} else {
+			// unknown keys, added by newer schema versions, are ignored
+			res.DecodeCapability(d)
 		}
 	}
 
@@ -54,6 +128,37 @@ func Decode(address string, port string, data []string) (*TxtData, error) {
 	return &res, nil
 }
 
+// DecodeCapability reads a TXT entry describing a capability of the cluster, it is ignored if the key is unknown
+func (txtData *TxtData) DecodeCapability(d string) {
+	caps := &txtData.Capabilities
+	switch {
+	case strings.HasPrefix(d, "txtvers="):
+		if v, err := strconv.Atoi(d[len("txtvers="):]); err == nil && v > 0 {
+			caps.TxtVersion = v
+		}
+	case strings.HasPrefix(d, "version="):
+		caps.LiqoVersion = d[len("version="):]
+	case strings.HasPrefix(d, "tunnels="):
+		caps.TunnelBackends = append(caps.TunnelBackends, decodeList(d[len("tunnels="):])...)
+	case strings.HasPrefix(d, "apigroups="):
+		caps.ApiGroups = append(caps.ApiGroups, decodeList(d[len("apigroups="):])...)
+	case strings.HasPrefix(d, "peering="):
+		if v, err := strconv.ParseBool(d[len("peering="):]); err == nil {
+			caps.AcceptsPeering = &v
+		}
+	}
+}
+
+func decodeList(value string) []string {
+	var res []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 func (discovery *DiscoveryCtrl) GetTxtData() (*TxtData, error) {
 	apiUrl, err := discovery.GetAPIUrl()
 	if err != nil {
@@ -61,9 +166,10 @@ func (discovery *DiscoveryCtrl) GetTxtData() (*TxtData, error) {
 		return nil, err
 	}
 	txtData := &TxtData{
-		ID:        discovery.ClusterId.GetClusterID(),
-		Namespace: discovery.Namespace,
-		ApiUrl:    apiUrl,
+		ID:           discovery.ClusterId.GetClusterID(),
+		Namespace:    discovery.Namespace,
+		ApiUrl:       apiUrl,
+		Capabilities: discovery.getCapabilities(),
 	}
 	if discovery.Config.ClusterName != "" {
 		txtData.Name = discovery.Config.ClusterName
@@ -71,6 +177,33 @@ func (discovery *DiscoveryCtrl) GetTxtData() (*TxtData, error) {
 	return txtData, nil
 }
 
+// get the capabilities of the local cluster
+func (discovery *DiscoveryCtrl) getCapabilities() v1alpha1.ClusterCapabilities {
+	acceptsPeering := discovery.acceptsPeering
+	caps := v1alpha1.ClusterCapabilities{
+		TxtVersion:     TxtVersion,
+		LiqoVersion:    os.Getenv("LIQO_VERSION"),
+		TunnelBackends: liqonet.TunnelBackends,
+		AcceptsPeering: &acceptsPeering,
+	}
+	groups, err := discovery.crdClient.Client().Discovery().ServerGroups()
+	if err != nil {
+		// the other capabilities can be announced anyway
+		klog.Warning(err)
+		return caps
+	}
+	for _, group := range groups.Groups {
+		if !strings.HasSuffix(group.Name, ".liqo.io") {
+			continue
+		}
+		for _, version := range group.Versions {
+			caps.ApiGroups = append(caps.ApiGroups, version.GroupVersion)
+		}
+	}
+	sort.Strings(caps.ApiGroups)
+	return caps
+}
+
 // get API Server Url for this cluster
 // if APISERVER env variable is set we read it's ip form this variable
 //     (this can be useful on managed k8s services where we have no master node)
@@ -78,26 +211,45 @@ func (discovery *DiscoveryCtrl) GetTxtData() (*TxtData, error) {
 // if APISERVER_PORT env variable is set we use it has port
 // else we fallback to default port
 func (discovery *DiscoveryCtrl) GetAPIUrl() (string, error) {
+	addresses, err := discovery.getAPIAddresses()
+	if err != nil {
+		return "", err
+	}
+	return getURL(addresses[0], getAPIPort()), nil
+}
+
+// get the addresses the API Server can be reached at, the APISERVER env variable or the addresses of the first master
+func (discovery *DiscoveryCtrl) getAPIAddresses() ([]string, error) {
 	address, ok := os.LookupEnv("APISERVER")
-	if !ok || address == "" {
-		nodes, err := discovery.crdClient.Client().CoreV1().Nodes().List(context.TODO(), v1.ListOptions{
-			LabelSelector: "node-role.kubernetes.io/master",
-		})
-		if err != nil {
-			return "", err
-		}
-		if len(nodes.Items) == 0 || len(nodes.Items[0].Status.Addresses) == 0 {
-			err = errors.New("no APISERVER env variable found and no master node found, one of the two values must be present")
-			klog.Error(err)
-			return "", err
-		}
-		address = nodes.Items[0].Status.Addresses[0].Address
+	if ok && address != "" {
+		return []string{address}, nil
+	}
+	nodes, err := discovery.crdClient.Client().CoreV1().Nodes().List(context.TODO(), v1.ListOptions{
+		LabelSelector: "node-role.kubernetes.io/master",
+	})
+	if err != nil {
+		return nil, err
 	}
+	if len(nodes.Items) == 0 || len(nodes.Items[0].Status.Addresses) == 0 {
+		err = errors.New("no APISERVER env variable found and no master node found, one of the two values must be present")
+		klog.Error(err)
+		return nil, err
+	}
+	addresses := make([]string, len(nodes.Items[0].Status.Addresses))
+	for i, addr := range nodes.Items[0].Status.Addresses {
+		addresses[i] = addr.Address
+	}
+	return addresses, nil
+}
 
+func getAPIPort() string {
 	port, ok := os.LookupEnv("APISERVER_PORT")
 	if !ok {
 		port = "6443"
 	}
+	return port
+}
 
-	return "https://" + address + ":" + port, nil
+func getURL(address string, port string) string {
+	return "https://" + address + ":" + port
 }
@@ -19,11 +19,16 @@ package schedulingNodeOperator
 import (
 	"context"
 	"github.com/go-logr/logr"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // SchedulingNodeReconciler reconciles a SchedulingNode object
@@ -35,6 +40,10 @@ type SchedulingNodeReconciler struct {
 
 // +kubebuilder:rbac:groups=scheduling.liqo.io,resources=schedulingnodes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.liqo.io,resources=schedulingnodes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=nodes;pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.liqo.io,resources=foreignclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=sharing.liqo.io,resources=advertisements,verbs=get;list;watch
+// +kubebuilder:rbac:groups=net.liqo.io,resources=tunnelendpoints,verbs=get;list;watch
 
 func (r *SchedulingNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -58,10 +67,29 @@ func (r *SchedulingNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result, err
 
 // SetupWithManager registers the event handler for
 // + node update,create,delete,patch
+// + pod update,create,delete, to update the requested resources of their node
+// + ForeignCluster, Advertisement and TunnelEndpoint update,create,delete, to update the peering status
+// of the virtual nodes of their cluster
 func (r *SchedulingNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Pod{}, podNodeNameField, indexPodByNodeName); err != nil {
+		return err
+	}
+
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Node{}).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(podToNode),
+		}).
+		Watches(&source.Kind{Type: &discoveryv1alpha1.ForeignCluster{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.clusterToNodes(foreignClusterID),
+		}).
+		Watches(&source.Kind{Type: &advtypes.Advertisement{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.clusterToNodes(advertisementClusterID),
+		}).
+		Watches(&source.Kind{Type: &netv1alpha1.TunnelEndpoint{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.clusterToNodes(tunnelEndpointClusterID),
+		}).
 		Complete(r); err != nil {
 		return err
 	}
@@ -4,10 +4,11 @@ import (
 	"context"
 	"github.com/liqotech/liqo/apis/scheduling/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"strings"
 )
 
 // CreateOrUpdateFromNode takes a node and creates a new scheduling Node if the
@@ -32,21 +33,25 @@ func (r *SchedulingNodeReconciler) CreateOrUpdateFromNode(ctx context.Context, n
 // according to the received node
 func (r *SchedulingNodeReconciler) updateSchedulingNode(ctx context.Context, node corev1.Node, sn *v1alpha1.SchedulingNode) error {
 
+	old := sn.DeepCopy()
 	if err := sn.UpdateFromNode(node); err != nil {
 		return err
 	}
 
 	if l, ok := node.GetLabels()["type"]; ok && l == "virtual-node" {
-		if err := r.setNeighborsFromAdv(sn, ctx, node); err != nil {
+		if err := r.setFromAdv(sn, ctx, node); err != nil {
 			return err
 		}
 	}
 
-	if err := r.Client.Update(ctx, sn); err != nil {
-		return err
+	// the node is reconciled also when its pods change, which does not affect the spec
+	if !equality.Semantic.DeepEqual(old, sn) {
+		if err := r.Client.Update(ctx, sn); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return r.updateStatus(ctx, node, sn)
 }
 
 // createSchedulingNode receives a node and creates a new SchedulingNode CR according
@@ -59,7 +64,7 @@ func (r *SchedulingNodeReconciler) createSchedulingNode(ctx context.Context, nod
 	}
 
 	if l, ok := node.GetLabels()["type"]; ok && l == "virtual-node" {
-		if err := r.setNeighborsFromAdv(&sn, ctx, node); err != nil {
+		if err := r.setFromAdv(&sn, ctx, node); err != nil {
 			return err
 		}
 	}
@@ -68,21 +73,37 @@ func (r *SchedulingNodeReconciler) createSchedulingNode(ctx context.Context, nod
 		return err
 	}
 
-	return nil
+	// the status is ignored on creation
+	return r.updateStatus(ctx, node, &sn)
 }
 
-func (r *SchedulingNodeReconciler) setNeighborsFromAdv(sn *v1alpha1.SchedulingNode, ctx context.Context, node corev1.Node) error {
+// getAdvertisement returns the Advertisement the virtual node has been created for. The cluster may be mapped on
+// several virtual nodes, one per partition, hence the Advertisement is named after the cluster id of the node
+func (r *SchedulingNodeReconciler) getAdvertisement(ctx context.Context, node corev1.Node) (*advtypes.Advertisement, error) {
 	var adv advtypes.Advertisement
 
 	advName := types.NamespacedName{
 		Namespace: "",
-		Name:      strings.Replace(node.Name, "liqo", "advertisement", 1),
+		Name:      virtualKubelet.AdvertisementPrefix + virtualKubelet.VirtualNodeClusterID(&node),
 	}
 
 	if err := r.Client.Get(ctx, advName, &adv); err != nil {
+		return nil, err
+	}
+	return &adv, nil
+}
+
+func (r *SchedulingNodeReconciler) setFromAdv(sn *v1alpha1.SchedulingNode, ctx context.Context, node corev1.Node) error {
+	adv, err := r.getAdvertisement(ctx, node)
+	if apierrors.IsNotFound(err) {
+		// the peering is being torn down
+		return nil
+	} else if err != nil {
 		return err
 	}
 
+	sn.Spec.Prices = adv.Spec.Prices.DeepCopy()
+
 	if adv.Spec.Neighbors == nil {
 		return nil
 	}
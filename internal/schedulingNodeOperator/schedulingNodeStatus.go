@@ -0,0 +1,182 @@
+package schedulingNodeOperator
+
+import (
+	"context"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/apis/scheduling/v1alpha1"
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// the field the pods are indexed by in the cache of the manager
+const podNodeNameField = "spec.nodeName"
+
+func indexPodByNodeName(obj runtime.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// updateStatus sets the status of the SchedulingNode from the node, the pods scheduled on it and, for the
+// virtual nodes, the Advertisement and the ForeignCluster of the backing cluster. The status is not updated
+// if nothing has changed.
+func (r *SchedulingNodeReconciler) updateStatus(ctx context.Context, node corev1.Node, sn *v1alpha1.SchedulingNode) error {
+	status := v1alpha1.SchedulingNodeStatus{
+		Allocatable:   node.Status.Allocatable.DeepCopy(),
+		Ready:         isNodeReady(node),
+		Unschedulable: node.Spec.Unschedulable,
+	}
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.MatchingFields{podNodeNameField: node.Name}); err != nil {
+		return err
+	}
+	status.Requested = corev1.ResourceList{}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodSucceeded || pods.Items[i].Status.Phase == corev1.PodFailed {
+			continue
+		}
+		status.Pods++
+		requests, _ := resourcehelper.PodRequestsAndLimits(&pods.Items[i])
+		for name, quantity := range requests {
+			if value, ok := status.Requested[name]; ok {
+				value.Add(quantity)
+				status.Requested[name] = value
+			} else {
+				status.Requested[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	if isVirtualNode(node) {
+		if err := r.setPeeringStatus(ctx, node, &status); err != nil {
+			return err
+		}
+	}
+
+	if equality.Semantic.DeepEqual(status, sn.Status) {
+		return nil
+	}
+	sn.Status = status
+	return r.Client.Status().Update(ctx, sn)
+}
+
+// setPeeringStatus sets the state of the peering with the foreign cluster backing the virtual node
+func (r *SchedulingNodeReconciler) setPeeringStatus(ctx context.Context, node corev1.Node, status *v1alpha1.SchedulingNodeStatus) error {
+	adv, err := r.getAdvertisement(ctx, node)
+	if apierrors.IsNotFound(err) {
+		// the Advertisement is deleted when the peering is torn down, while the virtual node may still exist
+		return nil
+	} else if err != nil {
+		return err
+	}
+	timestamp := adv.Spec.Timestamp
+	status.LastAdvertisementTime = &timestamp
+	status.Peering = &v1alpha1.PeeringStatus{
+		ClusterID:           adv.Spec.ClusterId,
+		AdvertisementStatus: string(adv.Status.AdvertisementStatus),
+	}
+
+	// the ForeignCluster may have been deleted while the virtual node is drained
+	var fc discoveryv1alpha1.ForeignCluster
+	if err = r.Client.Get(ctx, types.NamespacedName{Name: adv.Spec.ClusterId}, &fc); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	status.Peering.OutgoingJoined = fc.Status.Outgoing.Joined
+	status.Peering.IncomingJoined = fc.Status.Incoming.Joined
+
+	tunnel := fc.Status.Network.TunnelEndpoint
+	if !tunnel.Available || tunnel.Reference == nil {
+		return nil
+	}
+	status.Peering.TunnelAvailable = true
+	var tep netv1alpha1.TunnelEndpoint
+	if err = r.Client.Get(ctx, types.NamespacedName{Name: tunnel.Reference.Name}, &tep); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	status.Peering.TunnelPhase = tep.Status.Phase
+	return nil
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isVirtualNode(node corev1.Node) bool {
+	l, ok := node.GetLabels()["type"]
+	return ok && l == "virtual-node"
+}
+
+// podToNode enqueues the node the pod is scheduled on, to update its requested resources
+func podToNode(obj handler.MapObject) []reconcile.Request {
+	pod, ok := obj.Object.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+}
+
+// clusterToNodes returns a function enqueuing the virtual nodes of the cluster of the ForeignCluster, the
+// Advertisement or the TunnelEndpoint
+func (r *SchedulingNodeReconciler) clusterToNodes(clusterID func(runtime.Object) string) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		id := clusterID(obj.Object)
+		if id == "" {
+			return nil
+		}
+		var nodes corev1.NodeList
+		if err := r.Client.List(context.Background(), &nodes, client.MatchingLabels{"type": "virtual-node"}); err != nil {
+			r.Log.Error(err, "unable to list the virtual nodes", "clusterID", id)
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range nodes.Items {
+			if virtualKubelet.IsVirtualNodeOf(&nodes.Items[i], id) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: nodes.Items[i].Name}})
+			}
+		}
+		return requests
+	}
+}
+
+func foreignClusterID(obj runtime.Object) string {
+	if fc, ok := obj.(*discoveryv1alpha1.ForeignCluster); ok {
+		return fc.Spec.ClusterIdentity.ClusterID
+	}
+	return ""
+}
+
+func advertisementClusterID(obj runtime.Object) string {
+	if adv, ok := obj.(*advtypes.Advertisement); ok {
+		return adv.Spec.ClusterId
+	}
+	return ""
+}
+
+func tunnelEndpointClusterID(obj runtime.Object) string {
+	if tep, ok := obj.(*netv1alpha1.TunnelEndpoint); ok {
+		return tep.Spec.ClusterID
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package schedulingNodeOperator
+
+import (
+	"context"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/apis/scheduling/v1alpha1"
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"testing"
+	"time"
+)
+
+const clusterID = "cluster-1"
+
+func createFakePod(name string, phase corev1.PodPhase, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "liqo-" + clusterID,
+			Containers: []corev1.Container{
+				{
+					Name: "test",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse(cpu),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func TestSchedulingNodeStatus(t *testing.T) {
+	// the fake client decodes the objects with the client-go scheme
+	assert.Nil(t, v1alpha1.AddToScheme(scheme.Scheme))
+	assert.Nil(t, advtypes.AddToScheme(scheme.Scheme))
+	assert.Nil(t, discoveryv1alpha1.AddToScheme(scheme.Scheme))
+	assert.Nil(t, netv1alpha1.AddToScheme(scheme.Scheme))
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "liqo-" + clusterID,
+			Labels: map[string]string{"type": "virtual-node"},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	timestamp := metav1.NewTime(time.Now().Truncate(time.Second))
+	adv := &advtypes.Advertisement{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "advertisement-" + clusterID,
+		},
+		Spec: advtypes.AdvertisementSpec{
+			ClusterId: clusterID,
+			Timestamp: timestamp,
+		},
+		Status: advtypes.AdvertisementStatus{
+			AdvertisementStatus: advtypes.AdvertisementAccepted,
+		},
+	}
+	fc := &discoveryv1alpha1.ForeignCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterID,
+		},
+		Spec: discoveryv1alpha1.ForeignClusterSpec{
+			ClusterIdentity: discoveryv1alpha1.ClusterIdentity{ClusterID: clusterID},
+		},
+	}
+	fc.Status.Outgoing.Joined = true
+	fc.Status.Network.TunnelEndpoint = discoveryv1alpha1.ResourceLink{
+		Available: true,
+		Reference: &corev1.ObjectReference{Name: "tep-" + clusterID},
+	}
+	tep := &netv1alpha1.TunnelEndpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tep-" + clusterID,
+		},
+		Spec: netv1alpha1.TunnelEndpointSpec{
+			ClusterID: clusterID,
+		},
+		Status: netv1alpha1.TunnelEndpointStatus{
+			Phase: "Ready",
+		},
+	}
+
+	r := &SchedulingNodeReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, node, adv, fc, tep,
+			createFakePod("running", corev1.PodRunning, "500m"),
+			createFakePod("pending", corev1.PodPending, "250m"),
+			createFakePod("succeeded", corev1.PodSucceeded, "1")),
+		Log:    ctrl.Log.WithName("test"),
+		Scheme: scheme.Scheme,
+	}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	assert.Nil(t, err)
+
+	sn := &v1alpha1.SchedulingNode{}
+	assert.Nil(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name}, sn))
+	assert.True(t, sn.Status.Ready)
+	assert.Equal(t, int32(2), sn.Status.Pods, "the terminated pods should not be counted")
+	requested := sn.Status.Requested[corev1.ResourceCPU]
+	assert.Equal(t, int64(750), requested.MilliValue())
+	allocatable := sn.Status.Allocatable[corev1.ResourceCPU]
+	assert.Equal(t, int64(4), allocatable.Value())
+	assert.True(t, timestamp.Equal(sn.Status.LastAdvertisementTime))
+	assert.Equal(t, &v1alpha1.PeeringStatus{
+		ClusterID:           clusterID,
+		OutgoingJoined:      true,
+		AdvertisementStatus: string(advtypes.AdvertisementAccepted),
+		TunnelAvailable:     true,
+		TunnelPhase:         "Ready",
+	}, sn.Status.Peering)
+
+	// the status follows the health of the virtual node
+	node.Status.Conditions[0].Status = corev1.ConditionUnknown
+	assert.Nil(t, r.Client.Update(context.TODO(), node))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	assert.Nil(t, err)
+	assert.Nil(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name}, sn))
+	assert.False(t, sn.Status.Ready)
+
+	requests := r.clusterToNodes(foreignClusterID)(handler.MapObject{Meta: fc, Object: fc})
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, node.Name, requests[0].Name)
+
+	// the partitioned virtual nodes are resolved through their cluster id label
+	partition := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "liqo-" + clusterID + "-zone-a",
+			Labels: map[string]string{"type": "virtual-node", "liqo.io/remote-cluster-id": clusterID},
+		},
+	}
+	assert.Nil(t, r.Client.Create(context.TODO(), partition))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: partition.Name}})
+	assert.Nil(t, err)
+	assert.Nil(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: partition.Name}, sn))
+	assert.NotNil(t, sn.Status.Peering)
+	assert.Equal(t, clusterID, sn.Status.Peering.ClusterID)
+
+	// the Advertisement is deleted when the peering is torn down
+	assert.Nil(t, r.Client.Delete(context.TODO(), adv))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: partition.Name}})
+	assert.Nil(t, err)
+	sn = &v1alpha1.SchedulingNode{}
+	assert.Nil(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: partition.Name}, sn))
+	assert.Nil(t, sn.Status.Peering)
+}
@@ -15,7 +15,8 @@ import (
 )
 
 // create deployment for a virtual-kubelet
-func CreateVkDeployment(adv *advtypes.Advertisement, vkName, vkNamespace, vkImage, initVKImage, nodeName, homeClusterId string) *appsv1.Deployment {
+// the partition identifies the foreign nodes mapped on the virtual node: its zero value maps the whole foreign cluster
+func CreateVkDeployment(adv *advtypes.Advertisement, partition VirtualNodePartition, vkName, vkNamespace, vkImage, initVKImage, nodeName, homeClusterId string) *appsv1.Deployment {
 
 	command := []string{
 		"/usr/bin/virtual-kubelet",
@@ -31,10 +32,16 @@ func CreateVkDeployment(adv *advtypes.Advertisement, vkName, vkNamespace, vkImag
 		"--kubelet-namespace",
 		vkNamespace,
 		"--foreign-kubeconfig",
-		"/app/kubeconfig/remote",
+		"/app/kubeconfig/remote/kubeconfig",
 		"--home-cluster-id",
 		homeClusterId,
 	}
+	if partition.Zone != "" {
+		args = append(args, "--remote-zone", partition.Zone)
+	}
+	if partition.NodePool != "" {
+		args = append(args, "--remote-node-pool", partition.NodePool)
+	}
 
 	volumes := []v1.Volume{
 		{
@@ -56,8 +63,8 @@ func CreateVkDeployment(adv *advtypes.Advertisement, vkName, vkNamespace, vkImag
 	volumeMounts := []v1.VolumeMount{
 		{
 			Name:      "remote-kubeconfig",
+			// the whole secret is mounted, files mounted through a subPath are not updated when the credentials are rotated
 			MountPath: "/app/kubeconfig/remote",
-			SubPath:   "kubeconfig",
 		},
 		{
 			Name:      "virtual-kubelet-crt",
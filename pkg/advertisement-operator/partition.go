@@ -0,0 +1,66 @@
+package advertisementOperator
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+)
+
+// VirtualNodePartition identifies the subset of the foreign nodes mapped on a virtual node
+// the zero value maps the whole foreign cluster on a single virtual node
+type VirtualNodePartition struct {
+	// Zone is the name of the foreign zone mapped on the virtual node
+	Zone string
+	// NodePool is the name of the foreign node pool mapped on the virtual node
+	NodePool string
+}
+
+// get the name of the zone or of the node pool of the partition
+func (p VirtualNodePartition) Name() string {
+	if p.Zone != "" {
+		return p.Zone
+	}
+	return p.NodePool
+}
+
+// get the name of the virtual-kubelet deployment for the partition
+func (p VirtualNodePartition) VirtualKubeletName(clusterId string) string {
+	return virtualKubelet.VirtualKubeletName(clusterId, p.Name())
+}
+
+// get the name of the virtual node for the partition
+func (p VirtualNodePartition) VirtualNodeName(clusterId string) string {
+	return virtualKubelet.VirtualNodeName(clusterId, p.Name())
+}
+
+// get the partitions of the foreign cluster, each one to be mapped on a different virtual node
+// if the Advertisement does not announce any zone or node pool, a single partition containing the whole cluster is returned
+func GetVirtualNodePartitions(adv *advtypes.Advertisement, split configv1alpha1.VirtualNodeSplit) []VirtualNodePartition {
+	var partitions []VirtualNodePartition
+	// different names could be mapped on the same suffix: keep only the first one
+	seen := make(map[string]bool)
+	add := func(p VirtualNodePartition) {
+		suffix := virtualKubelet.PartitionSuffix(p.Name())
+		if suffix == "" || seen[suffix] {
+			return
+		}
+		seen[suffix] = true
+		partitions = append(partitions, p)
+	}
+
+	switch split {
+	case configv1alpha1.SplitByZone:
+		for _, zone := range adv.Spec.Topology.Zones {
+			add(VirtualNodePartition{Zone: zone.Zone})
+		}
+	case configv1alpha1.SplitByNodePool:
+		for _, pool := range adv.Spec.Topology.NodePools {
+			add(VirtualNodePartition{NodePool: pool.Name})
+		}
+	}
+
+	if len(partitions) == 0 {
+		return []VirtualNodePartition{{}}
+	}
+	return partitions
+}
@@ -1,6 +1,8 @@
 package clusterConfig
 
 import (
+	"context"
+	goerrors "errors"
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/pkg/crdClient"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -11,8 +13,68 @@ import (
 	"time"
 )
 
+// GetConfiguration returns the ClusterConfig of the local cluster
+func GetConfiguration(client *crdClient.CRDClient, kubeconfigPath string) (*configv1alpha1.ClusterConfig, error) {
+	if client == nil {
+		config, err := crdClient.NewKubeconfig(kubeconfigPath, &configv1alpha1.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		client, err = crdClient.NewFromConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tmp, err := client.Resource("clusterconfigs").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	configurations, ok := tmp.(*configv1alpha1.ClusterConfigList)
+	if !ok {
+		return nil, goerrors.New("retrieved object is not a ClusterConfigList")
+	}
+	if len(configurations.Items) == 0 {
+		return nil, goerrors.New("no ClusterConfig found")
+	}
+	return &configurations.Items[0], nil
+}
+
 func WatchConfiguration(handler func(*configv1alpha1.ClusterConfig), client *crdClient.CRDClient, kubeconfigPath string) {
 	var rsyncPeriod = 1 * time.Second
+	client = getConfigurationClient(client, kubeconfigPath)
+
+	var err error
+	lo := metav1.ListOptions{}
+	client.Store, client.Stop, err = crdClient.WatchResources(client,
+		"clusterconfigs", "",
+		rsyncPeriod, configurationHandlers(handler, client), lo)
+	if err != nil {
+		klog.Error(err)
+		os.Exit(1)
+	}
+	klog.Info("Cluster Config Informer initialized")
+
+}
+
+// WatchConfigurationContext is the context-aware variant of WatchConfiguration: the informer is stopped and its
+// requests are canceled when the context is done
+func WatchConfigurationContext(ctx context.Context, handler func(*configv1alpha1.ClusterConfig), client *crdClient.CRDClient, kubeconfigPath string) {
+	var rsyncPeriod = 1 * time.Second
+	client = getConfigurationClient(client, kubeconfigPath)
+
+	var err error
+	lo := metav1.ListOptions{}
+	client.Store, err = crdClient.WatchResourcesContext(ctx, client,
+		"clusterconfigs", "",
+		rsyncPeriod, configurationHandlers(handler, client), lo)
+	if err != nil {
+		klog.Error(err)
+		os.Exit(1)
+	}
+	klog.Info("Cluster Config Informer initialized")
+}
+
+func getConfigurationClient(client *crdClient.CRDClient, kubeconfigPath string) *crdClient.CRDClient {
 	if client == nil {
 		config, err := crdClient.NewKubeconfig(kubeconfigPath, &configv1alpha1.GroupVersion)
 		if err != nil {
@@ -26,10 +88,11 @@ func WatchConfiguration(handler func(*configv1alpha1.ClusterConfig), client *crd
 			os.Exit(1)
 		}
 	}
+	return client
+}
 
-	var err error
-
-	ehf := cache.ResourceEventHandlerFuncs{
+func configurationHandlers(handler func(*configv1alpha1.ClusterConfig), client *crdClient.CRDClient) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			configuration, ok := obj.(*configv1alpha1.ClusterConfig)
 			if !ok {
@@ -50,20 +113,10 @@ func WatchConfiguration(handler func(*configv1alpha1.ClusterConfig), client *crd
 			klog.Error("please, do not delete ClusterConfigs")
 			configuration := config.(*configv1alpha1.ClusterConfig)
 			configuration.ResourceVersion = ""
-			_, err = client.Resource("clusterconfigs").Create(configuration, metav1.CreateOptions{})
+			_, err := client.Resource("clusterconfigs").Create(configuration, metav1.CreateOptions{})
 			if err != nil && !errors.IsAlreadyExists(err) {
 				klog.Error(err, err.Error())
 			}
 		},
 	}
-	lo := metav1.ListOptions{}
-	client.Store, client.Stop, err = crdClient.WatchResources(client,
-		"clusterconfigs", "",
-		rsyncPeriod, ehf, lo)
-	if err != nil {
-		klog.Error(err)
-		os.Exit(1)
-	}
-	klog.Info("Cluster Config Informer initialized")
-
 }
@@ -1,8 +1,10 @@
 package crdClient
 
 import (
+	"context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
@@ -14,5 +16,16 @@ type CrdClientInterface interface {
 	Watch(opts metav1.ListOptions) (watch.Interface, error)
 	Update(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error)
 	UpdateStatus(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error)
+	Patch(name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error)
 	Delete(name string, opts metav1.DeleteOptions) error
+
+	// the context-aware variants: the requests are canceled when the context is done
+	ListContext(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error)
+	GetContext(ctx context.Context, name string, opts metav1.GetOptions) (runtime.Object, error)
+	CreateContext(ctx context.Context, obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error)
+	WatchContext(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	UpdateContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error)
+	UpdateStatusContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error)
+	PatchContext(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error)
+	DeleteContext(ctx context.Context, name string, opts metav1.DeleteOptions) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -28,6 +29,10 @@ func (c *Client) Namespace(namespace string) CrdClientInterface {
 }
 
 func (c *Client) Get(name string, opts metav1.GetOptions) (runtime.Object, error) {
+	return c.GetContext(context.TODO(), name, opts)
+}
+
+func (c *Client) GetContext(ctx context.Context, name string, opts metav1.GetOptions) (runtime.Object, error) {
 	result := reflect.New(c.resource.SingularType).Interface()
 	var namespaced bool
 	if c.ns != "" {
@@ -40,13 +45,17 @@ func (c *Client) Get(name string, opts metav1.GetOptions) (runtime.Object, error
 		VersionedParams(&opts, scheme.ParameterCodec).
 		NamespaceIfScoped(c.ns, namespaced).
 		Name(name).
-		Do(context.TODO()).
+		Do(ctx).
 		Into(result.(runtime.Object))
 
 	return result.(runtime.Object), err
 }
 
 func (c *Client) List(opts metav1.ListOptions) (runtime.Object, error) {
+	return c.ListContext(context.TODO(), opts)
+}
+
+func (c *Client) ListContext(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
 	result := reflect.New(c.resource.PluralType).Interface()
 	var namespaced bool
 	if c.ns != "" {
@@ -58,13 +67,17 @@ func (c *Client) List(opts metav1.ListOptions) (runtime.Object, error) {
 		Resource(c.api).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		NamespaceIfScoped(c.ns, namespaced).
-		Do(context.TODO()).
+		Do(ctx).
 		Into(result.(runtime.Object))
 
 	return result.(runtime.Object), err
 }
 
 func (c *Client) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.WatchContext(context.TODO(), opts)
+}
+
+func (c *Client) WatchContext(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -82,10 +95,14 @@ func (c *Client) Watch(opts metav1.ListOptions) (watch.Interface, error) {
 		VersionedParams(&opts, scheme.ParameterCodec).
 		NamespaceIfScoped(c.ns, namespaced).
 		Timeout(timeout).
-		Watch(context.TODO())
+		Watch(ctx)
 }
 
 func (c *Client) Create(obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error) {
+	return c.CreateContext(context.TODO(), obj, opts)
+}
+
+func (c *Client) CreateContext(ctx context.Context, obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error) {
 	result := reflect.New(c.resource.SingularType).Interface()
 
 	var namespaced bool
@@ -99,13 +116,17 @@ func (c *Client) Create(obj runtime.Object, opts metav1.CreateOptions) (runtime.
 		VersionedParams(&opts, scheme.ParameterCodec).
 		NamespaceIfScoped(c.ns, namespaced).
 		Body(obj).
-		Do(context.TODO()).
+		Do(ctx).
 		Into(result.(runtime.Object))
 
 	return result.(runtime.Object), err
 }
 
 func (c *Client) Delete(name string, opts metav1.DeleteOptions) error {
+	return c.DeleteContext(context.TODO(), name, opts)
+}
+
+func (c *Client) DeleteContext(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	var namespaced bool
 	if c.ns != "" {
 		namespaced = true
@@ -118,11 +139,15 @@ func (c *Client) Delete(name string, opts metav1.DeleteOptions) error {
 		NamespaceIfScoped(c.ns, namespaced).
 		Name(name).
 		Body(&opts).
-		Do(context.TODO()).
+		Do(ctx).
 		Error()
 }
 
 func (c *Client) Update(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateContext(context.TODO(), name, obj, opts)
+}
+
+func (c *Client) UpdateContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
 	result := reflect.New(c.resource.SingularType).Interface()
 
 	var namespaced bool
@@ -136,13 +161,17 @@ func (c *Client) Update(name string, obj runtime.Object, opts metav1.UpdateOptio
 		NamespaceIfScoped(c.ns, namespaced).
 		Name(name).
 		Body(obj).
-		Do(context.TODO()).
+		Do(ctx).
 		Into(result.(runtime.Object))
 
 	return result.(runtime.Object), err
 }
 
 func (c *Client) UpdateStatus(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateStatusContext(context.TODO(), name, obj, opts)
+}
+
+func (c *Client) UpdateStatusContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
 	result := reflect.New(c.resource.SingularType).Interface()
 
 	var namespaced bool
@@ -157,7 +186,33 @@ func (c *Client) UpdateStatus(name string, obj runtime.Object, opts metav1.Updat
 		Name(name).
 		SubResource("status").
 		Body(obj).
-		Do(context.TODO()).
+		Do(ctx).
+		Into(result.(runtime.Object))
+
+	return result.(runtime.Object), err
+}
+
+func (c *Client) Patch(name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error) {
+	return c.PatchContext(context.TODO(), name, pt, data, opts, subresources...)
+}
+
+// PatchContext supports the merge patches and the server-side apply, the latter requires opts.FieldManager to be set
+func (c *Client) PatchContext(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error) {
+	result := reflect.New(c.resource.SingularType).Interface()
+
+	var namespaced bool
+	if c.ns != "" {
+		namespaced = true
+	}
+
+	err := c.Client.Patch(pt).
+		Resource(c.api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, namespaced).
+		Name(name).
+		SubResource(subresources...).
+		Body(data).
+		Do(ctx).
 		Into(result.(runtime.Object))
 
 	return result.(runtime.Object), err
@@ -11,7 +11,6 @@ import (
 	restFake "k8s.io/client-go/rest/fake"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
-	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"os"
 )
 
@@ -28,6 +27,9 @@ type CRDClient struct {
 	config *rest.Config
 	Store  cache.Store
 	Stop   chan struct{}
+
+	// recorders of the calls performed on the clients of the resources, see Record
+	recorders map[string]*Recorder
 }
 
 func NewKubeconfig(configPath string, gv *schema.GroupVersion) (*rest.Config, error) {
@@ -37,10 +39,19 @@ func NewKubeconfig(configPath string, gv *schema.GroupVersion) (*rest.Config, er
 		// Check if the kubeConfig file exists.
 		if _, err := os.Stat(configPath); !os.IsNotExist(err) {
 			// Get the kubeconfig from the filepath.
-			config, err = clientcmd.BuildConfigFromFlags("", configPath)
+			cnf, err := clientcmd.LoadFromFile(configPath)
+			if err != nil {
+				return nil, errors.Wrap(err, "error loading kubeconfig")
+			}
+			if err = clientcmd.ResolveLocalPaths(cnf); err != nil {
+				return nil, errors.Wrap(err, "error loading kubeconfig")
+			}
+			config, err = failoverConfig(cnf)
 			if err != nil {
 				return nil, errors.Wrap(err, "error building Client config")
 			}
+			// the kubeconfig may be mounted from a Secret whose credentials are rotated
+			reloadTokenFromFile(config, configPath)
 		} else {
 			// Set to in-cluster config.
 			config, err = rest.InClusterConfig()
@@ -61,14 +72,14 @@ func NewKubeconfig(configPath string, gv *schema.GroupVersion) (*rest.Config, er
 }
 
 func NewKubeconfigFromSecret(secret *v1.Secret, gv *schema.GroupVersion) (*rest.Config, error) {
-	var err error
 	config := &rest.Config{}
 
 	if !Fake {
-		// Check if the kubeConfig file exists.
-		config, err = clientcmd.BuildConfigFromKubeconfigGetter("", func() (*clientcmdapi.Config, error) {
-			return clientcmd.Load(secret.Data["kubeconfig"])
-		})
+		cnf, err := clientcmd.Load(secret.Data["kubeconfig"])
+		if err != nil {
+			return nil, err
+		}
+		config, err = failoverConfig(cnf)
 		if err != nil {
 			return nil, err
 		}
@@ -126,6 +137,13 @@ func newFakeFromConfig(config *rest.Config) (*CRDClient, error) {
 }
 
 func (c *CRDClient) Resource(api string) CrdClientInterface {
+	if r, ok := c.recorders[api]; ok {
+		return r.Client(c.resource(api))
+	}
+	return c.resource(api)
+}
+
+func (c *CRDClient) resource(api string) CrdClientInterface {
 	if Fake {
 		return &FakeClient{
 			Client:   c.crdClient,
@@ -143,6 +161,14 @@ func (c *CRDClient) Resource(api string) CrdClientInterface {
 	}
 }
 
+// SetFakeStore sets the Store the fake clients work on, in Fake mode and if it has not been set yet: the Store is
+// shared by the clients of the CRDClient, hence it is not replaced by the caches created afterwards
+func (c *CRDClient) SetFakeStore(store cache.Store) {
+	if Fake && c.Store == nil {
+		c.Store = store
+	}
+}
+
 func (c *CRDClient) Client() kubernetes.Interface {
 	if Fake {
 		return c.client.(*clientsetFake.Clientset)
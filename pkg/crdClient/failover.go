@@ -0,0 +1,404 @@
+package crdClient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// timeout of the connectivity checks of the API servers
+	connectivityTimeout = 5 * time.Second
+	// period the API server selected among the ones of a kubeconfig is used for, before probing them again
+	failoverCacheTTL = 1 * time.Minute
+)
+
+// CheckConnectivity checks that the API server at the given URL is reachable and that its certificate is signed by caData
+// (or by a system CA, if caData is empty). Any HTTP answer, also unauthorized, proves the connectivity
+func CheckConnectivity(server string, caData []byte) error {
+	transport, err := rest.TransportFor(&rest.Config{
+		Host: server,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: caData,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   connectivityTimeout,
+	}
+	resp, err := client.Get(strings.TrimSuffix(server, "/") + "/healthz")
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// failoverConfig builds the rest config of the kubeconfig, whose current context is selected among the reachable ones
+// if it lists more API servers for failover. The clients created from the config keep failing over to the next
+// server, in the configured order, when the one they are using becomes unreachable
+func failoverConfig(cnf *clientcmdapi.Config) (*rest.Config, error) {
+	selectReachableContext(cnf)
+	config, err := clientcmd.NewDefaultClientConfig(*cnf, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if servers := getFailoverServers(cnf); servers != nil {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &failoverRoundTripper{
+				rt:      rt,
+				servers: servers,
+			}
+		})
+	}
+	return config, nil
+}
+
+// the API servers a client can fail over to, the one of the current context first: only the contexts sharing its
+// user and CA can be used, since the client keeps the credentials and the TLS configuration of the current one
+type failoverServers struct {
+	// the identifier of the kubeconfig in the cache of the selected servers
+	key     string
+	servers []string
+	urls    []*url.URL
+
+	mutex sync.Mutex
+	// the index of the server used by the clients
+	current int
+}
+
+// getFailoverServers returns nil if the kubeconfig has no other server the current context can fail over to
+func getFailoverServers(cnf *clientcmdapi.Config) *failoverServers {
+	if len(cnf.Contexts) < 2 {
+		return nil
+	}
+	names := orderedContexts(cnf)
+	current, ok := cnf.Contexts[names[0]]
+	if !ok {
+		return nil
+	}
+	currentCluster, ok := cnf.Clusters[current.Cluster]
+	if !ok {
+		return nil
+	}
+
+	servers := &failoverServers{
+		key: failoverKey(cnf, names),
+	}
+	for _, name := range names {
+		context := cnf.Contexts[name]
+		cluster, ok := cnf.Clusters[context.Cluster]
+		if !ok || context.AuthInfo != current.AuthInfo || !sameCA(cluster, currentCluster) {
+			continue
+		}
+		u, err := url.Parse(cluster.Server)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		servers.servers = append(servers.servers, cluster.Server)
+		servers.urls = append(servers.urls, u)
+	}
+	if len(servers.urls) < 2 {
+		return nil
+	}
+	return servers
+}
+
+func sameCA(a, b *clientcmdapi.Cluster) bool {
+	return bytes.Equal(a.CertificateAuthorityData, b.CertificateAuthorityData) && a.CertificateAuthority == b.CertificateAuthority &&
+		a.InsecureSkipTLSVerify == b.InsecureSkipTLSVerify && a.TLSServerName == b.TLSServerName
+}
+
+func (s *failoverServers) getCurrent() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.current
+}
+
+// the server is also cached, for the clients created from the same kubeconfig to use it
+func (s *failoverServers) setCurrent(index int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.current == index {
+		return
+	}
+	klog.Warningf("API server %v is not reachable, failing over to %v", s.servers[s.current], s.servers[index])
+	s.current = index
+	failoverCache.set(s.key, s.servers[index])
+}
+
+// request returns the request to be sent to the server with the given index, the requests of the clients being
+// built for the first server. A request retried is copied with a new body
+func (s *failoverServers) request(req *http.Request, index int, retry bool) (*http.Request, error) {
+	if index == 0 && !retry {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	if retry && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	if index != 0 {
+		target := s.urls[index]
+		r.URL.Scheme = target.Scheme
+		r.URL.Host = target.Host
+		r.URL.Path = strings.TrimSuffix(target.Path, "/") + strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(s.urls[0].Path, "/"))
+		r.URL.RawPath = ""
+		r.Host = ""
+	}
+	return r, nil
+}
+
+// failoverRoundTripper sends the requests to the server in use, and to the next ones if it is not reachable
+type failoverRoundTripper struct {
+	rt      http.RoundTripper
+	servers *failoverServers
+}
+
+func (f *failoverRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	first := f.servers.getCurrent()
+	var lastErr error
+	for i := range f.servers.urls {
+		index := (first + i) % len(f.servers.urls)
+		r, err := f.servers.request(req, index, i > 0)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := f.rt.RoundTrip(r)
+		if err == nil {
+			f.servers.setCurrent(index)
+			return resp, nil
+		}
+		lastErr = err
+		if !canFailover(req, err) {
+			break
+		}
+		klog.V(4).Infof("API server %v is not reachable: %v", f.servers.servers[index], err)
+	}
+	return nil, lastErr
+}
+
+func (f *failoverRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return f.rt
+}
+
+// a request can be sent again to the next server if it has not been canceled and its body can be read again,
+// and either the server closed the connection without answering or the request does not modify the resources
+func canFailover(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// if the kubeconfig has more contexts, i.e. it lists more API servers for failover, and the server of the current one
+// is not reachable, the current context is replaced by the first reachable one, in the order the servers have been
+// configured. The servers are probed concurrently, hence the selection takes at most connectivityTimeout, and the
+// selected server is cached for failoverCacheTTL, not to probe them every time a client is created
+func selectReachableContext(config *clientcmdapi.Config) {
+	if len(config.Contexts) < 2 {
+		return
+	}
+	names := orderedContexts(config)
+	key := failoverKey(config, names)
+	if server, ok := failoverCache.get(key); ok {
+		for _, name := range names {
+			if getServer(config, name) == server {
+				config.CurrentContext = name
+				return
+			}
+		}
+	}
+
+	selected, ok := firstReachable(config, names)
+	if !ok {
+		// if no server is reachable the current context is kept, and cached not to wait for the timeout every time
+		selected = config.CurrentContext
+	}
+	if selected != config.CurrentContext {
+		klog.Warningf("API server of context %v is not reachable, using context %v", config.CurrentContext, selected)
+		config.CurrentContext = selected
+	}
+	failoverCache.set(key, getServer(config, selected))
+}
+
+// firstReachable probes the servers of the contexts concurrently and returns the first reachable one in the given
+// order, as soon as the servers of the previous contexts are known to be unreachable
+func firstReachable(config *clientcmdapi.Config, names []string) (string, bool) {
+	type result struct {
+		index     int
+		reachable bool
+	}
+	// buffered, not to block the probes still running when the result is returned
+	results := make(chan result, len(names))
+	for i := range names {
+		go func(i int) {
+			results <- result{index: i, reachable: isReachable(config, names[i])}
+		}(i)
+	}
+
+	reachable := make([]*bool, len(names))
+	for range names {
+		r := <-results
+		reachable[r.index] = &r.reachable
+		for i := range names {
+			if reachable[i] == nil {
+				break
+			}
+			if *reachable[i] {
+				return names[i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// orderedContexts returns the names of the contexts of the kubeconfig, the current one first and the others in the
+// order they have been configured, i.e. sorting the numeric suffixes of their names as numbers
+func orderedContexts(config *clientcmdapi.Config) []string {
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		if name != config.CurrentContext {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+	return append([]string{config.CurrentContext}, names...)
+}
+
+// naturalLess compares the strings in alphabetical order, except for their sequences of digits which are compared
+// as numbers, e.g. "service-cluster-2" comes before "service-cluster-10"
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digitsPrefix(a), digitsPrefix(b)
+		if da != "" && db != "" {
+			// the numbers are compared by length first, ignoring the leading zeros
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func digitsPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// the servers of the contexts identify the kubeconfig in the cache
+func failoverKey(config *clientcmdapi.Config, names []string) string {
+	servers := make([]string, len(names))
+	for i, name := range names {
+		servers[i] = getServer(config, name)
+	}
+	return strings.Join(servers, ",")
+}
+
+func getServer(config *clientcmdapi.Config, contextName string) string {
+	context, ok := config.Contexts[contextName]
+	if !ok {
+		return ""
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok {
+		return ""
+	}
+	return cluster.Server
+}
+
+// cache of the servers selected among the ones of the kubeconfigs with more contexts
+type serverCache struct {
+	mutex   sync.Mutex
+	entries map[string]serverCacheEntry
+}
+
+type serverCacheEntry struct {
+	server     string
+	expiration time.Time
+}
+
+var failoverCache = &serverCache{
+	entries: make(map[string]serverCacheEntry),
+}
+
+func (c *serverCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiration) {
+		return "", false
+	}
+	return entry.server, true
+}
+
+func (c *serverCache) set(key, server string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	// the expired entries are removed, not to grow the cache when the servers change
+	for k, entry := range c.entries {
+		if now.After(entry.expiration) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = serverCacheEntry{
+		server:     server,
+		expiration: now.Add(failoverCacheTTL),
+	}
+}
+
+func isReachable(config *clientcmdapi.Config, contextName string) bool {
+	server := getServer(config, contextName)
+	if server == "" {
+		return false
+	}
+	cluster := config.Clusters[config.Contexts[contextName].Cluster]
+	if err := CheckConnectivity(server, cluster.CertificateAuthorityData); err != nil {
+		klog.V(4).Infof("API server %v is not reachable: %v", server, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package crdClient
+
+import (
+	"encoding/pem"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestServer() (*httptest.Server, []byte) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, ca
+}
+
+// createFakeConfig returns a kubeconfig with a context for each server, the first one is the current context
+func createFakeConfig(ca []byte, servers ...string) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	for i, server := range servers {
+		name := "service-cluster"
+		if i > 0 {
+			name = fmt.Sprintf("service-cluster-%v", i)
+		}
+		config.Clusters[name] = &clientcmdapi.Cluster{
+			Server:                   server,
+			CertificateAuthorityData: ca,
+		}
+		config.Contexts["sa@"+name] = &clientcmdapi.Context{
+			Cluster:  name,
+			AuthInfo: "sa",
+		}
+	}
+	config.CurrentContext = "sa@service-cluster"
+	return config
+}
+
+func TestNaturalLess(t *testing.T) {
+	names := []string{"service-cluster-10", "service-cluster-2", "service-cluster-1", "service-cluster", "service-cluster-02"}
+	sort.Slice(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+	assert.Equal(t, []string{"service-cluster", "service-cluster-1", "service-cluster-2", "service-cluster-02", "service-cluster-10"}, names)
+}
+
+func TestSelectReachableContext(t *testing.T) {
+	server, ca := newTestServer()
+	defer server.Close()
+	down, _ := newTestServer()
+	down.Close()
+
+	//the first reachable server is selected in the configured order, also after the tenth one
+	servers := []string{down.URL}
+	for i := 1; i < 11; i++ {
+		servers = append(servers, down.URL+fmt.Sprintf("/%v", i))
+	}
+	servers = append(servers, server.URL)
+	config := createFakeConfig(ca, servers...)
+	selectReachableContext(config)
+	assert.Equal(t, "sa@service-cluster-11", config.CurrentContext)
+
+	//the selection is cached
+	server.Close()
+	config = createFakeConfig(ca, servers...)
+	selectReachableContext(config)
+	assert.Equal(t, "sa@service-cluster-11", config.CurrentContext)
+
+	//the current context is kept if it is reachable, without waiting for the other servers
+	current, ca := newTestServer()
+	defer current.Close()
+	release := make(chan struct{})
+	slow := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	config = createFakeConfig(ca, current.URL, slow.URL)
+	start := time.Now()
+	selectReachableContext(config)
+	assert.Equal(t, "sa@service-cluster", config.CurrentContext)
+	assert.Less(t, int64(time.Since(start)), int64(connectivityTimeout))
+}
+
+func TestFailoverConfig(t *testing.T) {
+	var served []string
+	var mutex sync.Mutex
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			defer mutex.Unlock()
+			served = append(served, name+" "+r.Method+" "+r.URL.Path+" "+string(body))
+		}))
+	}
+	lastServed := func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return served[len(served)-1]
+	}
+
+	// all the test servers have the same certificate
+	first := newServer("first")
+	defer first.Close()
+	second := newServer("second")
+	defer second.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: first.Certificate().Raw})
+
+	config, err := failoverConfig(createFakeConfig(ca, first.URL, second.URL+"/prefix"))
+	assert.NoError(t, err)
+	rt, err := rest.TransportFor(config)
+	assert.NoError(t, err)
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Get(first.URL + "/api")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "first GET /api ", lastServed())
+
+	// the requests are sent to the next server, also the ones with a body, when the current one is down
+	first.Close()
+	resp, err = client.Post(first.URL+"/api/v1/namespaces", "application/json", strings.NewReader("{}"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "second POST /prefix/api/v1/namespaces {}", lastServed())
+
+	resp, err = client.Get(first.URL + "/api")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "second GET /prefix/api ", lastServed())
+}
@@ -1,11 +1,16 @@
 package crdClient
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+	"reflect"
 )
 
 type FakeClient struct {
@@ -23,7 +28,14 @@ func (c *FakeClient) Namespace(namespace string) CrdClientInterface {
 	return c
 }
 
-func (c *FakeClient) Get(name string, _ metav1.GetOptions) (runtime.Object, error) {
+func (c *FakeClient) Get(name string, opts metav1.GetOptions) (runtime.Object, error) {
+	return c.GetContext(context.TODO(), name, opts)
+}
+
+func (c *FakeClient) GetContext(ctx context.Context, name string, _ metav1.GetOptions) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	result, found, err := c.storage.GetByKey(name)
 	if !found {
@@ -33,15 +45,45 @@ func (c *FakeClient) Get(name string, _ metav1.GetOptions) (runtime.Object, erro
 	return result.(runtime.Object), err
 }
 
-func (c *FakeClient) List(_ metav1.ListOptions) (runtime.Object, error) {
-	panic("to implement")
+func (c *FakeClient) List(opts metav1.ListOptions) (runtime.Object, error) {
+	return c.ListContext(context.TODO(), opts)
+}
+
+func (c *FakeClient) ListContext(ctx context.Context, _ metav1.ListOptions) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := reflect.New(c.resource.PluralType)
+	items := result.Elem().FieldByName("Items")
+	if !items.IsValid() {
+		return nil, fmt.Errorf("type %v has no Items field", c.resource.PluralType)
+	}
+	for _, obj := range c.storage.List() {
+		items.Set(reflect.Append(items, reflect.ValueOf(obj).Elem()))
+	}
+
+	return result.Interface().(runtime.Object), nil
+}
+
+func (c *FakeClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.WatchContext(context.TODO(), opts)
 }
 
-func (c *FakeClient) Watch(_ metav1.ListOptions) (watch.Interface, error) {
+func (c *FakeClient) WatchContext(ctx context.Context, _ metav1.ListOptions) (watch.Interface, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return c.storage.watcher, nil
 }
 
-func (c *FakeClient) Create(obj runtime.Object, _ metav1.CreateOptions) (runtime.Object, error) {
+func (c *FakeClient) Create(obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error) {
+	return c.CreateContext(context.TODO(), obj, opts)
+}
+
+func (c *FakeClient) CreateContext(ctx context.Context, obj runtime.Object, _ metav1.CreateOptions) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	err := c.storage.Add(obj)
 	if err != nil {
 		return nil, err
@@ -51,7 +93,14 @@ func (c *FakeClient) Create(obj runtime.Object, _ metav1.CreateOptions) (runtime
 }
 
 func (c *FakeClient) Delete(name string, opts metav1.DeleteOptions) error {
-	obj, err := c.Get(name, metav1.GetOptions{})
+	return c.DeleteContext(context.TODO(), name, opts)
+}
+
+func (c *FakeClient) DeleteContext(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	obj, err := c.GetContext(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -62,7 +111,14 @@ func (c *FakeClient) Delete(name string, opts metav1.DeleteOptions) error {
 	return nil
 }
 
-func (c *FakeClient) Update(name string, obj runtime.Object, _ metav1.UpdateOptions) (runtime.Object, error) {
+func (c *FakeClient) Update(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateContext(context.TODO(), name, obj, opts)
+}
+
+func (c *FakeClient) UpdateContext(ctx context.Context, name string, obj runtime.Object, _ metav1.UpdateOptions) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	err := c.storage.Update(obj)
 	if err != nil {
 		return nil, err
@@ -80,6 +136,13 @@ func (c *FakeClient) Update(name string, obj runtime.Object, _ metav1.UpdateOpti
 }
 
 func (c *FakeClient) UpdateStatus(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateStatusContext(context.TODO(), name, obj, opts)
+}
+
+func (c *FakeClient) UpdateStatusContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	err := c.storage.Update(obj)
 	if err != nil {
 		return nil, err
@@ -95,3 +158,32 @@ func (c *FakeClient) UpdateStatus(name string, obj runtime.Object, opts metav1.U
 
 	return result.(runtime.Object), nil
 }
+
+func (c *FakeClient) Patch(name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error) {
+	return c.PatchContext(context.TODO(), name, pt, data, opts, subresources...)
+}
+
+// PatchContext applies the merge patches to the stored object, the server-side apply is approximated by a merge
+// patch since the fake does not track the field managers. The status is stored with the object, so the
+// subresources are ignored.
+func (c *FakeClient) PatchContext(ctx context.Context, name string, pt types.PatchType, data []byte, _ metav1.PatchOptions, _ ...string) (runtime.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if pt != types.MergePatchType && pt != types.ApplyPatchType {
+		return nil, fmt.Errorf("patch type %v not supported by the fake client", pt)
+	}
+	obj, err := c.GetContext(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	patched, err := mergePatch(obj, data)
+	if err != nil {
+		return nil, kerrors.NewBadRequest(err.Error())
+	}
+	result := reflect.New(c.resource.SingularType).Interface()
+	if err = json.Unmarshal(patched, result); err != nil {
+		return nil, kerrors.NewBadRequest(err.Error())
+	}
+	return c.UpdateContext(ctx, name, result.(runtime.Object), metav1.UpdateOptions{})
+}
@@ -110,11 +110,8 @@ func (i *fakeInformer) GetByKeyFake(key string) (item interface{}, exists bool,
 	v, ok := i.data[key]
 	i.lock.Unlock()
 
-	if !ok {
-		return nil, false, kerrors.NewNotFound(i.groupResource, key)
-	}
-
-	return v, true, nil
+	// as the real stores, a missing object is not an error
+	return v, ok, nil
 }
 
 func (i *fakeInformer) ReplaceFake(list []interface{}, resourceVersion string) error {
@@ -0,0 +1,146 @@
+package crdClient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakeResourceClient is the ResourceClient working on the fakeInformer, the objects are keyed by name as
+// in the FakeClient. The stored objects are copies, so that the callers cannot modify them.
+type fakeResourceClient struct {
+	resource ResourceType
+	ns       string
+
+	storage *fakeInformer
+}
+
+func (c *fakeResourceClient) checkStorage(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.storage == nil {
+		return errors.New("the store of the fake client is not a fake informer")
+	}
+	return nil
+}
+
+func (c *fakeResourceClient) Get(ctx context.Context, name string, _ metav1.GetOptions, into runtime.Object) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	result, found, err := c.storage.GetByKey(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return kerrors.NewNotFound(c.resource.GroupResource, name)
+	}
+	return copyInto(result, into)
+}
+
+func (c *fakeResourceClient) List(ctx context.Context, _ metav1.ListOptions, into runtime.Object) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	list := map[string]interface{}{
+		"items": c.storage.List(),
+	}
+	return copyInto(list, into)
+}
+
+func (c *fakeResourceClient) Create(ctx context.Context, obj runtime.Object, _ metav1.CreateOptions, into runtime.Object) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	key, err := c.resource.Keyer(obj)
+	if err != nil {
+		return err
+	}
+	if _, found, err := c.storage.GetByKey(key); err != nil {
+		return err
+	} else if found {
+		return kerrors.NewAlreadyExists(c.resource.GroupResource, key)
+	}
+	if err := c.storage.Add(obj.DeepCopyObject()); err != nil {
+		return err
+	}
+	return copyInto(obj, into)
+}
+
+func (c *fakeResourceClient) Update(ctx context.Context, name string, obj runtime.Object, _ metav1.UpdateOptions, into runtime.Object) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	if err := c.storage.Update(obj.DeepCopyObject()); err != nil {
+		return err
+	}
+	return c.Get(ctx, name, metav1.GetOptions{}, into)
+}
+
+func (c *fakeResourceClient) UpdateStatus(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions, into runtime.Object) error {
+	return c.Update(ctx, name, obj, opts, into)
+}
+
+// Patch applies the merge patches to the stored object, the server-side apply is approximated by a merge patch
+// since the fake does not track the field managers
+func (c *fakeResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, _ metav1.PatchOptions, into runtime.Object, _ ...string) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	if pt != types.MergePatchType && pt != types.ApplyPatchType {
+		return fmt.Errorf("patch type %v not supported by the fake client", pt)
+	}
+	obj, found, err := c.storage.GetByKey(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return kerrors.NewNotFound(c.resource.GroupResource, name)
+	}
+	patched, err := mergePatch(obj, data)
+	if err != nil {
+		return kerrors.NewBadRequest(err.Error())
+	}
+	result := c.resource.NewObject()
+	if err = json.Unmarshal(patched, result); err != nil {
+		return kerrors.NewBadRequest(err.Error())
+	}
+	return c.Update(ctx, name, result, metav1.UpdateOptions{}, into)
+}
+
+func (c *fakeResourceClient) Delete(ctx context.Context, name string, _ metav1.DeleteOptions) error {
+	if err := c.checkStorage(ctx); err != nil {
+		return err
+	}
+	obj, found, err := c.storage.GetByKey(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return kerrors.NewNotFound(c.resource.GroupResource, name)
+	}
+	return c.storage.Delete(obj)
+}
+
+func (c *fakeResourceClient) Watch(ctx context.Context, _ metav1.ListOptions) (watch.Interface, error) {
+	if err := c.checkStorage(ctx); err != nil {
+		return nil, err
+	}
+	return c.storage.watcher, nil
+}
+
+// copyInto copies the object through its JSON serialization, as the REST client decodes the responses
+func copyInto(obj interface{}, into runtime.Object) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
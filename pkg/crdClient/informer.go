@@ -1,6 +1,7 @@
 package crdClient
 
 import (
+	"context"
 	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,6 +27,42 @@ func WatchResources(clientSet NamespacedCRDClientInterface,
 	}
 }
 
+// WatchResourcesContext is the context-aware variant of WatchResources: the cache is stopped and its
+// requests are canceled when the context is done
+func WatchResourcesContext(ctx context.Context,
+	clientSet NamespacedCRDClientInterface,
+	resource, namespace string,
+	resyncPeriod time.Duration,
+	handlers cache.ResourceEventHandlerFuncs,
+	lo metav1.ListOptions) (cache.Store, error) {
+
+	var store cache.Store
+	var stop chan struct{}
+	var err error
+	if Fake {
+		store, stop, err = WatchfakeResources(resource, handlers)
+	} else {
+		store, stop, err = watchRealResources(ctx, clientSet, resource, namespace, resyncPeriod, handlers, lo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stopOnDone(ctx, stop)
+	return store, nil
+}
+
+// close stop when the context is done, a context that is never done (e.g. context.TODO()) leaves the cache running
+func stopOnDone(ctx context.Context, stop chan struct{}) {
+	done := ctx.Done()
+	if done == nil {
+		return
+	}
+	go func() {
+		<-done
+		close(stop)
+	}()
+}
+
 // Watch RealResources creates
 func WatchRealResources(clientSet NamespacedCRDClientInterface,
 	resource, namespace string,
@@ -33,14 +70,24 @@ func WatchRealResources(clientSet NamespacedCRDClientInterface,
 	handlers cache.ResourceEventHandlerFuncs,
 	lo metav1.ListOptions) (cache.Store, chan struct{}, error) {
 
+	return watchRealResources(context.TODO(), clientSet, resource, namespace, resyncPeriod, handlers, lo)
+}
+
+func watchRealResources(ctx context.Context,
+	clientSet NamespacedCRDClientInterface,
+	resource, namespace string,
+	resyncPeriod time.Duration,
+	handlers cache.ResourceEventHandlerFuncs,
+	lo metav1.ListOptions) (cache.Store, chan struct{}, error) {
+
 	listFunc := func(ls metav1.ListOptions) (result runtime.Object, err error) {
 		ls = lo
-		return clientSet.Resource(resource).Namespace(namespace).List(ls)
+		return clientSet.Resource(resource).Namespace(namespace).ListContext(ctx, ls)
 	}
 
 	watchFunc := func(ls metav1.ListOptions) (watch.Interface, error) {
 		ls = lo
-		return clientSet.Resource(resource).Namespace(namespace).Watch(ls)
+		return clientSet.Resource(resource).Namespace(namespace).WatchContext(ctx, ls)
 	}
 	res, ok := Registry[resource]
 	if !ok {
@@ -76,3 +123,75 @@ func WatchfakeResources(resource string, handlers cache.ResourceEventHandlerFunc
 	store, stop := NewFakeCustomInformer(handlers, res.Keyer, res.Resource)
 	return store, stop, nil
 }
+
+// WatchResourceType is the equivalent of WatchResources for the typed clients: it creates either a real
+// cache or a fake one, depending on the global variable Fake, without looking up the resource in the Registry
+func WatchResourceType(clientSet *CRDClient,
+	resource ResourceType, namespace string,
+	resyncPeriod time.Duration,
+	handlers cache.ResourceEventHandlerFuncs,
+	lo metav1.ListOptions) (cache.Store, chan struct{}, error) {
+
+	return watchResourceType(context.TODO(), clientSet, resource, namespace, resyncPeriod, handlers, lo)
+}
+
+// WatchResourceTypeContext is the context-aware variant of WatchResourceType: the cache is stopped and its
+// requests are canceled when the context is done
+func WatchResourceTypeContext(ctx context.Context,
+	clientSet *CRDClient,
+	resource ResourceType, namespace string,
+	resyncPeriod time.Duration,
+	handlers cache.ResourceEventHandlerFuncs,
+	lo metav1.ListOptions) (cache.Store, error) {
+
+	store, stop, err := watchResourceType(ctx, clientSet, resource, namespace, resyncPeriod, handlers, lo)
+	if err != nil {
+		return nil, err
+	}
+	stopOnDone(ctx, stop)
+	return store, nil
+}
+
+func watchResourceType(ctx context.Context,
+	clientSet *CRDClient,
+	resource ResourceType, namespace string,
+	resyncPeriod time.Duration,
+	handlers cache.ResourceEventHandlerFuncs,
+	lo metav1.ListOptions) (cache.Store, chan struct{}, error) {
+
+	if Fake {
+		store, stop := NewFakeCustomInformer(handlers, resource.Keyer, resource.GroupResource)
+		return store, stop, nil
+	}
+
+	client := clientSet.ResourceClient(resource, namespace)
+	listFunc := func(ls metav1.ListOptions) (runtime.Object, error) {
+		list := resource.NewList()
+		err := client.List(ctx, lo, list)
+		return list, err
+	}
+
+	// the watch resumes from the resource version of the list performed by the reflector
+	watchFunc := func(ls metav1.ListOptions) (watch.Interface, error) {
+		opts := lo
+		opts.ResourceVersion = ls.ResourceVersion
+		opts.TimeoutSeconds = ls.TimeoutSeconds
+		return client.Watch(ctx, opts)
+	}
+
+	store, controller := cache.NewInformer(
+		&cache.ListWatch{
+			ListFunc:  listFunc,
+			WatchFunc: watchFunc,
+		},
+		resource.NewObject(),
+		resyncPeriod,
+		handlers,
+	)
+
+	stopChan := make(chan struct{}, 1)
+
+	go controller.Run(stopChan)
+
+	return store, stopChan, nil
+}
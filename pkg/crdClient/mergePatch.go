@@ -0,0 +1,41 @@
+package crdClient
+
+import (
+	"encoding/json"
+)
+
+// mergePatch applies a JSON merge patch (RFC 7386) to the JSON serialization of obj
+func mergePatch(obj interface{}, patch []byte) ([]byte, error) {
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var doc, p interface{}
+	if err = json.Unmarshal(original, &doc); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(doc, p))
+}
+
+// a patch which is not an object replaces the value, the null fields of a patch object remove the value
+func mergeValue(doc, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docMap, ok := doc.(map[string]interface{})
+	if !ok {
+		docMap = map[string]interface{}{}
+	}
+	for k, v := range patchMap {
+		if v == nil {
+			delete(docMap, k)
+			continue
+		}
+		docMap[k] = mergeValue(docMap[k], v)
+	}
+	return docMap
+}
@@ -0,0 +1,231 @@
+package crdClient
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"sync"
+)
+
+// Action is a call performed on a client of a resource
+type Action struct {
+	Verb      string
+	Api       string
+	Namespace string
+	Name      string
+	// Object is a copy of the object sent by the create and update calls
+	Object       runtime.Object
+	PatchType    types.PatchType
+	Patch        []byte
+	Subresources []string
+}
+
+// ReactorFunc is called for each recorded call before it is performed: if handled is true, the call is not
+// forwarded to the client and obj and err are returned, so that the tests can inject failures
+type ReactorFunc func(action Action) (handled bool, obj runtime.Object, err error)
+
+// Recorder records the calls performed on the clients of a resource, so that the tests can assert on them
+type Recorder struct {
+	api string
+
+	lock    sync.Mutex
+	actions []Action
+	reactor ReactorFunc
+}
+
+// NewRecorder returns a Recorder for the clients of the resource
+func NewRecorder(api string) *Recorder {
+	return &Recorder{
+		api: api,
+	}
+}
+
+// Record makes the clients of the resource returned from now on by Resource record their calls on the returned
+// Recorder, it has to be called before the CRDClient is shared with other goroutines
+func (c *CRDClient) Record(api string) *Recorder {
+	if c.recorders == nil {
+		c.recorders = make(map[string]*Recorder)
+	}
+	r := NewRecorder(api)
+	c.recorders[api] = r
+	return r
+}
+
+// Client wraps the client so that its calls are recorded
+func (r *Recorder) Client(client CrdClientInterface) CrdClientInterface {
+	return &recordingClient{
+		client:   client,
+		recorder: r,
+	}
+}
+
+// Actions returns the calls recorded so far, in the order they have been performed
+func (r *Recorder) Actions() []Action {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	actions := make([]Action, len(r.actions))
+	copy(actions, r.actions)
+	return actions
+}
+
+// ActionsFor returns the recorded calls with the given verb
+func (r *Recorder) ActionsFor(verb string) []Action {
+	var actions []Action
+	for _, action := range r.Actions() {
+		if action.Verb == verb {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.actions = nil
+}
+
+func (r *Recorder) SetReactor(reactor ReactorFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reactor = reactor
+}
+
+func (r *Recorder) record(action Action) (bool, runtime.Object, error) {
+	r.lock.Lock()
+	r.actions = append(r.actions, action)
+	reactor := r.reactor
+	r.lock.Unlock()
+	if reactor == nil {
+		return false, nil, nil
+	}
+	return reactor(action)
+}
+
+type recordingClient struct {
+	client   CrdClientInterface
+	recorder *Recorder
+	ns       string
+}
+
+func (c *recordingClient) action(verb, name string, obj runtime.Object) Action {
+	action := Action{
+		Verb:      verb,
+		Api:       c.recorder.api,
+		Namespace: c.ns,
+		Name:      name,
+	}
+	if obj != nil {
+		action.Object = obj.DeepCopyObject()
+		if accessor, err := meta.Accessor(obj); err == nil && name == "" {
+			action.Name = accessor.GetName()
+		}
+	}
+	return action
+}
+
+// do records the call and performs it, unless it is handled by the reactor
+func (c *recordingClient) do(action Action, call func() (runtime.Object, error)) (runtime.Object, error) {
+	if handled, obj, err := c.recorder.record(action); handled {
+		return obj, err
+	}
+	return call()
+}
+
+func (c *recordingClient) Namespace(namespace string) CrdClientInterface {
+	c.ns = namespace
+	c.client = c.client.Namespace(namespace)
+	return c
+}
+
+func (c *recordingClient) List(opts metav1.ListOptions) (runtime.Object, error) {
+	return c.ListContext(context.TODO(), opts)
+}
+
+func (c *recordingClient) Get(name string, opts metav1.GetOptions) (runtime.Object, error) {
+	return c.GetContext(context.TODO(), name, opts)
+}
+
+func (c *recordingClient) Create(obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error) {
+	return c.CreateContext(context.TODO(), obj, opts)
+}
+
+func (c *recordingClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.WatchContext(context.TODO(), opts)
+}
+
+func (c *recordingClient) Update(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateContext(context.TODO(), name, obj, opts)
+}
+
+func (c *recordingClient) UpdateStatus(name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.UpdateStatusContext(context.TODO(), name, obj, opts)
+}
+
+func (c *recordingClient) Patch(name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error) {
+	return c.PatchContext(context.TODO(), name, pt, data, opts, subresources...)
+}
+
+func (c *recordingClient) Delete(name string, opts metav1.DeleteOptions) error {
+	return c.DeleteContext(context.TODO(), name, opts)
+}
+
+func (c *recordingClient) ListContext(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+	return c.do(c.action("list", "", nil), func() (runtime.Object, error) {
+		return c.client.ListContext(ctx, opts)
+	})
+}
+
+func (c *recordingClient) GetContext(ctx context.Context, name string, opts metav1.GetOptions) (runtime.Object, error) {
+	return c.do(c.action("get", name, nil), func() (runtime.Object, error) {
+		return c.client.GetContext(ctx, name, opts)
+	})
+}
+
+func (c *recordingClient) CreateContext(ctx context.Context, obj runtime.Object, opts metav1.CreateOptions) (runtime.Object, error) {
+	return c.do(c.action("create", "", obj), func() (runtime.Object, error) {
+		return c.client.CreateContext(ctx, obj, opts)
+	})
+}
+
+// WatchContext records the call, the reactor cannot handle it since it does not return a watch
+func (c *recordingClient) WatchContext(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	c.recorder.lock.Lock()
+	c.recorder.actions = append(c.recorder.actions, c.action("watch", "", nil))
+	c.recorder.lock.Unlock()
+	return c.client.WatchContext(ctx, opts)
+}
+
+func (c *recordingClient) UpdateContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	return c.do(c.action("update", name, obj), func() (runtime.Object, error) {
+		return c.client.UpdateContext(ctx, name, obj, opts)
+	})
+}
+
+func (c *recordingClient) UpdateStatusContext(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions) (runtime.Object, error) {
+	action := c.action("update", name, obj)
+	action.Subresources = []string{"status"}
+	return c.do(action, func() (runtime.Object, error) {
+		return c.client.UpdateStatusContext(ctx, name, obj, opts)
+	})
+}
+
+func (c *recordingClient) PatchContext(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (runtime.Object, error) {
+	action := c.action("patch", name, nil)
+	action.PatchType = pt
+	action.Patch = data
+	action.Subresources = subresources
+	return c.do(action, func() (runtime.Object, error) {
+		return c.client.PatchContext(ctx, name, pt, data, opts, subresources...)
+	})
+}
+
+func (c *recordingClient) DeleteContext(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	_, err := c.do(c.action("delete", name, nil), func() (runtime.Object, error) {
+		return nil, c.client.DeleteContext(ctx, name, opts)
+	})
+	return err
+}
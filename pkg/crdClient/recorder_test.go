@@ -0,0 +1,84 @@
+package crdClient_test
+
+import (
+	"context"
+	"errors"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"testing"
+)
+
+func createFakeClient(t *testing.T) *crdClient.CRDClient {
+	crdClient.Fake = true
+	crdClient.AddToRegistry("peeringrequests", &discoveryv1alpha1.PeeringRequest{}, &discoveryv1alpha1.PeeringRequestList{},
+		discoveryv1alpha1.Keyer, discoveryv1alpha1.PeeringRequestResource.GroupResource)
+	clientSet, err := crdClient.NewFromConfig(nil)
+	assert.Nil(t, err)
+	clientSet.Store, err = crdClient.WatchResourcesContext(context.TODO(), clientSet, "peeringrequests", "", 0, cache.ResourceEventHandlerFuncs{}, metav1.ListOptions{})
+	assert.Nil(t, err)
+	return clientSet
+}
+
+func createFakePeeringRequest(name string) *discoveryv1alpha1.PeeringRequest {
+	return &discoveryv1alpha1.PeeringRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: discoveryv1alpha1.PeeringRequestSpec{
+			ClusterIdentity: discoveryv1alpha1.ClusterIdentity{
+				ClusterID: name,
+			},
+			Namespace: "liqo",
+		},
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	clientSet := createFakeClient(t)
+	defer func() { crdClient.Fake = false }()
+	recorder := clientSet.Record("peeringrequests")
+
+	_, err := clientSet.Resource("peeringrequests").Create(createFakePeeringRequest("cluster-1"), metav1.CreateOptions{})
+	assert.Nil(t, err)
+	_, err = clientSet.Resource("peeringrequests").Patch("cluster-1", types.MergePatchType, []byte(`{"spec":{"namespace":"other"}}`), metav1.PatchOptions{})
+	assert.Nil(t, err)
+	obj, err := clientSet.Resource("peeringrequests").Get("cluster-1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	pr, ok := obj.(*discoveryv1alpha1.PeeringRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "other", pr.Spec.Namespace)
+	assert.Equal(t, "cluster-1", pr.Spec.ClusterIdentity.ClusterID)
+
+	actions := recorder.Actions()
+	assert.Equal(t, 3, len(actions))
+	assert.Equal(t, "create", actions[0].Verb)
+	assert.Equal(t, "cluster-1", actions[0].Name)
+	assert.Equal(t, "liqo", actions[0].Object.(*discoveryv1alpha1.PeeringRequest).Spec.Namespace, "the recorded object should be a copy")
+	assert.Equal(t, "patch", actions[1].Verb)
+	assert.Equal(t, types.MergePatchType, actions[1].PatchType)
+	assert.Equal(t, "get", actions[2].Verb)
+
+	//the reactor injects failures
+	recorder.Reset()
+	recorder.SetReactor(func(action crdClient.Action) (bool, runtime.Object, error) {
+		return action.Verb == "delete", nil, errors.New("injected")
+	})
+	assert.EqualError(t, clientSet.Resource("peeringrequests").Delete("cluster-1", metav1.DeleteOptions{}), "injected")
+	_, err = clientSet.Resource("peeringrequests").Get("cluster-1", metav1.GetOptions{})
+	assert.Nil(t, err, "the object should not be deleted")
+	assert.Equal(t, 1, len(recorder.ActionsFor("delete")))
+	assert.Equal(t, 2, len(recorder.Actions()))
+
+	//the calls fail when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = clientSet.Resource("peeringrequests").GetContext(ctx, "cluster-1", metav1.GetOptions{})
+	assert.Equal(t, context.Canceled, err)
+	_, err = clientSet.Resource("peeringrequests").Patch("cluster-1", types.JSONPatchType, []byte(`[]`), metav1.PatchOptions{})
+	assert.NotNil(t, err, "the JSON patches are not supported by the fake")
+}
@@ -0,0 +1,148 @@
+package crdClient
+
+import (
+	"context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"time"
+)
+
+// ResourceClient performs the CRUD operations on a custom resource decoding the results into the
+// objects provided by the caller, it is the building block of the typed clients of the API packages.
+// The requests are canceled when the context is done.
+type ResourceClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions, into runtime.Object) error
+	List(ctx context.Context, opts metav1.ListOptions, into runtime.Object) error
+	Create(ctx context.Context, obj runtime.Object, opts metav1.CreateOptions, into runtime.Object) error
+	Update(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions, into runtime.Object) error
+	UpdateStatus(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions, into runtime.Object) error
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, into runtime.Object, subresources ...string) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// ResourceClient returns a client for the resource in the namespace, an empty namespace for the cluster
+// scoped resources. In Fake mode the client works on the Store of the CRDClient.
+func (c *CRDClient) ResourceClient(resource ResourceType, namespace string) ResourceClient {
+	if Fake {
+		storage, _ := c.Store.(*fakeInformer)
+		return &fakeResourceClient{
+			resource: resource,
+			ns:       namespace,
+			storage:  storage,
+		}
+	}
+	return &restResourceClient{
+		client:   c.crdClient,
+		resource: resource,
+		ns:       namespace,
+	}
+}
+
+type restResourceClient struct {
+	client   rest.Interface
+	resource ResourceType
+	ns       string
+}
+
+func (c *restResourceClient) Get(ctx context.Context, name string, opts metav1.GetOptions, into runtime.Object) error {
+	return c.client.
+		Get().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Name(name).
+		Do(ctx).
+		Into(into)
+}
+
+func (c *restResourceClient) List(ctx context.Context, opts metav1.ListOptions, into runtime.Object) error {
+	return c.client.
+		Get().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Do(ctx).
+		Into(into)
+}
+
+func (c *restResourceClient) Create(ctx context.Context, obj runtime.Object, opts metav1.CreateOptions, into runtime.Object) error {
+	return c.client.
+		Post().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Body(obj).
+		Do(ctx).
+		Into(into)
+}
+
+func (c *restResourceClient) Update(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions, into runtime.Object) error {
+	return c.client.
+		Put().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Name(name).
+		Body(obj).
+		Do(ctx).
+		Into(into)
+}
+
+func (c *restResourceClient) UpdateStatus(ctx context.Context, name string, obj runtime.Object, opts metav1.UpdateOptions, into runtime.Object) error {
+	return c.client.
+		Put().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Name(name).
+		SubResource("status").
+		Body(obj).
+		Do(ctx).
+		Into(into)
+}
+
+// Patch supports the merge patches and the server-side apply, the latter requires opts.FieldManager to be set
+func (c *restResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, into runtime.Object, subresources ...string) error {
+	return c.client.
+		Patch(pt).
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Name(name).
+		SubResource(subresources...).
+		Body(data).
+		Do(ctx).
+		Into(into)
+}
+
+func (c *restResourceClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.
+		Delete().
+		Resource(c.resource.Api).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+func (c *restResourceClient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+
+	return c.client.
+		Get().
+		Resource(c.resource.Api).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		NamespaceIfScoped(c.ns, c.ns != "").
+		Timeout(timeout).
+		Watch(ctx)
+}
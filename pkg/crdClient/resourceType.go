@@ -0,0 +1,38 @@
+package crdClient
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceType describes a custom resource to the typed clients: unlike the entries of the Registry,
+// the objects are created by the constructors of the API package instead of by reflection
+type ResourceType struct {
+	// Api is the plural name of the resource, used in the REST paths
+	Api           string
+	GroupResource schema.GroupResource
+	Keyer         KeyerFunc
+	// NewObject and NewList return an empty object and an empty list of the resource
+	NewObject func() runtime.Object
+	NewList   func() runtime.Object
+}
+
+// NameKeyer is a KeyerFunc that keys the objects by name, suitable for the cluster scoped resources
+func NameKeyer(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	return accessor.GetName(), nil
+}
+
+// FromTombstone returns the object contained in the tombstone received by a delete handler when the
+// deletion has been missed by the watch, or the received object otherwise
+func FromTombstone(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
@@ -0,0 +1,100 @@
+package crdClient
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/oauth2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/transport"
+	"k8s.io/klog"
+	"sync"
+	"time"
+)
+
+// period after which the bearer token of a client with rotated credentials is read again
+const tokenReloadPeriod = 1 * time.Minute
+
+// a token source reading the token again once the reload period elapsed, the last token is kept if it cannot be read
+type reloadingTokenSource struct {
+	getToken func() (string, error)
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (ts *reloadingTokenSource) Token() (*oauth2.Token, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	if time.Now().After(ts.expiry) {
+		token, err := ts.getToken()
+		if err != nil {
+			klog.Warningf("unable to reload the bearer token, keeping the current one: %v", err)
+		} else if token != "" {
+			ts.token = token
+		}
+		ts.expiry = time.Now().Add(tokenReloadPeriod)
+	}
+	return &oauth2.Token{
+		AccessToken: ts.token,
+	}, nil
+}
+
+// replace the static bearer token of the config with one periodically read through getToken
+func reloadToken(config *rest.Config, getToken func() (string, error)) {
+	if config.BearerToken == "" {
+		return
+	}
+	// the current token is kept only if the first read fails
+	ts := &reloadingTokenSource{
+		getToken: getToken,
+		token:    config.BearerToken,
+	}
+	// the bearer token would be set before the token source is used
+	config.BearerToken = ""
+	config.Wrap(transport.TokenSourceWrapTransport(ts))
+}
+
+// ReloadTokenFromSecret makes the clients created from config read their bearer token again from the kubeconfig
+// stored in the given Secret, so that they keep working when the credentials it contains are rotated
+func ReloadTokenFromSecret(config *rest.Config, clientset kubernetes.Interface, namespace string, name string) {
+	reloadToken(config, func() (string, error) {
+		secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		cnf, err := clientcmd.Load(secret.Data["kubeconfig"])
+		if err != nil {
+			return "", err
+		}
+		return getToken(cnf)
+	})
+}
+
+// reload the bearer token from the kubeconfig file, which is updated when the Secret it is mounted from changes
+func reloadTokenFromFile(config *rest.Config, configPath string) {
+	reloadToken(config, func() (string, error) {
+		cnf, err := clientcmd.LoadFromFile(configPath)
+		if err != nil {
+			return "", err
+		}
+		return getToken(cnf)
+	})
+}
+
+// get the token of the user of the current context
+func getToken(cnf *clientcmdapi.Config) (string, error) {
+	context, ok := cnf.Contexts[cnf.CurrentContext]
+	if !ok {
+		return "", fmt.Errorf("context %v not found", cnf.CurrentContext)
+	}
+	authInfo, ok := cnf.AuthInfos[context.AuthInfo]
+	if !ok {
+		return "", fmt.Errorf("user %v not found", context.AuthInfo)
+	}
+	return authInfo.Token, nil
+}
@@ -2,41 +2,183 @@ package csrApprover
 
 import (
 	"context"
+	"errors"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/pkg/crdClient"
 	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
+	"strings"
 	"time"
 )
 
-func approveCSR(clientSet k8s.Interface, csr *certificatesv1beta1.CertificateSigningRequest) error {
+// KnownClusters returns the cluster IDs of the known ForeignClusters
+type KnownClusters func() ([]string, error)
+
+// ListForeignClusters returns the KnownClusters listing the ForeignClusters with the given client
+func ListForeignClusters(client *crdClient.CRDClient) KnownClusters {
+	return func() ([]string, error) {
+		tmp, err := client.Resource("foreignclusters").List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		fcs, ok := tmp.(*discoveryv1alpha1.ForeignClusterList)
+		if !ok {
+			return nil, errors.New("retrieved object is not a ForeignClusterList")
+		}
+		res := make([]string, 0, len(fcs.Items))
+		for _, fc := range fcs.Items {
+			res = append(res, fc.Spec.ClusterIdentity.ClusterID)
+		}
+		return res, nil
+	}
+}
+
+// SigningDuration returns the duration of the certificates issued by the signer of the CSRs, 0 if it is unknown
+type SigningDuration func() (time.Duration, error)
+
+// duration of the certificates issued by the kube-controller-manager if not configured by its flags
+const defaultClusterSigningDuration = 365 * 24 * time.Hour
+
+// ControllerManagerSigningDuration returns the SigningDuration reading the flags of the kube-controller-manager pods
+// in the kube-system namespace, unknown if they are not found. If declared is not 0, it is returned instead, e.g. for
+// the clusters whose control plane is not visible
+func ControllerManagerSigningDuration(clientset k8s.Interface, declared time.Duration) SigningDuration {
+	return func() (time.Duration, error) {
+		if declared > 0 {
+			return declared, nil
+		}
+		pods, err := clientset.CoreV1().Pods("kube-system").List(context.TODO(), metav1.ListOptions{
+			LabelSelector: "component=kube-controller-manager",
+		})
+		if err != nil {
+			return 0, err
+		}
+		var duration time.Duration
+		for i := range pods.Items {
+			d, err := getClusterSigningDuration(&pods.Items[i])
+			if err != nil {
+				return 0, err
+			}
+			// with more replicas, the longest duration is considered
+			if d > duration {
+				duration = d
+			}
+		}
+		return duration, nil
+	}
+}
+
+// get the signing duration from the command line of the kube-controller-manager pod
+func getClusterSigningDuration(pod *corev1.Pod) (time.Duration, error) {
+	duration := defaultClusterSigningDuration
+	for _, container := range pod.Spec.Containers {
+		args := append(append([]string{}, container.Command...), container.Args...)
+		for i, arg := range args {
+			for _, flag := range []string{"--cluster-signing-duration", "--experimental-cluster-signing-duration"} {
+				var value string
+				switch {
+				case strings.HasPrefix(arg, flag+"="):
+					value = strings.TrimPrefix(arg, flag+"=")
+				case arg == flag && i+1 < len(args):
+					value = args[i+1]
+				default:
+					continue
+				}
+				d, err := time.ParseDuration(value)
+				if err != nil {
+					return 0, err
+				}
+				duration = d
+			}
+		}
+	}
+	return duration, nil
+}
+
+type approver struct {
+	clientset       k8s.Interface
+	recorder        record.EventRecorder
+	policy          *Policy
+	knownClusters   KnownClusters
+	signingDuration SigningDuration
+}
+
+// approve the CSR if it is allowed by the policy, deny it otherwise
+func (a *approver) handleCSR(csr *certificatesv1beta1.CertificateSigningRequest) error {
 	// certificate already added to CSR
 	if csr.Status.Certificate != nil {
 		return nil
 	}
-	// Check if the certificate is already approved but the certificate is still not available
+	// Check if the certificate is already approved but the certificate is still not available, or if it was denied
 	for _, b := range csr.Status.Conditions {
-		if b.Type == "Approved" {
+		if b.Type == certificatesv1beta1.CertificateApproved || b.Type == certificatesv1beta1.CertificateDenied {
 			return nil
 		}
 	}
-	// Approve
+
+	clusterIDs, err := a.knownClusters()
+	if err != nil {
+		return err
+	}
+	signingDuration, err := a.signingDuration()
+	if err != nil {
+		return err
+	}
+	if d := a.policy.check(csr, clusterIDs, signingDuration); d != nil {
+		return a.denyCSR(csr, d)
+	}
+	return a.approveCSR(csr)
+}
+
+func (a *approver) approveCSR(csr *certificatesv1beta1.CertificateSigningRequest) error {
 	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
 		Type:           certificatesv1beta1.CertificateApproved,
 		Reason:         "LiqoApproval",
 		Message:        "This CSR was approved by Liqo Advertisement Operator",
 		LastUpdateTime: metav1.Now(),
 	})
-	_, errApproval := clientSet.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{})
+	_, errApproval := a.clientset.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{})
 	if errApproval != nil {
 		return errApproval
 	}
+	klog.Infof("CSR %v correctly approved", csr.Name)
+	return nil
+}
+
+func (a *approver) denyCSR(csr *certificatesv1beta1.CertificateSigningRequest, d *denial) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           certificatesv1beta1.CertificateDenied,
+		Reason:         d.reason,
+		Message:        d.message,
+		LastUpdateTime: metav1.Now(),
+	})
+	_, err := a.clientset.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	a.recorder.Event(csr, corev1.EventTypeWarning, d.reason, d.message)
+	klog.Warningf("CSR %v denied: %v", csr.Name, d)
 	return nil
 }
 
-func WatchCSR(clientset k8s.Interface, label string, resyncPeriod time.Duration) {
+// WatchCSR approves the CSRs with the given label allowed by the policy, and denies the other ones
+// knownClusters returns the ForeignClusters whose virtual-kubelets can request a certificate, signingDuration the
+// duration of the certificates issued by the signer, checked against the maximum of the policy
+func WatchCSR(clientset k8s.Interface, recorder record.EventRecorder, label string, resyncPeriod time.Duration,
+	policy *Policy, knownClusters KnownClusters, signingDuration SigningDuration) {
+	a := &approver{
+		clientset:       clientset,
+		recorder:        recorder,
+		policy:          policy,
+		knownClusters:   knownClusters,
+		signingDuration: signingDuration,
+	}
 
 	stop := make(chan struct{})
 	lo := func(options *metav1.ListOptions) {
@@ -47,19 +189,21 @@ func WatchCSR(clientset k8s.Interface, label string, resyncPeriod time.Duration)
 	}
 	informer := informers.NewSharedInformerFactoryWithOptions(clientset, resyncPeriod, options...)
 	csrInformer := informer.Certificates().V1beta1().CertificateSigningRequests().Informer()
+	handle := func(obj interface{}) {
+		csr, ok := obj.(*certificatesv1beta1.CertificateSigningRequest)
+		if !ok {
+			klog.Error("Unable to cast object")
+			return
+		}
+		if err := a.handleCSR(csr.DeepCopy()); err != nil {
+			klog.Error(err)
+		}
+	}
 	csrInformer.AddEventHandler(&cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			csr, ok := obj.(*certificatesv1beta1.CertificateSigningRequest)
-			if !ok {
-				klog.Error("Unable to cast object")
-				return
-			}
-			err := approveCSR(clientset, csr)
-			if err != nil {
-				klog.Error(err)
-			} else {
-				klog.Infof("CSR %v correctly approved", csr.Name)
-			}
+		AddFunc: handle,
+		// the CSRs which could not be handled are retried at every resync
+		UpdateFunc: func(_, newObj interface{}) {
+			handle(newObj)
 		},
 	})
 
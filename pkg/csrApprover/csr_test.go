@@ -2,16 +2,51 @@ package csrApprover
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"github.com/stretchr/testify/assert"
 	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	"testing"
+	"time"
 )
 
-func TestNewNamespaceWithSuffix(t *testing.T) {
-	//setup
-	certificateToValidate := certificatesv1beta1.CertificateSigningRequest{
+const (
+	testClusterID = "2a5b1bc0-b1d8-4c3a-9d4b-6d34f0a7c7a1"
+	vkUsername    = "system:serviceaccount:liqo:virtual-kubelet-" + testClusterID
+)
+
+var vkGroups = []string{"system:serviceaccounts", "system:serviceaccounts:liqo", "system:authenticated"}
+
+func newKey(t *testing.T) crypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// create a CSR for a serving certificate of the virtual-kubelet of the test cluster, which can be modified by the tests
+func newCSR(t *testing.T, key crypto.Signer, commonName string) *certificatesv1beta1.CertificateSigningRequest {
+	request, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{"system:nodes"},
+		},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &certificatesv1beta1.CertificateSigningRequest{
 		TypeMeta: v1.TypeMeta{},
 		ObjectMeta: v1.ObjectMeta{
 			Name: "to_validate",
@@ -19,16 +54,46 @@ func TestNewNamespaceWithSuffix(t *testing.T) {
 				"liqo.io/csr": "true",
 			},
 		},
-		Spec:   certificatesv1beta1.CertificateSigningRequestSpec{},
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: request}),
+			Usages: []certificatesv1beta1.KeyUsage{
+				certificatesv1beta1.UsageDigitalSignature,
+				certificatesv1beta1.UsageKeyEncipherment,
+				certificatesv1beta1.UsageServerAuth,
+			},
+			Username: vkUsername,
+			Groups:   vkGroups,
+		},
 		Status: certificatesv1beta1.CertificateSigningRequestStatus{},
 	}
+}
+
+func newApprover(c *testclient.Clientset) (*approver, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	return &approver{
+		clientset: c,
+		recorder:  recorder,
+		policy:    DefaultPolicy("liqo"),
+		knownClusters: func() ([]string, error) {
+			return []string{testClusterID}, nil
+		},
+		signingDuration: func() (time.Duration, error) {
+			return 365 * 24 * time.Hour, nil
+		},
+	}, recorder
+}
+
+func TestNewNamespaceWithSuffix(t *testing.T) {
+	//setup
+	certificateToValidate := newCSR(t, newKey(t), "virtual-kubelet-"+testClusterID+"-6d8f7b9c4-x2x5z")
 
 	c := testclient.NewSimpleClientset()
-	_, err := c.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), &certificateToValidate, v1.CreateOptions{})
+	_, err := c.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), certificateToValidate, v1.CreateOptions{})
 	if err != nil {
 		t.Fail()
 	}
-	err = approveCSR(c, &certificateToValidate)
+	a, _ := newApprover(c)
+	err = a.handleCSR(certificateToValidate)
 	if err != nil {
 		t.Fail()
 	}
@@ -43,3 +108,183 @@ func TestNewNamespaceWithSuffix(t *testing.T) {
 	assert.Equal(t, conditions[0].Reason, "LiqoApproval")
 	assert.Equal(t, conditions[0].Message, "This CSR was approved by Liqo Advertisement Operator")
 }
+
+func TestDenyCSR(t *testing.T) {
+	commonName := "virtual-kubelet-" + testClusterID + "-6d8f7b9c4-x2x5z"
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := "kubernetes.io/kube-apiserver-client"
+
+	tests := []struct {
+		name   string
+		csr    func() *certificatesv1beta1.CertificateSigningRequest
+		reason string
+	}{
+		{"invalid request", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), commonName)
+			csr.Spec.Request = []byte("not a request")
+			return csr
+		}, ReasonInvalidRequest},
+		{"client signer", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), commonName)
+			csr.Spec.SignerName = &signer
+			return csr
+		}, ReasonInvalidSigner},
+		{"client usage", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), commonName)
+			csr.Spec.Usages = append(csr.Spec.Usages, certificatesv1beta1.UsageClientAuth)
+			return csr
+		}, ReasonInvalidUsages},
+		{"small key", func() *certificatesv1beta1.CertificateSigningRequest {
+			return newCSR(t, rsaKey, commonName)
+		}, ReasonInvalidKey},
+		{"unknown requestor", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), commonName)
+			csr.Spec.Username = "system:serviceaccount:default:default"
+			return csr
+		}, ReasonUnknownRequestor},
+		{"missing group", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), commonName)
+			csr.Spec.Groups = []string{"system:authenticated"}
+			return csr
+		}, ReasonUnknownRequestor},
+		{"other pod", func() *certificatesv1beta1.CertificateSigningRequest {
+			return newCSR(t, newKey(t), "virtual-kubelet-other-6d8f7b9c4-x2x5z")
+		}, ReasonInvalidSubject},
+		{"unknown cluster", func() *certificatesv1beta1.CertificateSigningRequest {
+			csr := newCSR(t, newKey(t), "virtual-kubelet-unknown-6d8f7b9c4-x2x5z")
+			csr.Spec.Username = "system:serviceaccount:liqo:virtual-kubelet-unknown"
+			return csr
+		}, ReasonUnknownForeignCluster},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			csr := test.csr()
+			c := testclient.NewSimpleClientset()
+			_, err := c.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), csr, v1.CreateOptions{})
+			assert.NoError(t, err)
+			a, recorder := newApprover(c)
+			assert.NoError(t, a.handleCSR(csr))
+
+			cert, err := c.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), csr.Name, v1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, cert.Status.Conditions, 1)
+			assert.Equal(t, certificatesv1beta1.CertificateDenied, cert.Status.Conditions[0].Type)
+			assert.Equal(t, test.reason, cert.Status.Conditions[0].Reason)
+			assert.NotEmpty(t, cert.Status.Conditions[0].Message)
+			assert.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, test.reason)
+
+			// a denied CSR is not handled again
+			assert.NoError(t, a.handleCSR(cert))
+			assert.Len(t, recorder.Events, 0)
+		})
+	}
+}
+
+func TestApproveWebhookCSR(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr := newCSR(t, key, "mutatepodtoleration.liqo.svc")
+	csr.Spec.Username = "system:serviceaccount:liqo:podmutatoraccount"
+	// the subject of the webhook certificates contains no organization
+	request, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: "mutatepodtoleration.liqo.svc",
+		},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr.Spec.Request = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: request})
+
+	c := testclient.NewSimpleClientset()
+	_, err = c.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), csr, v1.CreateOptions{})
+	assert.NoError(t, err)
+	a, _ := newApprover(c)
+	assert.NoError(t, a.handleCSR(csr))
+
+	cert, err := c.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), csr.Name, v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, cert.Status.Conditions, 1)
+	assert.Equal(t, certificatesv1beta1.CertificateApproved, cert.Status.Conditions[0].Type)
+}
+
+func TestDenyCSRSigningDuration(t *testing.T) {
+	commonName := "virtual-kubelet-" + testClusterID + "-6d8f7b9c4-x2x5z"
+	for _, signingDuration := range []time.Duration{0, 10 * 365 * 24 * time.Hour} {
+		csr := newCSR(t, newKey(t), commonName)
+		c := testclient.NewSimpleClientset()
+		_, err := c.CertificatesV1beta1().CertificateSigningRequests().Create(context.TODO(), csr, v1.CreateOptions{})
+		assert.NoError(t, err)
+		a, recorder := newApprover(c)
+		a.signingDuration = func() (time.Duration, error) {
+			return signingDuration, nil
+		}
+		assert.NoError(t, a.handleCSR(csr))
+
+		cert, err := c.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), csr.Name, v1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, cert.Status.Conditions, 1)
+		assert.Equal(t, certificatesv1beta1.CertificateDenied, cert.Status.Conditions[0].Type)
+		assert.Equal(t, ReasonInvalidDuration, cert.Status.Conditions[0].Reason)
+		assert.Contains(t, <-recorder.Events, ReasonInvalidDuration)
+	}
+}
+
+func TestControllerManagerSigningDuration(t *testing.T) {
+	createFakeControllerManager := func(name string, args ...string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: "kube-system",
+				Labels: map[string]string{
+					"component": "kube-controller-manager",
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:    "kube-controller-manager",
+						Command: append([]string{"kube-controller-manager"}, args...),
+					},
+				},
+			},
+		}
+	}
+
+	// unknown if the kube-controller-manager is not visible
+	c := testclient.NewSimpleClientset()
+	duration, err := ControllerManagerSigningDuration(c, 0)()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), duration)
+
+	// the declared duration takes precedence
+	duration, err = ControllerManagerSigningDuration(c, time.Hour)()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, duration)
+
+	// the default duration of the kube-controller-manager
+	c = testclient.NewSimpleClientset(createFakeControllerManager("kube-controller-manager-master", "--leader-elect=true"))
+	duration, err = ControllerManagerSigningDuration(c, 0)()
+	assert.NoError(t, err)
+	assert.Equal(t, 365*24*time.Hour, duration)
+
+	// the longest duration among the replicas, in both the flag syntaxes
+	c = testclient.NewSimpleClientset(
+		createFakeControllerManager("kube-controller-manager-master-1", "--cluster-signing-duration=720h"),
+		createFakeControllerManager("kube-controller-manager-master-2", "--experimental-cluster-signing-duration", "1440h"),
+	)
+	duration, err = ControllerManagerSigningDuration(c, 0)()
+	assert.NoError(t, err)
+	assert.Equal(t, 1440*time.Hour, duration)
+
+	c = testclient.NewSimpleClientset(createFakeControllerManager("kube-controller-manager-master", "--cluster-signing-duration=1y"))
+	_, err = ControllerManagerSigningDuration(c, 0)()
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package csrApprover
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	pkg "github.com/liqotech/liqo/pkg/virtualKubelet"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	"path"
+	"strings"
+	"time"
+)
+
+// reasons of the denials, set in the Denied condition and in the event recorded on the CSR
+const (
+	ReasonInvalidRequest        = "InvalidRequest"
+	ReasonInvalidSigner         = "InvalidSigner"
+	ReasonInvalidUsages         = "InvalidUsages"
+	ReasonInvalidKey            = "InvalidKey"
+	ReasonInvalidDuration       = "InvalidDuration"
+	ReasonUnknownRequestor      = "UnknownRequestor"
+	ReasonInvalidSubject        = "InvalidSubject"
+	ReasonUnknownForeignCluster = "UnknownForeignCluster"
+)
+
+// Policy defines which CertificateSigningRequests are approved, the other ones are denied
+type Policy struct {
+	// the requestor and the subject of the certificate have to match one of the rules
+	Rules []Rule
+	// signers which can be requested, a CSR without signer is always allowed
+	SignerNames []string
+	// key usages which can be requested
+	Usages []certificatesv1beta1.KeyUsage
+	// minimum size in bits of the RSA keys
+	MinRsaKeySize int
+	// minimum size in bits of the curve of the ECDSA keys
+	MinEcdsaKeySize int
+	// maximum duration of the certificates, 0 if it is not checked. The v1beta1 API does not allow to request the
+	// duration of a certificate, which is the one configured in the signer (the kube-controller-manager): the CSRs
+	// are denied if the signer issues longer certificates, or if its signing duration is unknown
+	MaxDuration time.Duration
+}
+
+// Rule matches the requestor of a CertificateSigningRequest and the subject of the requested certificate
+type Rule struct {
+	// pattern of the username of the requestor, in path.Match syntax
+	Username string
+	// groups the requestor has to belong to
+	Groups []string
+	// pattern of the common name of the subject, in path.Match syntax
+	CommonName string
+	// organizations the subject can contain
+	Organizations []string
+	// if set, the requestor has to be the ServiceAccount of the virtual-kubelet of a known ForeignCluster, and the
+	// common name has to start with its name, as the name of the pods of the virtual-kubelet
+	ForeignCluster bool
+}
+
+// DefaultPolicy returns the policy approving the serving certificates of the virtual-kubelets and of the Liqo
+// webhooks deployed in namespace
+func DefaultPolicy(namespace string) *Policy {
+	serviceAccount := func(name string) string {
+		return fmt.Sprintf("system:serviceaccount:%v:%v", namespace, name)
+	}
+	groups := []string{"system:serviceaccounts:" + namespace}
+	return &Policy{
+		Rules: []Rule{
+			{
+				Username:       serviceAccount(pkg.VirtualKubeletPrefix + "*"),
+				Groups:         groups,
+				CommonName:     pkg.VirtualKubeletPrefix + "*",
+				Organizations:  []string{"system:nodes"},
+				ForeignCluster: true,
+			},
+			{
+				Username:   serviceAccount("podmutatoraccount"),
+				Groups:     groups,
+				CommonName: "*." + namespace + ".svc",
+			},
+			{
+				Username:   serviceAccount("peering-request-operator"),
+				Groups:     groups,
+				CommonName: "*." + namespace + ".svc",
+			},
+		},
+		SignerNames: []string{"kubernetes.io/legacy-unknown"},
+		Usages: []certificatesv1beta1.KeyUsage{
+			certificatesv1beta1.UsageDigitalSignature,
+			certificatesv1beta1.UsageKeyEncipherment,
+			certificatesv1beta1.UsageServerAuth,
+		},
+		MinRsaKeySize:   2048,
+		MinEcdsaKeySize: 256,
+		MaxDuration:     365 * 24 * time.Hour,
+	}
+}
+
+// the reason why a CSR is denied
+type denial struct {
+	reason  string
+	message string
+}
+
+func (d *denial) Error() string {
+	return d.reason + ": " + d.message
+}
+
+func deny(reason string, format string, args ...interface{}) *denial {
+	return &denial{
+		reason:  reason,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+// check the CSR against the policy, it returns the reason of the denial if it is not allowed
+// clusterIDs are the IDs of the known ForeignClusters, signingDuration is the duration of the certificates issued by
+// the signer, 0 if unknown
+func (p *Policy) check(csr *certificatesv1beta1.CertificateSigningRequest, clusterIDs []string, signingDuration time.Duration) *denial {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return deny(ReasonInvalidRequest, "the request is not a PEM encoded certificate request")
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return deny(ReasonInvalidRequest, "unable to parse the certificate request: %v", err)
+	}
+	if err = request.CheckSignature(); err != nil {
+		return deny(ReasonInvalidRequest, "invalid signature of the certificate request: %v", err)
+	}
+
+	if csr.Spec.SignerName != nil && !containsString(p.SignerNames, *csr.Spec.SignerName) {
+		return deny(ReasonInvalidSigner, "signer %v is not allowed", *csr.Spec.SignerName)
+	}
+	for _, usage := range csr.Spec.Usages {
+		if !containsUsage(p.Usages, usage) {
+			return deny(ReasonInvalidUsages, "key usage %v is not allowed", usage)
+		}
+	}
+	if d := p.checkKey(request); d != nil {
+		return d
+	}
+	if d := p.checkDuration(signingDuration); d != nil {
+		return d
+	}
+	return p.checkRequestor(csr, request, clusterIDs)
+}
+
+func (p *Policy) checkKey(request *x509.CertificateRequest) *denial {
+	switch key := request.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < p.MinRsaKeySize {
+			return deny(ReasonInvalidKey, "RSA keys must have at least %v bits, got %v", p.MinRsaKeySize, key.N.BitLen())
+		}
+	case *ecdsa.PublicKey:
+		if size := key.Curve.Params().BitSize; size < p.MinEcdsaKeySize {
+			return deny(ReasonInvalidKey, "ECDSA keys must have at least %v bits, got %v", p.MinEcdsaKeySize, size)
+		}
+	default:
+		return deny(ReasonInvalidKey, "key type %T is not allowed", key)
+	}
+	return nil
+}
+
+func (p *Policy) checkDuration(signingDuration time.Duration) *denial {
+	if p.MaxDuration <= 0 {
+		return nil
+	}
+	if signingDuration <= 0 {
+		return deny(ReasonInvalidDuration, "the duration of the certificates issued by the signer is unknown, it cannot be checked against the maximum of %v", p.MaxDuration)
+	}
+	if signingDuration > p.MaxDuration {
+		return deny(ReasonInvalidDuration, "the signer issues certificates valid for %v, exceeding the maximum of %v", signingDuration, p.MaxDuration)
+	}
+	return nil
+}
+
+// check that the requestor and the subject match a rule
+func (p *Policy) checkRequestor(csr *certificatesv1beta1.CertificateSigningRequest, request *x509.CertificateRequest, clusterIDs []string) *denial {
+	var res *denial
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.matchRequestor(csr) {
+			continue
+		}
+		if res = rule.checkSubject(csr, request, clusterIDs); res == nil {
+			return nil
+		}
+	}
+	if res == nil {
+		res = deny(ReasonUnknownRequestor, "requestor %v is not allowed to request certificates", csr.Spec.Username)
+	}
+	return res
+}
+
+func (r *Rule) matchRequestor(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	if ok, _ := path.Match(r.Username, csr.Spec.Username); !ok {
+		return false
+	}
+	for _, group := range r.Groups {
+		if !containsString(csr.Spec.Groups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) checkSubject(csr *certificatesv1beta1.CertificateSigningRequest, request *x509.CertificateRequest, clusterIDs []string) *denial {
+	commonName := request.Subject.CommonName
+	if ok, _ := path.Match(r.CommonName, commonName); !ok {
+		return deny(ReasonInvalidSubject, "common name %v is not allowed for requestor %v", commonName, csr.Spec.Username)
+	}
+	for _, organization := range request.Subject.Organization {
+		if !containsString(r.Organizations, organization) {
+			return deny(ReasonInvalidSubject, "organization %v is not allowed for requestor %v", organization, csr.Spec.Username)
+		}
+	}
+	if !r.ForeignCluster {
+		return nil
+	}
+
+	// the name of the ServiceAccount of the virtual-kubelet is the prefix followed by the cluster ID and, if the
+	// foreign cluster is split in more virtual nodes, by the partition suffix
+	serviceAccount := csr.Spec.Username[strings.LastIndex(csr.Spec.Username, ":")+1:]
+	for _, clusterID := range clusterIDs {
+		name := pkg.VirtualKubeletPrefix + clusterID
+		if serviceAccount == name || strings.HasPrefix(serviceAccount, name+"-") {
+			if !strings.HasPrefix(commonName, serviceAccount+"-") {
+				return deny(ReasonInvalidSubject, "common name %v does not belong to a pod of %v", commonName, serviceAccount)
+			}
+			return nil
+		}
+	}
+	return deny(ReasonUnknownForeignCluster, "requestor %v is not the virtual-kubelet of a known ForeignCluster", csr.Spec.Username)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUsage(list []certificatesv1beta1.KeyUsage, usage certificatesv1beta1.KeyUsage) bool {
+	for _, item := range list {
+		if item == usage {
+			return true
+		}
+	}
+	return false
+}
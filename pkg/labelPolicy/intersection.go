@@ -0,0 +1,33 @@
+package labelPolicy
+
+import corev1 "k8s.io/api/core/v1"
+
+// Intersection advertises the sorted intersection of the sets of values of the nodes, no label is advertised
+// if some nodes do not have it or the intersection is empty
+type Intersection struct {
+	valuePolicy
+}
+
+func (i *Intersection) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	values, missing := i.values(physicalNodes, key)
+	if missing || len(values) == 0 {
+		return "", false
+	}
+	intersection := make(map[string]struct{})
+	for _, e := range i.set(values[0]) {
+		intersection[e] = struct{}{}
+	}
+	for _, v := range values[1:] {
+		next := make(map[string]struct{})
+		for _, e := range i.set(v) {
+			if _, ok := intersection[e]; ok {
+				next[e] = struct{}{}
+			}
+		}
+		intersection = next
+	}
+	if len(intersection) == 0 {
+		return "", false
+	}
+	return join(key, intersection, i.separator)
+}
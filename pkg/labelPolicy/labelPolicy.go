@@ -1,6 +1,9 @@
 package labelPolicy
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"errors"
+	corev1 "k8s.io/api/core/v1"
+)
 
 type LabelPolicyType string
 
@@ -14,13 +17,68 @@ const (
 	LabelPolicyAnyTrueNoLabelIfFalse LabelPolicyType = "LabelPolicyAnyTrueNoLabelIfFalse"
 	// add val="" label if each node has a val=true or val="" label
 	LabelPolicyAllTrueNoLabelIfFalse LabelPolicyType = "LabelPolicyAllTrueNoLabelIfFalse"
+
+	// the following policies aggregate the label values, which can be selected and transformed by a regex (see Options)
+	// add the most frequent value among the nodes
+	LabelPolicyMajority LabelPolicyType = "LabelPolicyMajority"
+	// add the union of the sets of values of the nodes, e.g. val=avx2_avx512 from val=avx2 and val=avx512
+	LabelPolicyUnion LabelPolicyType = "LabelPolicyUnion"
+	// add the intersection of the sets of values of the nodes, if not empty
+	LabelPolicyIntersection LabelPolicyType = "LabelPolicyIntersection"
+	// add the minimum numeric value among the nodes
+	LabelPolicyMin LabelPolicyType = "LabelPolicyMin"
+	// add the maximum numeric value among the nodes
+	LabelPolicyMax LabelPolicyType = "LabelPolicyMax"
+	// add the most frequent value among the ones matching the regex, transformed by the replacement
+	LabelPolicyRegex LabelPolicyType = "LabelPolicyRegex"
 )
 
 type LabelPolicy interface {
 	Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool)
 }
 
+// GetInstance returns the policy with the default options
 func GetInstance(policyType LabelPolicyType) LabelPolicy {
+	policy, _ := NewPolicy(policyType, Options{})
+	return policy
+}
+
+// NewPolicy returns the policy with the given options, which are ignored by the boolean policies, or an error
+// if they are not valid. The AnyTrue policy is returned for an unknown type.
+func NewPolicy(policyType LabelPolicyType, options Options) (LabelPolicy, error) {
+	switch policyType {
+	case LabelPolicyMajority, LabelPolicyUnion, LabelPolicyIntersection, LabelPolicyMin, LabelPolicyMax, LabelPolicyRegex:
+		if policyType == LabelPolicyRegex && options.Regex == "" {
+			return &AnyTrue{}, errors.New("the regex policy requires a regex")
+		}
+		p, err := newValuePolicy(options)
+		if err != nil {
+			return &AnyTrue{}, err
+		}
+		return newValuePolicyInstance(policyType, p), nil
+	default:
+		return getBooleanInstance(policyType), nil
+	}
+}
+
+func newValuePolicyInstance(policyType LabelPolicyType, p valuePolicy) LabelPolicy {
+	switch policyType {
+	case LabelPolicyUnion:
+		return &Union{p}
+	case LabelPolicyIntersection:
+		return &Intersection{p}
+	case LabelPolicyMin:
+		return &Min{p}
+	case LabelPolicyMax:
+		return &Max{p}
+	case LabelPolicyRegex:
+		return &Regex{p}
+	default:
+		return &Majority{p}
+	}
+}
+
+func getBooleanInstance(policyType LabelPolicyType) LabelPolicy {
 	switch policyType {
 	case LabelPolicyAnyTrue:
 		return &AnyTrue{}
@@ -148,3 +148,113 @@ func TestAllTrueNoLabelIfFalse(t *testing.T) {
 	assert.EqualValues(t, "", val)
 	assert.Equal(t, insert, true)
 }
+
+func getValueNodes(values ...map[string]string) *v1.NodeList {
+	nodeList := &v1.NodeList{}
+	for _, labels := range values {
+		nodeList.Items = append(nodeList.Items, v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: labels,
+			},
+		})
+	}
+	return nodeList
+}
+
+var valueNodes = getValueNodes(
+	map[string]string{"gpu.vendor": "nvidia-tesla-t4", "cpu-generation": "9", "cpu.flags": "avx2_sse4"},
+	map[string]string{"gpu.vendor": "nvidia-tesla-v100", "cpu-generation": "10", "cpu.flags": "avx2_avx512_sse4"},
+	map[string]string{"gpu.vendor": "amd-radeon", "cpu-generation": "unknown", "cpu.flags": "sse4"},
+	map[string]string{"cpu-generation": "8"},
+)
+
+func TestMajority(t *testing.T) {
+	policy := GetInstance(LabelPolicyMajority)
+
+	val, insert := policy.Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "amd-radeon", val, "the lowest value should be chosen in case of tie")
+	assert.Equal(t, insert, true)
+
+	val, insert = policy.Process(valueNodes, "test5")
+	assert.EqualValues(t, "", val)
+	assert.Equal(t, insert, false)
+
+	policy, err := NewPolicy(LabelPolicyMajority, Options{Regex: "^nvidia-"})
+	assert.Nil(t, err)
+	val, insert = policy.Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "nvidia-tesla-t4", val)
+	assert.Equal(t, insert, true)
+}
+
+func TestUnionIntersection(t *testing.T) {
+	union, err := NewPolicy(LabelPolicyUnion, Options{Separator: "_"})
+	assert.Nil(t, err)
+	intersection, err := NewPolicy(LabelPolicyIntersection, Options{Separator: "_"})
+	assert.Nil(t, err)
+
+	val, insert := union.Process(valueNodes, "cpu.flags")
+	assert.EqualValues(t, "avx2_avx512_sse4", val)
+	assert.Equal(t, insert, true)
+
+	//the last node does not have the label
+	val, insert = intersection.Process(valueNodes, "cpu.flags")
+	assert.EqualValues(t, "", val)
+	assert.Equal(t, insert, false)
+
+	val, insert = intersection.Process(getValueNodes(valueNodes.Items[0].Labels, valueNodes.Items[1].Labels), "cpu.flags")
+	assert.EqualValues(t, "avx2_sse4", val)
+	assert.Equal(t, insert, true)
+
+	//without a separator the values are not split
+	val, insert = GetInstance(LabelPolicyIntersection).Process(getValueNodes(valueNodes.Items[0].Labels, valueNodes.Items[1].Labels), "cpu.flags")
+	assert.EqualValues(t, "", val)
+	assert.Equal(t, insert, false)
+
+	val, insert = GetInstance(LabelPolicyUnion).Process(getValueNodes(valueNodes.Items[0].Labels, valueNodes.Items[0].Labels), "cpu.flags")
+	assert.EqualValues(t, "avx2_sse4", val)
+	assert.Equal(t, insert, true)
+
+	val, insert = GetInstance(LabelPolicyUnion).Process(valueNodes, "cpu.flags")
+	assert.EqualValues(t, "", val, "the values cannot be joined without a separator")
+	assert.Equal(t, insert, false)
+
+	policy, err := NewPolicy(LabelPolicyUnion, Options{Separator: ".", Regex: "^([a-z]+)-", Replacement: "$1"})
+	assert.Nil(t, err)
+	val, insert = policy.Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "amd.nvidia", val)
+	assert.Equal(t, insert, true)
+}
+
+func TestMinMax(t *testing.T) {
+	val, insert := GetInstance(LabelPolicyMin).Process(valueNodes, "cpu-generation")
+	assert.EqualValues(t, "8", val)
+	assert.Equal(t, insert, true)
+
+	val, insert = GetInstance(LabelPolicyMax).Process(valueNodes, "cpu-generation")
+	assert.EqualValues(t, "10", val, "the values should be compared as numbers")
+	assert.Equal(t, insert, true)
+
+	val, insert = GetInstance(LabelPolicyMax).Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "", val)
+	assert.Equal(t, insert, false)
+}
+
+func TestRegex(t *testing.T) {
+	_, err := NewPolicy(LabelPolicyRegex, Options{})
+	assert.NotNil(t, err, "the regex policy requires a regex")
+	_, err = NewPolicy(LabelPolicyRegex, Options{Regex: "("})
+	assert.NotNil(t, err)
+
+	policy, err := NewPolicy(LabelPolicyRegex, Options{Regex: "^([a-z]+)-", Replacement: "$1"})
+	assert.Nil(t, err)
+	val, insert := policy.Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "nvidia", val)
+	assert.Equal(t, insert, true)
+
+	//the aggregated values which are not valid label values are not advertised
+	policy, err = NewPolicy(LabelPolicyRegex, Options{Regex: ".*", Replacement: "${0}!"})
+	assert.Nil(t, err)
+	val, insert = policy.Process(valueNodes, "gpu.vendor")
+	assert.EqualValues(t, "", val)
+	assert.Equal(t, insert, false)
+}
@@ -0,0 +1,26 @@
+package labelPolicy
+
+import corev1 "k8s.io/api/core/v1"
+
+// Majority advertises the most frequent value among the nodes having the label, the lowest one in case of tie
+type Majority struct {
+	valuePolicy
+}
+
+func (m *Majority) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	values, _ := m.values(physicalNodes, key)
+	if len(values) == 0 {
+		return "", false
+	}
+	counts := make(map[string]int)
+	for _, v := range values {
+		counts[v]++
+	}
+	value = values[0]
+	for v, n := range counts {
+		if n > counts[value] || (n == counts[value] && v < value) {
+			value = v
+		}
+	}
+	return result(key, value)
+}
@@ -0,0 +1,47 @@
+package labelPolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"strconv"
+)
+
+// Min advertises the minimum numeric value among the nodes having the label, the non numeric values are ignored
+type Min struct {
+	valuePolicy
+}
+
+func (m *Min) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	return m.extreme(physicalNodes, key, func(a, b float64) bool { return a < b })
+}
+
+// Max advertises the maximum numeric value among the nodes having the label, the non numeric values are ignored
+type Max struct {
+	valuePolicy
+}
+
+func (m *Max) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	return m.extreme(physicalNodes, key, func(a, b float64) bool { return a > b })
+}
+
+// extreme returns the value preceding all the others according to less, as it is written in the label
+func (p *valuePolicy) extreme(physicalNodes *corev1.NodeList, key string, less func(a, b float64) bool) (string, bool) {
+	values, _ := p.values(physicalNodes, key)
+	found := false
+	var value string
+	var extreme float64
+	for _, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		if !found || less(n, extreme) {
+			found = true
+			value = v
+			extreme = n
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return result(key, value)
+}
@@ -0,0 +1,14 @@
+package labelPolicy
+
+import corev1 "k8s.io/api/core/v1"
+
+// Regex advertises the most frequent value among the ones matching Options.Regex, transformed by
+// Options.Replacement, e.g. "nvidia" from the "nvidia-tesla-t4" value with the regex "^([a-z]+)-" and "$1"
+type Regex struct {
+	valuePolicy
+}
+
+func (r *Regex) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	majority := Majority{r.valuePolicy}
+	return majority.Process(physicalNodes, key)
+}
@@ -0,0 +1,42 @@
+package labelPolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	"sort"
+	"strings"
+)
+
+// Union advertises the sorted union of the sets of values of the nodes having the label. Without a separator the
+// union can be advertised only if it contains a single value
+type Union struct {
+	valuePolicy
+}
+
+func (u *Union) Process(physicalNodes *corev1.NodeList, key string) (value string, insertLabel bool) {
+	values, _ := u.values(physicalNodes, key)
+	union := make(map[string]struct{})
+	for _, v := range values {
+		for _, e := range u.set(v) {
+			union[e] = struct{}{}
+		}
+	}
+	if len(union) == 0 {
+		return "", false
+	}
+	return join(key, union, u.separator)
+}
+
+// join advertises the sorted elements of the set, which cannot be joined if there is no separator
+func join(key string, set map[string]struct{}, separator string) (string, bool) {
+	elements := make([]string, 0, len(set))
+	for e := range set {
+		elements = append(elements, e)
+	}
+	if len(elements) > 1 && separator == "" {
+		klog.Warningf("label %s not advertised, its values %v cannot be aggregated without a separator", key, elements)
+		return "", false
+	}
+	sort.Strings(elements)
+	return result(key, strings.Join(elements, separator))
+}
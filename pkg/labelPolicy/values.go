@@ -0,0 +1,97 @@
+package labelPolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog"
+	"regexp"
+	"strings"
+)
+
+// Options are the parameters of the policies aggregating the label values
+type Options struct {
+	// Separator of the elements of the values representing a set, since commas are not allowed in the label values.
+	// If empty, the values are not split and each of them is a single element
+	Separator string
+	// Regex, if not empty, selects the values to be aggregated: the nodes whose value does not match it are
+	// considered as not having the label
+	Regex string
+	// Replacement, if not empty, transforms the selected values expanding the submatches of Regex, e.g. "$1"
+	Replacement string
+}
+
+// valuePolicy contains the parameters shared by the policies aggregating the label values
+type valuePolicy struct {
+	separator   string
+	regex       *regexp.Regexp
+	replacement string
+}
+
+func newValuePolicy(options Options) (valuePolicy, error) {
+	p := valuePolicy{
+		separator:   options.Separator,
+		replacement: options.Replacement,
+	}
+	if options.Regex != "" {
+		regex, err := regexp.Compile(options.Regex)
+		if err != nil {
+			return p, err
+		}
+		p.regex = regex
+	}
+	return p, nil
+}
+
+// values returns the (transformed) values of the label of the nodes having it, and whether some nodes do not have it
+func (p *valuePolicy) values(physicalNodes *corev1.NodeList, key string) (values []string, missing bool) {
+	for _, node := range physicalNodes.Items {
+		v, ok := p.transform(node.Labels, key)
+		if !ok {
+			missing = true
+			continue
+		}
+		values = append(values, v)
+	}
+	return values, missing
+}
+
+func (p *valuePolicy) transform(labels map[string]string, key string) (string, bool) {
+	v, ok := labels[key]
+	if !ok || p.regex == nil {
+		return v, ok
+	}
+	match := p.regex.FindStringSubmatchIndex(v)
+	if match == nil {
+		return "", false
+	}
+	if p.replacement == "" {
+		return v, true
+	}
+	return string(p.regex.ExpandString(nil, p.replacement, v, match)), true
+}
+
+// set returns the elements of a value representing a set
+func (p *valuePolicy) set(value string) []string {
+	if p.separator == "" {
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}
+	var elements []string
+	for _, e := range strings.Split(value, p.separator) {
+		if e != "" {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// result discards the aggregated values which are not valid label values, e.g. because they are too long
+func result(key string, value string) (string, bool) {
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		klog.Warningf("label %s not advertised, invalid aggregated value %s: %s", key, value, strings.Join(errs, ", "))
+		return "", false
+	}
+	return value, true
+}
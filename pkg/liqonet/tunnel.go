@@ -16,6 +16,10 @@ const (
 	tunnelTtl        = 255
 )
 
+// TunnelBackends lists the technologies supported to build the tunnels between the clusters,
+// announced to the other clusters during the discovery
+var TunnelBackends = []string{"gre"}
+
 //Get the LocalTunnelPublicIP which is exported to the pod through an environment
 //variable called LocalTunnelPublicIP. The pod is run with hostNetwork=true so it gets the same IP
 //of the host where it is scheduled. The IP is the same used by the kubelet to register
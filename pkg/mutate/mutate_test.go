@@ -0,0 +1,177 @@
+package mutate
+
+import (
+	"encoding/json"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"testing"
+)
+
+func createFakePod(labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-pod",
+			Labels: labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("200m"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("100Mi"),
+						},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpExists},
+			},
+		},
+	}
+}
+
+func createFakeMutationServer(t *testing.T, config configv1alpha1.PodMutatorConfig) *MutationServer {
+	s := &MutationServer{Policies: NewPolicyEngine()}
+	s.Policies.UpdateConfig(&configv1alpha1.ClusterConfig{
+		Spec: configv1alpha1.ClusterConfigSpec{
+			PodMutatorConfig: config,
+		},
+	})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"liqo.io/offloading": "enabled"}}}))
+	assert.Nil(t, indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}))
+	s.namespaceLister = corev1listers.NewNamespaceLister(indexer)
+	return s
+}
+
+// mutate sends the pod to the mutator and returns the values of the patches by path
+func mutate(t *testing.T, s *MutationServer, namespace string, pod *corev1.Pod) map[string]json.RawMessage {
+	raw, err := json.Marshal(pod)
+	assert.Nil(t, err)
+	resp, err := s.Mutate(&admissionv1.AdmissionRequest{
+		UID:       "test",
+		Namespace: namespace,
+		Object:    runtime.RawExtension{Raw: raw},
+	})
+	assert.Nil(t, err)
+	assert.True(t, resp.Allowed, "the pods should never be rejected")
+
+	var patches []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Patch, &patches))
+	result := make(map[string]json.RawMessage)
+	for _, p := range patches {
+		assert.Equal(t, "add", p.Op)
+		result[p.Path] = p.Value
+	}
+	return result
+}
+
+func TestMutate_Default(t *testing.T) {
+	s := createFakeMutationServer(t, configv1alpha1.PodMutatorConfig{})
+	patches := mutate(t, s, "team-b", createFakePod(nil))
+	assert.Equal(t, 1, len(patches))
+	var tolerations []corev1.Toleration
+	assert.Nil(t, json.Unmarshal(patches["/spec/tolerations"], &tolerations))
+	assert.Equal(t, []corev1.Toleration{createFakePod(nil).Spec.Tolerations[0], virtualNodeToleration}, tolerations,
+		"the tolerations of the pod should be kept")
+
+	s = createFakeMutationServer(t, configv1alpha1.PodMutatorConfig{DefaultPolicy: configv1alpha1.OffloadingPolicyDeny})
+	assert.Equal(t, 0, len(mutate(t, s, "team-b", createFakePod(nil))))
+}
+
+func TestMutate_Rules(t *testing.T) {
+	s := createFakeMutationServer(t, configv1alpha1.PodMutatorConfig{
+		DefaultPolicy: configv1alpha1.OffloadingPolicyDeny,
+		Rules: []configv1alpha1.OffloadingRule{
+			{
+				Name:        "opt-out",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"liqo.io/offloading": "disabled"}},
+				Policy:      configv1alpha1.OffloadingPolicyDeny,
+			},
+			{
+				Name:              "team-a",
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"liqo.io/offloading": "enabled"}},
+				Policy:            configv1alpha1.OffloadingPolicyOffload,
+				AllowedClusters:   []string{"cluster-1", "cluster-2"},
+				Resources: &configv1alpha1.ResourcesMutation{
+					DefaultRequests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("200Mi"),
+					},
+					RequestsPercentage: 50,
+				},
+			},
+			{
+				Name:       "team-b",
+				Namespaces: []string{"team-b"},
+				Resources: &configv1alpha1.ResourcesMutation{
+					RequestsPercentage: 50,
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, 0, len(mutate(t, s, "team-a", createFakePod(map[string]string{"liqo.io/offloading": "disabled"}))))
+	assert.Equal(t, 0, len(mutate(t, s, "team-c", createFakePod(nil))), "the default policy should be applied")
+	assert.Equal(t, 1, len(mutate(t, s, "team-b", createFakePod(nil))),
+		"the requests of the pods which may run on the local nodes should not be mutated")
+
+	patches := mutate(t, s, "team-a", createFakePod(nil))
+	assert.Equal(t, 3, len(patches))
+	affinity := &corev1.Affinity{}
+	assert.Nil(t, json.Unmarshal(patches["/spec/affinity"], affinity))
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, 1, len(terms), "the pod should be scheduled only on the allowed virtual nodes")
+	assert.Equal(t, virtualKubelet.VirtualNodeClusterIdLabel, terms[0].MatchExpressions[0].Key)
+	assert.Equal(t, []string{"cluster-1", "cluster-2"}, terms[0].MatchExpressions[0].Values)
+
+	resources := corev1.ResourceRequirements{}
+	assert.Nil(t, json.Unmarshal(patches["/spec/containers/0/resources"], &resources))
+	cpu := resources.Requests[corev1.ResourceCPU]
+	memory := resources.Requests[corev1.ResourceMemory]
+	assert.Equal(t, int64(100), cpu.MilliValue())
+	assert.Equal(t, 0, memory.Cmp(resource.MustParse("100Mi")), "the requests should not exceed the limits")
+}
+
+func TestRestrictToClusters(t *testing.T) {
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+				},
+			},
+		},
+	}
+	result := restrictToClusters(affinity, []string{"cluster-1"}, true)
+	terms := result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, 4, len(terms), "the requirements should be added to each term")
+	for _, term := range terms {
+		assert.Equal(t, 2, len(term.MatchExpressions))
+		assert.Equal(t, "zone", term.MatchExpressions[0].Key)
+	}
+
+	// the offloaded pods are scheduled on any virtual node
+	result = restrictToClusters(nil, nil, false)
+	assert.Equal(t, []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: virtualKubelet.VirtualNodeClusterIdLabel, Operator: corev1.NodeSelectorOpExists},
+	}}}, result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+	assert.Equal(t, 2, len(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms),
+		"the affinity of the pod should not be modified")
+}
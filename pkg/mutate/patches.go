@@ -0,0 +1,145 @@
+package mutate
+
+import (
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// the toleration of the taint of the virtual nodes, added to the pods that can be offloaded
+var virtualNodeToleration = corev1.Toleration{
+	Key:      "virtual-node.liqo.io/not-allowed",
+	Operator: "Exists",
+	Effect:   "NoExecute",
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// getPatches returns the JSON patch applying the decision to the pod, the add operations replace the existing values
+func getPatches(pod *corev1.Pod, decision *Decision) []patchOperation {
+	if !decision.Allowed() {
+		return []patchOperation{}
+	}
+	patches := []patchOperation{
+		{
+			Op:    "add",
+			Path:  "/spec/tolerations",
+			Value: addToleration(pod.Spec.Tolerations, virtualNodeToleration),
+		},
+	}
+	if len(decision.AllowedClusters) > 0 || decision.Offloaded() {
+		patches = append(patches, patchOperation{
+			Op:    "add",
+			Path:  "/spec/affinity",
+			Value: restrictToClusters(pod.Spec.Affinity, decision.AllowedClusters, !decision.Offloaded()),
+		})
+	}
+	// the requests are mutated only if the pod cannot run on the local nodes
+	if decision.Resources != nil && decision.Offloaded() {
+		for i := range pod.Spec.InitContainers {
+			patches = append(patches, patchOperation{
+				Op:    "add",
+				Path:  fmt.Sprintf("/spec/initContainers/%d/resources", i),
+				Value: mutateResources(pod.Spec.InitContainers[i].Resources, decision.Resources),
+			})
+		}
+		for i := range pod.Spec.Containers {
+			patches = append(patches, patchOperation{
+				Op:    "add",
+				Path:  fmt.Sprintf("/spec/containers/%d/resources", i),
+				Value: mutateResources(pod.Spec.Containers[i].Resources, decision.Resources),
+			})
+		}
+	}
+	return patches
+}
+
+// addToleration keeps the tolerations of the pod, which would be replaced by the patch
+func addToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) []corev1.Toleration {
+	result := make([]corev1.Toleration, 0, len(tolerations)+1)
+	for _, t := range tolerations {
+		if t.MatchToleration(&toleration) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return append(result, toleration)
+}
+
+// restrictToClusters adds to the node affinity of the pod the requirement to be scheduled on a virtual node of one of
+// the clusters, of any cluster if empty, or on a physical node if local is set. Since the terms of the node affinity
+// are ORed, the requirements are added to each of them.
+func restrictToClusters(affinity *corev1.Affinity, clusters []string, local bool) *corev1.Affinity {
+	result := affinity.DeepCopy()
+	if result == nil {
+		result = &corev1.Affinity{}
+	}
+	if result.NodeAffinity == nil {
+		result.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	var requirements []corev1.NodeSelectorRequirement
+	if local {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      virtualKubelet.VirtualNodeClusterIdLabel,
+			Operator: corev1.NodeSelectorOpDoesNotExist,
+		})
+	}
+	if len(clusters) > 0 {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      virtualKubelet.VirtualNodeClusterIdLabel,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   clusters,
+		})
+	} else {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      virtualKubelet.VirtualNodeClusterIdLabel,
+			Operator: corev1.NodeSelectorOpExists,
+		})
+	}
+	selector := result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	terms := selector.NodeSelectorTerms
+	if len(terms) == 0 {
+		terms = []corev1.NodeSelectorTerm{{}}
+	}
+	selector.NodeSelectorTerms = make([]corev1.NodeSelectorTerm, 0, len(terms)*len(requirements))
+	for _, term := range terms {
+		for _, requirement := range requirements {
+			t := term.DeepCopy()
+			t.MatchExpressions = append(t.MatchExpressions, requirement)
+			selector.NodeSelectorTerms = append(selector.NodeSelectorTerms, *t)
+		}
+	}
+	return result
+}
+
+// mutateResources sets the default requests and scales them, without exceeding the limits
+func mutateResources(resources corev1.ResourceRequirements, mutation *configv1alpha1.ResourcesMutation) corev1.ResourceRequirements {
+	result := *resources.DeepCopy()
+	if result.Requests == nil {
+		result.Requests = corev1.ResourceList{}
+	}
+	for name, quantity := range mutation.DefaultRequests {
+		if _, ok := result.Requests[name]; !ok {
+			result.Requests[name] = quantity.DeepCopy()
+		}
+	}
+	for name, quantity := range result.Requests {
+		if mutation.RequestsPercentage > 0 {
+			quantity = *resource.NewMilliQuantity(quantity.MilliValue()*int64(mutation.RequestsPercentage)/100, quantity.Format)
+		}
+		if limit, ok := result.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+			quantity = limit.DeepCopy()
+		}
+		result.Requests[name] = quantity
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package mutate
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	"sync"
+)
+
+// Decision is the outcome of the evaluation of the offloading policies for a pod
+type Decision struct {
+	// Rule is the name of the matching rule, empty if the default policy has been applied
+	Rule            string
+	Policy          configv1alpha1.OffloadingPolicy
+	AllowedClusters []string
+	Resources       *configv1alpha1.ResourcesMutation
+}
+
+// Allowed returns whether the pod can be scheduled on the virtual nodes
+func (d *Decision) Allowed() bool {
+	return d.Policy != configv1alpha1.OffloadingPolicyDeny
+}
+
+// Offloaded returns whether the pod can be scheduled only on the virtual nodes
+func (d *Decision) Offloaded() bool {
+	return d.Policy == configv1alpha1.OffloadingPolicyOffload
+}
+
+// PolicyEngine evaluates the offloading policies of the PodMutatorConfig of the ClusterConfig, which is updated
+// at runtime. Until the configuration is received all the pods are allowed, as before the policies were introduced.
+type PolicyEngine struct {
+	lock          sync.RWMutex
+	defaultPolicy configv1alpha1.OffloadingPolicy
+	rules         []*offloadingRule
+}
+
+type offloadingRule struct {
+	configv1alpha1.OffloadingRule
+	namespaceSelector labels.Selector
+	podSelector       labels.Selector
+}
+
+func NewPolicyEngine() *PolicyEngine {
+	return &PolicyEngine{
+		defaultPolicy: configv1alpha1.OffloadingPolicyAllow,
+	}
+}
+
+// UpdateConfig replaces the policies with the ones of the ClusterConfig
+func (e *PolicyEngine) UpdateConfig(cfg *configv1alpha1.ClusterConfig) {
+	config := cfg.Spec.PodMutatorConfig.DeepCopy()
+	rules := make([]*offloadingRule, len(config.Rules))
+	for i := range config.Rules {
+		rules[i] = &offloadingRule{
+			OffloadingRule:    config.Rules[i],
+			namespaceSelector: parseSelector(config.Rules[i].NamespaceSelector, config.Rules[i].Name),
+			podSelector:       parseSelector(config.Rules[i].PodSelector, config.Rules[i].Name),
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.defaultPolicy = config.DefaultPolicy
+	if e.defaultPolicy == "" {
+		e.defaultPolicy = configv1alpha1.OffloadingPolicyAllow
+	}
+	e.rules = rules
+	klog.Infof("pod mutator configuration updated: default policy %s, %d rules", e.defaultPolicy, len(e.rules))
+}
+
+// an invalid selector selects nothing, so that a wrong rule is not applied to unwanted pods
+func parseSelector(selector *metav1.LabelSelector, rule string) labels.Selector {
+	if selector == nil {
+		return labels.Everything()
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("invalid selector in offloading rule %s, the rule will not match any pod: %s", rule, err)
+		return labels.Nothing()
+	}
+	return s
+}
+
+// Evaluate returns the decision of the first rule matching the pod, created in the namespace, or the default policy.
+// The labels of the namespace are retrieved only if a rule selects the namespaces by labels.
+func (e *PolicyEngine) Evaluate(pod *corev1.Pod, namespace string, namespaceLabels func(string) (map[string]string, error)) (*Decision, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	var nsLabels labels.Set
+	for _, rule := range e.rules {
+		if !rule.matchesNamespace(namespace) || !rule.podSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if !rule.namespaceSelector.Empty() {
+			if nsLabels == nil {
+				l, err := namespaceLabels(namespace)
+				if err != nil {
+					return nil, err
+				}
+				nsLabels = labels.Set(l)
+			}
+			if !rule.namespaceSelector.Matches(nsLabels) {
+				continue
+			}
+		}
+		policy := rule.Policy
+		if policy == "" {
+			policy = configv1alpha1.OffloadingPolicyAllow
+		}
+		return &Decision{
+			Rule:            rule.Name,
+			Policy:          policy,
+			AllowedClusters: rule.AllowedClusters,
+			Resources:       rule.Resources,
+		}, nil
+	}
+	return &Decision{
+		Policy: e.defaultPolicy,
+	}, nil
+}
+
+func (r *offloadingRule) matchesNamespace(namespace string) bool {
+	if len(r.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range r.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
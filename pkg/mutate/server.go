@@ -1,81 +1,86 @@
 package mutate
 
 import (
-	"fmt"
-	"html"
-	"io/ioutil"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/pkg/clusterConfig"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"github.com/liqotech/liqo/pkg/webhook"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
-	"log"
-	"net/http"
 	"time"
 )
 
+const namespaceResyncPeriod = 10 * time.Minute
+
 type MutationConfig struct {
 	CertFile string
 	KeyFile  string
+	// Addr is the address the server listens on, webhook.DefaultAddr if empty
+	Addr string
 }
 
 type MutationServer struct {
-	mux    *http.ServeMux
-	server *http.Server
+	server *webhook.Server
 
 	config *MutationConfig
+
+	// Policies decide which pods can be offloaded and how they are mutated
+	Policies *PolicyEngine
+	// namespaceLister returns the namespaces whose labels are selected by the policies
+	namespaceLister corev1listers.NamespaceLister
 }
 
 func NewMutationServer(c *MutationConfig) (*MutationServer, error) {
 	s := &MutationServer{}
 	s.config = c
+	s.Policies = NewPolicyEngine()
 
-	s.mux = http.NewServeMux()
-	s.mux.HandleFunc("/", handleRoot)
-	s.mux.HandleFunc("/mutate", s.handleMutate)
-
-	s.server = &http.Server{
-		Addr:           ":8443",
-		Handler:        s.mux,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20, // 1048576
+	var err error
+	if s.server, err = webhook.NewServer(c.Addr, c.CertFile, c.KeyFile); err != nil {
+		return nil, err
 	}
+	s.server.Handle("/mutate", s.Mutate)
 
 	return s, nil
 }
 
-func handleRoot(w http.ResponseWriter, r *http.Request) {
-	_, _ = fmt.Fprintf(w, "hello %q", html.EscapeString(r.URL.Path))
-}
-
-func (s *MutationServer) handleMutate(w http.ResponseWriter, r *http.Request) {
-	// read the body / request
-	body, err := ioutil.ReadAll(r.Body)
-
+// WatchConfiguration updates the policies when the ClusterConfig changes and starts the cache of the namespaces
+func (s *MutationServer) WatchConfiguration(kubeconfigPath string) error {
+	config, err := crdClient.NewKubeconfig(kubeconfigPath, &configv1alpha1.GroupVersion)
 	if err != nil {
-		s.sendError(err, w)
-		return
+		return err
 	}
-
-	// mutate the request
-	mutated, err := s.Mutate(body, true)
+	client, err := crdClient.NewFromConfig(config)
 	if err != nil {
-		s.sendError(err, w)
-		return
+		return err
 	}
+	clusterConfig.WatchConfiguration(s.Policies.UpdateConfig, client, kubeconfigPath)
 
-	// and write it back
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(mutated)
-
-	if err := r.Body.Close(); err != nil {
-		klog.Error("error in body closing")
-	}
+	factory := informers.NewSharedInformerFactory(client.Client(), namespaceResyncPeriod)
+	s.namespaceLister = factory.Core().V1().Namespaces().Lister()
+	factory.Start(wait.NeverStop)
+	factory.WaitForCacheSync(wait.NeverStop)
+	return nil
 }
 
-func (s *MutationServer) sendError(err error, w http.ResponseWriter) {
-	log.Println(err)
-	w.WriteHeader(http.StatusInternalServerError)
-	_, _ = fmt.Fprintf(w, "%s", err)
+// namespaceLabels returns no labels for the namespaces not yet in the cache, which may be behind the API server
+func (s *MutationServer) namespaceLabels(name string) (map[string]string, error) {
+	if s.namespaceLister == nil {
+		return nil, nil
+	}
+	ns, err := s.namespaceLister.Get(name)
+	if kerrors.IsNotFound(err) {
+		klog.Warningf("namespace %s not found in the cache, its labels are not evaluated", name)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ns.Labels, nil
 }
 
 func (s *MutationServer) Serve() {
-	log.Fatal(s.server.ListenAndServeTLS(s.config.CertFile, s.config.KeyFile))
+	klog.Fatal(s.server.ListenAndServe(wait.NeverStop))
 }
@@ -13,7 +13,8 @@ var ApiMapping = map[apimgmt.ApiType]func(reflector ri.APIReflector, opts map[op
 	apimgmt.Pods: func(reflector ri.APIReflector, opts map[options.OptionKey]options.Option) ri.IncomingAPIReflector {
 		return &PodsIncomingReflector{
 			APIReflector:          reflector,
-			RemoteRemappedPodCIDR: opts[types.RemoteRemappedPodCIDR]}
+			RemoteRemappedPodCIDR: opts[types.RemoteRemappedPodCIDR],
+			NodeName:              opts[types.NodeName]}
 	},
 }
 
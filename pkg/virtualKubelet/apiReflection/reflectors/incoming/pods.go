@@ -20,10 +20,12 @@ type PodsIncomingReflector struct {
 	ri.APIReflector
 
 	RemoteRemappedPodCIDR options.ReadOnlyOption
+	NodeName              options.ReadOnlyOption
 }
 
 func (r *PodsIncomingReflector) SetSpecializedPreProcessingHandlers() {
 	r.SetPreProcessingHandlers(ri.PreProcessingHandlers{
+		IsAllowed:  r.isAllowed,
 		AddFunc:    r.PreAdd,
 		UpdateFunc: r.PreUpdate,
 		DeleteFunc: r.PreDelete})
@@ -54,6 +56,20 @@ func (r *PodsIncomingReflector) PreDelete(obj interface{}) interface{} {
 	return r.forgeTranslatedPod(obj)
 }
 
+// only the pods offloaded by this virtual node are reflected, since the foreign namespaces are shared with
+// the virtual nodes of the other partitions of the foreign cluster
+func (r *PodsIncomingReflector) isAllowed(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	po, ok := obj.(*corev1.Pod)
+	if !ok {
+		klog.Error("cannot convert obj to pod")
+		return false
+	}
+	return translation.IsHomeNodePod(po, r.NodeName.Value().ToString())
+}
+
 func (r *PodsIncomingReflector) GetMirroredObject(namespace, name string) interface{} {
 	informer := r.ForeignInformer(namespace)
 	if informer == nil {
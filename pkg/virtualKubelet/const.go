@@ -5,4 +5,8 @@ const (
 	VirtualKubeletPrefix    = "virtual-kubelet-"
 	VirtualKubeletSecPrefix = "vk-kubeconfig-secret-"
 	AdvertisementPrefix     = "advertisement-"
+	CredentialsSecPrefix    = "vk-credentials-"
+
+	// label of the virtual nodes containing the cluster ID of the foreign cluster
+	VirtualNodeClusterIdLabel = "liqo.io/remote-cluster-id"
 )
@@ -0,0 +1,52 @@
+package virtualKubelet
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"regexp"
+	"strings"
+)
+
+var invalidPartitionChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// PartitionSuffix converts the name of a zone or of a node pool into a suffix which can be appended to
+// the names of the virtual-kubelet and of the virtual node
+func PartitionSuffix(partition string) string {
+	return strings.Trim(invalidPartitionChars.ReplaceAllString(strings.ToLower(partition), "-"), "-")
+}
+
+// VirtualKubeletName returns the name of the virtual-kubelet deployment for a foreign cluster
+// partition is the name of the zone or of the node pool mapped on the virtual node, if the foreign cluster is split
+func VirtualKubeletName(clusterId, partition string) string {
+	return withPartitionSuffix(VirtualKubeletPrefix+clusterId, partition)
+}
+
+// VirtualNodeName returns the name of the virtual node for a foreign cluster
+// partition is the name of the zone or of the node pool mapped on the virtual node, if the foreign cluster is split
+func VirtualNodeName(clusterId, partition string) string {
+	return withPartitionSuffix(VirtualNodePrefix+clusterId, partition)
+}
+
+func withPartitionSuffix(name, partition string) string {
+	if suffix := PartitionSuffix(partition); suffix != "" {
+		return name + "-" + suffix
+	}
+	return name
+}
+
+// IsVirtualNodeOf returns true if the node is one of the virtual nodes of the foreign cluster
+func IsVirtualNodeOf(node *corev1.Node, clusterId string) bool {
+	return clusterId != "" && VirtualNodeClusterID(node) == clusterId
+}
+
+// VirtualNodeClusterID returns the id of the foreign cluster backing the virtual node, or an empty string if the
+// node is not a virtual node
+func VirtualNodeClusterID(node *corev1.Node) string {
+	if clusterId, ok := node.Labels[VirtualNodeClusterIdLabel]; ok {
+		return clusterId
+	}
+	// the virtual nodes created before the cluster id label was introduced are named after the cluster
+	if strings.HasPrefix(node.Name, VirtualNodePrefix) {
+		return strings.TrimPrefix(node.Name, VirtualNodePrefix)
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemotePartition describes the subset of the foreign nodes mapped on the virtual node
+type RemotePartition struct {
+	// Resources are the resources made available on the virtual node
+	Resources v1.ResourceList
+	// LargestNodes contains the resources still allocatable on the largest foreign nodes of the partition
+	LargestNodes []v1.ResourceList
+	// Labels are the labels to be added to the virtual node
+	Labels map[string]string
+	// NodeSelector selects the foreign nodes the offloaded pods have to be scheduled on
+	NodeSelector *metav1.LabelSelector
+}
+
+// GetRemotePartition returns the subset of the foreign cluster identified by zone or by nodePool, as announced in the Advertisement
+// the resources announced for the whole cluster are split among the partitions proportionally to their free resources
+// if both zone and nodePool are empty, the partition contains the whole foreign cluster
+func GetRemotePartition(adv *advtypes.Advertisement, zone, nodePool string) *RemotePartition {
+	partition := &RemotePartition{
+		Resources:    adv.Spec.ResourceQuota.Hard.DeepCopy(),
+		LargestNodes: copyResourceLists(adv.Spec.Topology.LargestNodes),
+		Labels:       map[string]string{},
+		NodeSelector: adv.Spec.NodeSelector.DeepCopy(),
+	}
+
+	var labelKey, labelValue, selectorKey string
+	var resources v1.ResourceList
+	var largestNodes []v1.ResourceList
+	total := v1.ResourceList{}
+	switch {
+	case zone != "":
+		// the virtual node has the stable zone label, while the foreign nodes are selected by the label their zone has
+		// been read from, the deprecated one for the nodes of the older kubelets
+		labelKey, labelValue = v1.LabelZoneFailureDomainStable, zone
+		selectorKey = v1.LabelZoneFailureDomainStable
+		for i := range adv.Spec.Topology.Zones {
+			zt := &adv.Spec.Topology.Zones[i]
+			addResources(total, zt.Resources)
+			if zt.Zone == zone {
+				resources, largestNodes = zt.Resources, zt.LargestNodes
+				if zt.Region != "" {
+					partition.Labels[v1.LabelZoneRegionStable] = zt.Region
+				}
+				if zt.ZoneLabel != "" {
+					selectorKey = zt.ZoneLabel
+				}
+			}
+		}
+	case nodePool != "":
+		labelKey, labelValue = adv.Spec.Topology.NodePoolLabel, nodePool
+		selectorKey = labelKey
+		for i := range adv.Spec.Topology.NodePools {
+			pt := &adv.Spec.Topology.NodePools[i]
+			addResources(total, pt.Resources)
+			if pt.Name == nodePool {
+				resources, largestNodes = pt.Resources, pt.LargestNodes
+			}
+		}
+	default:
+		return partition
+	}
+
+	partition.Resources = splitResources(adv.Spec.ResourceQuota.Hard, resources, total)
+	if resources == nil {
+		// the partition is not announced anymore: no pod can fit on it
+		largestNodes = []v1.ResourceList{zeroResources(adv.Spec.ResourceQuota.Hard)}
+	}
+	partition.LargestNodes = copyResourceLists(largestNodes)
+	if labelKey != "" {
+		partition.Labels[labelKey] = labelValue
+		if partition.NodeSelector == nil {
+			partition.NodeSelector = &metav1.LabelSelector{}
+		}
+		partition.NodeSelector.MatchExpressions = append(partition.NodeSelector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      selectorKey,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{labelValue},
+		})
+	}
+	return partition
+}
+
+// get the share of the announced resources corresponding to the part of the total free resources
+func splitResources(announced, part, total v1.ResourceList) v1.ResourceList {
+	shares := v1.ResourceList{}
+	for k, v := range announced {
+		partQnt, ok := part[k]
+		totalQnt, ok2 := total[k]
+		if !ok || !ok2 || totalQnt.Sign() <= 0 {
+			shares[k] = *resource.NewQuantity(0, v.Format)
+			continue
+		}
+		ratio := float64(partQnt.MilliValue()) / float64(totalQnt.MilliValue())
+		shares[k] = *resource.NewMilliQuantity(int64(float64(v.MilliValue())*ratio), v.Format)
+	}
+	return shares
+}
+
+func addResources(dst v1.ResourceList, toAdd v1.ResourceList) {
+	for k, v := range toAdd {
+		if qnt, ok := dst[k]; ok {
+			qnt.Add(v)
+			dst[k] = qnt
+		} else {
+			dst[k] = v.DeepCopy()
+		}
+	}
+}
+
+func zeroResources(resources v1.ResourceList) v1.ResourceList {
+	zero := v1.ResourceList{}
+	for k, v := range resources {
+		zero[k] = *resource.NewQuantity(0, v.Format)
+	}
+	return zero
+}
+
+func copyResourceLists(lists []v1.ResourceList) []v1.ResourceList {
+	if lists == nil {
+		return nil
+	}
+	result := make([]v1.ResourceList, len(lists))
+	for i := range lists {
+		result[i] = lists[i].DeepCopy()
+	}
+	return result
+}
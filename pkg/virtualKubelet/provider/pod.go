@@ -16,6 +16,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
 	stats "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
 	"math/rand"
 	"time"
@@ -36,12 +37,17 @@ func (p *KubernetesProvider) CreatePod(ctx context.Context, pod *v1.Pod) error {
 		return nil
 	}
 
+	if err := CheckPodFitsNode(pod, p.getRemoteLargestNodes()); err != nil {
+		klog.Errorf("pod %v/%v refused: %v", pod.Namespace, pod.Name, err)
+		return err
+	}
+
 	nattedNS, err := p.namespaceMapper.NatNamespace(pod.Namespace, true)
 	if err != nil {
 		return err
 	}
 
-	podTranslated := translation.H2FTranslate(pod, nattedNS)
+	podTranslated := translation.H2FTranslate(pod, nattedNS, p.getRemoteNodeSelector())
 
 	_, err = p.foreignClient.Client().CoreV1().Pods(podTranslated.Namespace).Create(context.TODO(), podTranslated, metav1.CreateOptions{})
 	if err != nil {
@@ -52,6 +58,32 @@ func (p *KubernetesProvider) CreatePod(ctx context.Context, pod *v1.Pod) error {
 	return nil
 }
 
+// CheckPodFitsNode returns an error if no single foreign node among the largest ones has enough allocatable
+// resources for all the requests of the pod, i.e. if it cannot be scheduled on any node of the foreign cluster
+// an empty largestNodes, as announced by a cluster not describing its topology, accepts every pod
+func CheckPodFitsNode(pod *v1.Pod, largestNodes []v1.ResourceList) error {
+	if len(largestNodes) == 0 {
+		return nil
+	}
+	podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+	for _, node := range largestNodes {
+		if podFits(podReqs, node) {
+			return nil
+		}
+	}
+	return errors.Errorf("the pod requests %v, but no foreign node has enough allocatable resources", podReqs)
+}
+
+// the resources not announced for the node are not checked
+func podFits(podReqs, node v1.ResourceList) bool {
+	for k, req := range podReqs {
+		if available, ok := node[k]; ok && req.Cmp(available) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // UpdatePod accepts a Pod definition and updates its reference.
 func (p *KubernetesProvider) UpdatePod(ctx context.Context, pod *v1.Pod) error {
 	return nil
@@ -213,7 +245,11 @@ func (p *KubernetesProvider) GetPods(ctx context.Context) ([]*v1.Pod, error) {
 		}
 
 		for _, pod := range pods {
-			podsHomeOut = append(podsHomeOut, translation.H2FTranslate(pod.(*v1.Pod), foreignNamespace))
+			// the pods offloaded by the virtual nodes of the other partitions share the foreign namespaces
+			if !translation.IsHomeNodePod(pod.(*v1.Pod), p.nodeName.Value().ToString()) {
+				continue
+			}
+			podsHomeOut = append(podsHomeOut, translation.H2FTranslate(pod.(*v1.Pod), foreignNamespace, p.getRemoteNodeSelector()))
 		}
 	}
 
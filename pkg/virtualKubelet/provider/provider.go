@@ -11,10 +11,13 @@ import (
 	"github.com/liqotech/liqo/pkg/virtualKubelet/namespacesMapping"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options"
 	optTypes "github.com/liqotech/liqo/pkg/virtualKubelet/options/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
+	"sync"
 	"time"
 )
 
@@ -43,13 +46,24 @@ type KubernetesProvider struct { // nolint:golint]
 	RemoteRemappedPodCidr options.Option
 	LocalRemappedPodCidr  options.Option
 
+	// remoteZone and remoteNodePool identify the subset of the foreign nodes mapped on the virtual node
+	remoteZone     string
+	remoteNodePool string
+	// remoteNodeSelector selects the foreign nodes the offloaded pods have to be scheduled on, as announced in the Advertisement
+	remoteNodeSelector *metav1.LabelSelector
+	// remoteLargestNodes contains the resources still allocatable on the largest foreign nodes, as announced in the Advertisement
+	remoteLargestNodes []v1.ResourceList
+	remoteAdvMutex     sync.RWMutex
+
 	foreignPodWatcherStop chan struct{}
 	nodeUpdateStop        chan struct{}
 	nodeReady             chan struct{}
 }
 
 // NewKubernetesProviderKubernetesConfig creates a new KubernetesV0Provider. Kubernetes legacy provider does not implement the new asynchronous podnotifier interface
-func NewKubernetesProvider(nodeName, foreignClusterId, homeClusterId string, internalIP string, daemonEndpointPort int32, kubeconfig, remoteKubeConfig string) (*KubernetesProvider, error) {
+// remoteZone and remoteNodePool, if set, restrict the virtual node to a zone or a node pool of the foreign cluster
+func NewKubernetesProvider(nodeName, foreignClusterId, homeClusterId string, internalIP string, daemonEndpointPort int32, kubeconfig, remoteKubeConfig string,
+	remoteZone, remoteNodePool string) (*KubernetesProvider, error) {
 	var err error
 
 	if err = nattingv1.AddToScheme(clientgoscheme.Scheme); err != nil {
@@ -112,6 +126,8 @@ func NewKubernetesProvider(nodeName, foreignClusterId, homeClusterId string, int
 		foreignClient:         foreignClient,
 		advClient:             advClient,
 		tunEndClient:          tepClient,
+		remoteZone:            remoteZone,
+		remoteNodePool:        remoteNodePool,
 
 		RemoteRemappedPodCidr: remoteRemappedPodCIDROpt,
 		LocalRemappedPodCidr:  localRemappedPodCIDROpt,
@@ -143,3 +159,27 @@ func (p *KubernetesProvider) GetApiController() (*controller.Controller, error)
 	}
 	return p.apiController, nil
 }
+
+func (p *KubernetesProvider) getRemoteNodeSelector() *metav1.LabelSelector {
+	p.remoteAdvMutex.RLock()
+	defer p.remoteAdvMutex.RUnlock()
+	return p.remoteNodeSelector.DeepCopy()
+}
+
+func (p *KubernetesProvider) setRemoteNodeSelector(selector *metav1.LabelSelector) {
+	p.remoteAdvMutex.Lock()
+	defer p.remoteAdvMutex.Unlock()
+	p.remoteNodeSelector = selector.DeepCopy()
+}
+
+func (p *KubernetesProvider) getRemoteLargestNodes() []v1.ResourceList {
+	p.remoteAdvMutex.RLock()
+	defer p.remoteAdvMutex.RUnlock()
+	return copyResourceLists(p.remoteLargestNodes)
+}
+
+func (p *KubernetesProvider) setRemoteLargestNodes(largestNodes []v1.ResourceList) {
+	p.remoteAdvMutex.Lock()
+	defer p.remoteAdvMutex.Unlock()
+	p.remoteLargestNodes = copyResourceLists(largestNodes)
+}
@@ -161,6 +161,14 @@ func (p *KubernetesProvider) ReconcileNodeFromTep(event watch.Event) error {
 func (p *KubernetesProvider) updateFromAdv(adv advtypes.Advertisement) error {
 	var err error
 
+	// get the subset of the foreign cluster mapped on this virtual node
+	partition := GetRemotePartition(&adv, p.remoteZone, p.remoteNodePool)
+
+	// the remote pods will be scheduled only on the announced nodes
+	p.setRemoteNodeSelector(partition.NodeSelector)
+	// the pods not fitting on any foreign node will be refused
+	p.setRemoteLargestNodes(partition.LargestNodes)
+
 	var no *v1.Node
 	if no, err = p.homeClient.Client().CoreV1().Nodes().Get(context.TODO(), p.nodeName.Value().ToString(), metav1.GetOptions{}); err != nil {
 		return err
@@ -169,7 +177,7 @@ func (p *KubernetesProvider) updateFromAdv(adv advtypes.Advertisement) error {
 	no.SetAnnotations(map[string]string{
 		"cluster-id": p.foreignClusterId,
 	})
-	no.SetLabels(mergeMaps(no.GetLabels(), adv.Spec.Labels))
+	no.SetLabels(mergeMaps(mergeMaps(no.GetLabels(), adv.Spec.Labels), partition.Labels))
 	no, err = p.homeClient.Client().CoreV1().Nodes().Update(context.TODO(), no, metav1.UpdateOptions{})
 	if err != nil {
 		return err
@@ -181,7 +189,7 @@ func (p *KubernetesProvider) updateFromAdv(adv advtypes.Advertisement) error {
 	if no.Status.Allocatable == nil {
 		no.Status.Allocatable = v1.ResourceList{}
 	}
-	for k, v := range adv.Spec.ResourceQuota.Hard {
+	for k, v := range partition.Resources {
 		no.Status.Capacity[k] = v
 		no.Status.Allocatable[k] = v
 	}
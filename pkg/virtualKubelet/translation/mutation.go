@@ -5,6 +5,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -38,7 +39,16 @@ func F2HTranslate(podForeignIn *v1.Pod, newCidr, namespace string) (podHomeOut *
 	return podHomeOut
 }
 
-func H2FTranslate(pod *v1.Pod, nattedNS string) *v1.Pod {
+// IsHomeNodePod returns true if the home pod of the foreign pod is scheduled on the virtual node nodeName
+// the virtual nodes of the partitions of a foreign cluster share its namespaces, each one managing only its own pods
+func IsHomeNodePod(podForeign *v1.Pod, nodeName string) bool {
+	return podForeign.Annotations["home_nodename"] == nodeName
+}
+
+// H2FTranslate translates a home pod into the pod to be created on the foreign cluster
+// nodeSelector is the selector of the foreign nodes whose resources have been announced: if set, the foreign pod
+// is bound to them by means of node affinity
+func H2FTranslate(pod *v1.Pod, nattedNS string, nodeSelector *metav1.LabelSelector) *v1.Pod {
 	// create an empty ObjectMeta for the output pod, copying only "Name" and "Namespace" fields
 	objectMeta := metav1.ObjectMeta{
 		Name:      pod.ObjectMeta.Name,
@@ -69,13 +79,13 @@ func H2FTranslate(pod *v1.Pod, nattedNS string) *v1.Pod {
 			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
 				NodeSelectorTerms: []v1.NodeSelectorTerm{
 					{
-						MatchExpressions: []v1.NodeSelectorRequirement{
+						MatchExpressions: append([]v1.NodeSelectorRequirement{
 							{
 								Key:      "type",
 								Operator: v1.NodeSelectorOpNotIn,
 								Values:   []string{"virtual-node"},
 							},
-						},
+						}, LabelSelectorToNodeSelectorRequirements(nodeSelector)...),
 					},
 				},
 			},
@@ -110,6 +120,36 @@ func H2FTranslate(pod *v1.Pod, nattedNS string) *v1.Pod {
 	}
 }
 
+// LabelSelectorToNodeSelectorRequirements converts a label selector into the equivalent node selector requirements
+func LabelSelectorToNodeSelectorRequirements(selector *metav1.LabelSelector) []v1.NodeSelectorRequirement {
+	if selector == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for k := range selector.MatchLabels {
+		keys = append(keys, k)
+	}
+	// sort the keys to always produce the same affinity
+	sort.Strings(keys)
+
+	requirements := make([]v1.NodeSelectorRequirement, 0, len(selector.MatchLabels)+len(selector.MatchExpressions))
+	for _, k := range keys {
+		requirements = append(requirements, v1.NodeSelectorRequirement{
+			Key:      k,
+			Operator: v1.NodeSelectorOpIn,
+			Values:   []string{selector.MatchLabels[k]},
+		})
+	}
+	for _, expr := range selector.MatchExpressions {
+		requirements = append(requirements, v1.NodeSelectorRequirement{
+			Key:      expr.Key,
+			Operator: v1.NodeSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return requirements
+}
+
 func translateContainer(container v1.Container, volumes []v1.VolumeMount) v1.Container {
 	return v1.Container{
 		Name:            container.Name,
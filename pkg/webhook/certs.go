@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	"sync"
+	"time"
+)
+
+// CertPollInterval is the period the certificate files are checked for changes at
+var CertPollInterval = 10 * time.Second
+
+// CertWatcher serves the certificate of the webhook and reloads it when its files change, as when the mounted
+// secret is rotated. The files are polled since the secrets are updated by replacing the symlinks of their
+// directory, which is not reliably notified on all the file systems.
+type CertWatcher struct {
+	certFile string
+	keyFile  string
+
+	lock     sync.RWMutex
+	cert     *tls.Certificate
+	certPEM  []byte
+	keyPEM   []byte
+	notAfter time.Time
+}
+
+// NewCertWatcher returns a CertWatcher serving the certificate, which has to be valid
+func NewCertWatcher(certFile, keyFile string) (*CertWatcher, error) {
+	w := &CertWatcher{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := w.reload(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Start polls the certificate files until stop is closed
+func (w *CertWatcher) Start(stop <-chan struct{}) {
+	go wait.Until(func() {
+		if reloaded, err := w.reload(); err != nil {
+			klog.Errorf("unable to reload the certificate %s, the previous one will be served: %v", w.certFile, err)
+		} else if reloaded {
+			klog.Infof("certificate %s reloaded", w.certFile)
+		}
+	}, CertPollInterval, stop)
+}
+
+// reload loads the certificate if its files have changed, returning whether it has been replaced
+func (w *CertWatcher) reload() (bool, error) {
+	certPEM, err := ioutil.ReadFile(w.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := ioutil.ReadFile(w.keyFile)
+	if err != nil {
+		return false, err
+	}
+	w.lock.RLock()
+	unchanged := bytes.Equal(certPEM, w.certPEM) && bytes.Equal(keyPEM, w.keyPEM)
+	w.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	// the files may be read while they are being replaced, in that case the pair is not valid and it is
+	// loaded at the next poll
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.cert = &cert
+	w.certPEM = certPEM
+	w.keyPEM = keyPEM
+	w.notAfter = leaf.NotAfter
+	return true, nil
+}
+
+// GetCertificate returns the current certificate, it is meant to be set in the tls.Config of the server
+func (w *CertWatcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.cert, nil
+}
+
+// Check returns an error if the current certificate has expired
+func (w *CertWatcher) Check() error {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if time.Now().After(w.notAfter) {
+		return fmt.Errorf("the certificate %s expired at %s", w.certFile, w.notAfter)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "liqo_webhook_requests_total",
+		Help: "Number of the admission requests handled by the webhook, by AdmissionReview version and result",
+	}, []string{"webhook", "version", "result"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "liqo_webhook_request_duration_seconds",
+		Help:    "Time spent handling the admission requests",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"webhook"})
+)
+
+const (
+	resultAllowed = "allowed"
+	resultDenied  = "denied"
+	resultError   = "error"
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration)
+}
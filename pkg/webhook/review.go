@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AdmitFunc handles the request of an AdmissionReview. The requests of both the admission/v1 and the
+// admission/v1beta1 versions are converted to admission/v1, since their schema is the same.
+// If an error is returned the webhook replies with an internal server error and the failure policy of the
+// webhook configuration is applied.
+type AdmitFunc func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)
+
+// review decodes the AdmissionReview, calls admit and encodes the response with the version of the request.
+// The reviews without version are handled as admission/v1beta1, which was the only version before v1 was introduced.
+func review(body []byte, admit AdmitFunc) (response []byte, resp *admissionv1.AdmissionResponse, version string, err error) {
+	typeMeta := metav1.TypeMeta{}
+	if err = json.Unmarshal(body, &typeMeta); err != nil {
+		return nil, nil, "", fmt.Errorf("unable to unmarshal the AdmissionReview: %v", err)
+	}
+	version = typeMeta.APIVersion
+	switch version {
+	case admissionv1.SchemeGroupVersion.String():
+	case admissionv1beta1.SchemeGroupVersion.String(), "":
+		version = admissionv1beta1.SchemeGroupVersion.String()
+	default:
+		return nil, nil, version, fmt.Errorf("unsupported AdmissionReview version %s", version)
+	}
+
+	ar := admissionv1.AdmissionReview{}
+	if err = json.Unmarshal(body, &ar); err != nil {
+		return nil, nil, version, fmt.Errorf("unable to unmarshal the AdmissionReview: %v", err)
+	}
+	if ar.Request == nil {
+		return nil, nil, version, fmt.Errorf("the AdmissionReview contains no request")
+	}
+	if resp, err = admit(ar.Request); err != nil {
+		return nil, nil, version, err
+	}
+	resp.UID = ar.Request.UID
+
+	ar = admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: version,
+			Kind:       "AdmissionReview",
+		},
+		Response: resp,
+	}
+	if response, err = json.Marshal(ar); err != nil {
+		return nil, nil, version, err
+	}
+	return response, resp, version, nil
+}
@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"io/ioutil"
+	"k8s.io/klog"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"time"
+)
+
+const (
+	// DefaultAddr is the address the webhooks listen on if not configured
+	DefaultAddr = ":8443"
+
+	shutdownTimeout = 10 * time.Second
+	// the API server rejects the objects larger than 3MiB, and a review can carry both the new and the old object
+	maxRequestSize = 7 << 20
+)
+
+// Server serves the admission webhooks over TLS, together with the /healthz and /readyz probes and the /metrics
+// of the process. The certificate is reloaded when its files change.
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+	certs  *CertWatcher
+}
+
+// NewServer returns a Server listening on addr, it fails if the certificate cannot be loaded
+func NewServer(addr, certFile, keyFile string) (*Server, error) {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	certs, err := NewCertWatcher(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the certificate: %v", err)
+	}
+	s := &Server{
+		mux:   http.NewServeMux(),
+		certs: certs,
+	}
+	s.server = &http.Server{
+		Addr:    addr,
+		Handler: s.mux,
+		TLSConfig: &tls.Config{
+			GetCertificate: certs.GetCertificate,
+		},
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20, // 1048576
+	}
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "ok")
+	})
+	s.mux.HandleFunc("/readyz", s.handleReady)
+	s.mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	return s, nil
+}
+
+// Handle serves the webhook at path, the path labels its metrics
+func (s *Server) Handle(path string, admit AdmitFunc) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		s.handleReview(path, admit, w, r)
+	})
+}
+
+// ListenAndServe serves the webhooks until stop is closed, then it waits for the pending requests
+func (s *Server) ListenAndServe(stop <-chan struct{}) error {
+	s.certs.Start(stop)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.server.Shutdown(ctx); err != nil {
+			klog.Error(err)
+		}
+	}()
+	klog.Infof("serving the webhooks on %s", s.server.Addr)
+	// the certificate is provided by the TLSConfig
+	if err := s.server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// the server is ready as long as it has a valid certificate
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if err := s.certs.Check(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = fmt.Fprint(w, "ok")
+}
+
+func (s *Server) handleReview(path string, admit AdmitFunc, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer func() {
+		requestDuration.WithLabelValues(path).Observe(time.Since(start).Seconds())
+	}()
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		requestsTotal.WithLabelValues(path, "", resultError).Inc()
+		http.Error(w, fmt.Sprintf("invalid Content-Type %s, expected application/json", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		requestsTotal.WithLabelValues(path, "", resultError).Inc()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, resp, version, err := review(body, admit)
+	if err != nil {
+		klog.Errorf("unable to handle the request to %s: %v", path, err)
+		requestsTotal.WithLabelValues(path, version, resultError).Inc()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := resultAllowed
+	if !resp.Allowed {
+		result = resultDenied
+	}
+	requestsTotal.WithLabelValues(path, version, result).Inc()
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(response); err != nil {
+		klog.Errorf("unable to write the response to %s: %v", path, err)
+	}
+}
@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate valid until notAfter
+func writeCertificate(t *testing.T, dir string, notAfter time.Time) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func createFakeServer(t *testing.T) (*Server, string) {
+	dir, err := ioutil.TempDir("", "webhook")
+	assert.Nil(t, err)
+	certFile, keyFile := writeCertificate(t, dir, time.Now().Add(time.Hour))
+	s, err := NewServer("", certFile, keyFile)
+	assert.Nil(t, err)
+	s.Handle("/validate", func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+		switch req.Name {
+		case "error":
+			return nil, errors.New("injected")
+		case "denied":
+			return &admissionv1.AdmissionResponse{Allowed: false}, nil
+		}
+		return &admissionv1.AdmissionResponse{Allowed: true}, nil
+	})
+	return s, dir
+}
+
+func post(t *testing.T, url, body string) (*http.Response, *admissionv1.AdmissionReview) {
+	resp, err := http.Post(url+"/validate", "application/json", strings.NewReader(body))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	review := &admissionv1.AdmissionReview{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(review))
+	return resp, review
+}
+
+func TestServer_Review(t *testing.T) {
+	s, dir := createFakeServer(t)
+	defer os.RemoveAll(dir)
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	_, review := post(t, ts.URL, `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"1","name":"test"}}`)
+	assert.Equal(t, "admission.k8s.io/v1", review.APIVersion)
+	assert.Equal(t, "AdmissionReview", review.Kind)
+	assert.Equal(t, "1", string(review.Response.UID))
+	assert.True(t, review.Response.Allowed)
+
+	_, review = post(t, ts.URL, `{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview","request":{"uid":"2","name":"denied"}}`)
+	assert.Equal(t, "admission.k8s.io/v1beta1", review.APIVersion, "the response should have the version of the request")
+	assert.Equal(t, "2", string(review.Response.UID))
+	assert.False(t, review.Response.Allowed)
+
+	_, review = post(t, ts.URL, `{"request":{"uid":"3","name":"test"}}`)
+	assert.Equal(t, "admission.k8s.io/v1beta1", review.APIVersion, "the reviews without version should be handled as v1beta1")
+
+	resp, _ := post(t, ts.URL, `{"apiVersion":"admission.k8s.io/v2","kind":"AdmissionReview","request":{"uid":"4"}}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	resp, _ = post(t, ts.URL, `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"5","name":"error"}}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	resp, err := http.Post(ts.URL+"/validate", "text/plain", strings.NewReader(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	metrics, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(metrics), `liqo_webhook_requests_total{result="denied",version="admission.k8s.io/v1beta1",webhook="/validate"} 1`)
+	assert.Contains(t, string(metrics), `liqo_webhook_requests_total{result="error",version="admission.k8s.io/v1",webhook="/validate"} 1`)
+	assert.Contains(t, string(metrics), `liqo_webhook_request_duration_seconds_count{webhook="/validate"} 6`)
+
+	resp, _ = post(t, ts.URL, fmt.Sprintf(`{"request":{"uid":"6","name":"%s"}}`, strings.Repeat("a", maxRequestSize)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServer_Probes(t *testing.T) {
+	s, dir := createFakeServer(t)
+	defer os.RemoveAll(dir)
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	for _, probe := range []string{"/healthz", "/readyz"} {
+		resp, err := http.Get(ts.URL + probe)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, probe)
+	}
+
+	//the server is not ready when its certificate has expired
+	writeCertificate(t, dir, time.Now().Add(-time.Hour))
+	reloaded, err := s.certs.reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded)
+	resp, err := http.Get(ts.URL + "/readyz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp, err = http.Get(ts.URL + "/healthz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCertWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeCertificate(t, dir, time.Now().Add(time.Hour))
+
+	w, err := NewCertWatcher(certFile, keyFile)
+	assert.Nil(t, err)
+	first, err := w.GetCertificate(nil)
+	assert.Nil(t, err)
+	reloaded, err := w.reload()
+	assert.Nil(t, err)
+	assert.False(t, reloaded, "the certificate should not be reloaded if its files have not changed")
+
+	//the rotated certificate is served after the next poll
+	CertPollInterval = 10 * time.Millisecond
+	defer func() { CertPollInterval = 10 * time.Second }()
+	stop := make(chan struct{})
+	defer close(stop)
+	w.Start(stop)
+	writeCertificate(t, dir, time.Now().Add(2*time.Hour))
+	assert.Eventually(t, func() bool {
+		cert, _ := w.GetCertificate(nil)
+		return !bytes.Equal(cert.Certificate[0], first.Certificate[0])
+	}, time.Second, 10*time.Millisecond)
+
+	//an invalid certificate is not loaded
+	current, _ := w.GetCertificate(nil)
+	assert.Nil(t, ioutil.WriteFile(certFile, []byte("invalid"), 0600))
+	_, err = w.reload()
+	assert.NotNil(t, err)
+	cert, _ := w.GetCertificate(nil)
+	assert.Equal(t, current, cert)
+
+	_, err = NewCertWatcher(filepath.Join(dir, "missing.pem"), keyFile)
+	assert.NotNil(t, err)
+}
@@ -54,7 +54,7 @@ webhooks:
         apiGroups: ["discovery.liqo.io"]
         apiVersions: ["v1alpha1"]
         resources: ["peeringrequests"]
-    admissionReviewVersions: ["v1"]
+    admissionReviewVersions: ["v1", "v1beta1"]
     sideEffects: None
 EOF
 
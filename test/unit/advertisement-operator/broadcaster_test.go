@@ -16,6 +16,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"strconv"
 	"testing"
 	"time"
@@ -234,7 +235,7 @@ func TestGetNodeImages(t *testing.T) {
 
 func TestComputePrices(t *testing.T) {
 	_, _, images, _, _ := createFakeResources()
-	prices := advop.ComputePrices(images)
+	prices := advop.ComputePrices(images, nil, configv1alpha1.PricingConfig{}, test.ForeignClusterId)
 
 	keys1 := make([]string, len(prices))
 	keys2 := make([]string, len(prices))
@@ -251,6 +252,64 @@ func TestComputePrices(t *testing.T) {
 	assert.ElementsMatch(t, keys1, keys2)
 }
 
+func TestComputePricesWithPricingConfig(t *testing.T) {
+	_, _, images, _, _ := createFakeResources()
+	defaultImagePrice := resource.MustParse("2")
+	pricingConfig := configv1alpha1.PricingConfig{
+		BasePrices: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10"),
+			corev1.ResourceMemory: resource.MustParse("4"),
+		},
+		SurgeThreshold:  50,
+		SurgePercentage: 100,
+		ClusterDiscounts: []configv1alpha1.ClusterDiscount{
+			{ClusterID: test.ForeignClusterId, Percentage: 50},
+		},
+		ImagePrices: []configv1alpha1.ImagePrice{
+			{Prefix: images[0].Names[0], Price: resource.MustParse("8")},
+		},
+		DefaultImagePrice: &defaultImagePrice,
+	}
+	utilisation := map[corev1.ResourceName]int64{
+		corev1.ResourceCPU:    75,
+		corev1.ResourceMemory: 20,
+	}
+
+	prices := advop.ComputePrices(images, utilisation, pricingConfig, test.ForeignClusterId)
+	// cpu: 10 * 1.5 (surge) * 0.5 (discount)
+	assert.Equal(t, int64(7500), prices.Cpu().MilliValue())
+	// memory: utilisation under the threshold, only the discount is applied
+	assert.Equal(t, int64(2000), prices.Memory().MilliValue())
+	for i, image := range images {
+		price := prices[corev1.ResourceName(image.Names[0])]
+		if i == 0 {
+			assert.Equal(t, int64(4000), price.MilliValue())
+		} else {
+			assert.Equal(t, int64(1000), price.MilliValue())
+		}
+	}
+
+	// a cluster without discount gets the full price
+	prices = advop.ComputePrices(images, utilisation, pricingConfig, "another-cluster")
+	assert.Equal(t, int64(15000), prices.Cpu().MilliValue())
+	assert.Equal(t, int64(4000), prices.Memory().MilliValue())
+}
+
+func TestComputeUtilisation(t *testing.T) {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("10Gi"),
+		corev1.ResourcePods:   resource.MustParse("0"),
+	}
+	reqs := corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
+	utilisation := advop.ComputeUtilisation(allocatable, reqs)
+	assert.Equal(t, int64(25), utilisation[corev1.ResourceCPU])
+	assert.Equal(t, int64(0), utilisation[corev1.ResourceMemory])
+	assert.NotContains(t, utilisation, corev1.ResourcePods)
+}
+
 func TestCreateAdvertisement(t *testing.T) {
 	pNodes, vNodes, images, _, pods := createFakeResources()
 	sharingPercentage := int32(50)
@@ -367,3 +426,266 @@ func TestNotifyAdvertisementDeletion(t *testing.T) {
 	_, err = b.RemoteClient.Resource("advertisements").Get(adv2.Name, metav1.GetOptions{})
 	assert.Equal(t, k8serrors.IsNotFound(err), true, "Advertisement has not been deleted")
 }
+
+func TestComputeSharedResources(t *testing.T) {
+	pNodes, _, _, _, pods := createFakeResources()
+	reqs, _ := advop.GetAllPodsResources(pods)
+	half, _ := advop.ComputeAnnouncedResources(pNodes, reqs, 50)
+	whole, _ := advop.ComputeAnnouncedResources(pNodes, reqs, 100)
+
+	percentage := int32(100)
+	maxTotal := int32(100)
+	config := configv1alpha1.BroadcasterConfig{
+		ResourceSharingPercentage: 50,
+		ClusterSharingQuotas: []configv1alpha1.ClusterSharingQuota{
+			{
+				ClusterID:  "cluster-quota",
+				Percentage: &percentage,
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		},
+		MaxTotalSharingPercentage: &maxTotal,
+	}
+
+	// a cluster without quota is offered the global sharing percentage
+	availability, _ := advop.ComputeSharedResources(pNodes, reqs, corev1.ResourceList{}, config, test.ForeignClusterId)
+	assert.Equal(t, half, availability)
+
+	// a cluster with quota is offered its percentage, limited by the absolute values
+	availability, _ = advop.ComputeSharedResources(pNodes, reqs, corev1.ResourceList{}, config, "cluster-quota")
+	assert.Equal(t, int64(2), availability.Cpu().Value())
+	assert.Equal(t, whole.Memory().Value(), availability.Memory().Value())
+
+	// resources already announced to other clusters are not offered again
+	announcedToOthers := whole.DeepCopy()
+	for k, v := range announcedToOthers {
+		v.Sub(*resource.NewQuantity(1, resource.DecimalSI))
+		announcedToOthers[k] = v
+	}
+	availability, _ = advop.ComputeSharedResources(pNodes, reqs, announcedToOthers, config, test.ForeignClusterId)
+	assert.Equal(t, int64(1), availability.Cpu().Value())
+	assert.Equal(t, int64(1), availability.Pods().Value())
+
+	// over-commitment allows to announce more than the free resources
+	maxTotal = 200
+	availability, _ = advop.ComputeSharedResources(pNodes, reqs, whole, config, test.ForeignClusterId)
+	assert.Equal(t, half.Cpu().Value(), availability.Cpu().Value())
+}
+
+func TestGetResourcesAnnouncedToOthers(t *testing.T) {
+	config := createFakeClusterConfig()
+	b := createBroadcaster(config.Spec)
+
+	for _, name := range []string{b.PeeringRequestName, "cluster-1", "cluster-2"} {
+		pr := &discoveryv1alpha1.PeeringRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: discoveryv1alpha1.PeeringRequestStatus{
+				AnnouncedResources: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		}
+		if _, err := b.DiscoveryClient.Resource("peeringrequests").Create(pr, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	announced, err := b.GetResourcesAnnouncedToOthers()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), announced.Cpu().Value())
+}
+
+func TestGetPhysicalNodesSelector(t *testing.T) {
+	pNodes, vNodes, _, _, _ := createFakeResources()
+	pNodes.Items[1].Labels["pool"] = "liqo"
+
+	selector, err := advop.GetPhysicalNodesSelector(nil)
+	assert.Nil(t, err)
+	for _, node := range pNodes.Items {
+		assert.True(t, selector.Matches(labels.Set(node.Labels)))
+	}
+	for _, node := range vNodes.Items {
+		assert.False(t, selector.Matches(labels.Set(node.Labels)))
+	}
+
+	selector, err = advop.GetPhysicalNodesSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"pool": "liqo"}})
+	assert.Nil(t, err)
+	for i, node := range pNodes.Items {
+		assert.Equal(t, i == 1, selector.Matches(labels.Set(node.Labels)))
+	}
+}
+
+func TestFilterPodsByNodes(t *testing.T) {
+	pNodes, _, _, _, pods := createFakeResources()
+	pNodes.Items = pNodes.Items[:2]
+
+	advop.FilterPodsByNodes(pods, pNodes)
+	assert.Len(t, pods.Items, 2)
+	for _, pod := range pods.Items {
+		assert.Contains(t, []string{pNodes.Items[0].Name, pNodes.Items[1].Name}, pod.Spec.NodeName)
+	}
+}
+
+func TestComputeTopology(t *testing.T) {
+	newNode := func(name string, nodeLabels map[string]string, cpu, memory string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	pNodes := &corev1.NodeList{Items: []corev1.Node{
+		newNode("node-a1", map[string]string{corev1.LabelZoneRegionStable: "eu", corev1.LabelZoneFailureDomainStable: "eu-a"}, "4", "8Gi"),
+		newNode("node-a2", map[string]string{corev1.LabelZoneRegionStable: "eu", corev1.LabelZoneFailureDomainStable: "eu-a"}, "2", "16Gi"),
+		// deprecated labels are still honoured
+		newNode("node-b1", map[string]string{corev1.LabelZoneRegion: "eu", corev1.LabelZoneFailureDomain: "eu-b"}, "8", "4Gi"),
+		newNode("node-c1", map[string]string{}, "1", "32Gi"),
+	}}
+	pods := &corev1.PodList{Items: []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-b1"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-b1",
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("6"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					}},
+				}},
+			},
+		},
+	}}
+
+	topology := advop.ComputeTopology(pNodes, pods, "")
+
+	// node-b1 is the largest one, but only 2 cpus are still free, and its memory is over-committed:
+	// it is exceeded by node-a1, while the other nodes have each the most of a resource
+	assert.Equal(t, []corev1.ResourceList{
+		{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+		{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+		{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("32Gi")},
+	}, topology.LargestNodes)
+
+	// the node without zone labels is not part of the breakdown
+	assert.Len(t, topology.Zones, 2)
+	zoneA, zoneB := topology.Zones[0], topology.Zones[1]
+	assert.Equal(t, "eu", zoneA.Region)
+	assert.Equal(t, "eu-a", zoneA.Zone)
+	assert.Equal(t, int32(2), zoneA.Nodes)
+	assert.Equal(t, int64(6), zoneA.Resources.Cpu().Value())
+	assert.Equal(t, resource.MustParse("24Gi"), *zoneA.Resources.Memory())
+	// no single node of the zone has 4 cpus and 16Gi of memory
+	assert.Len(t, zoneA.LargestNodes, 2)
+	assert.Equal(t, int64(4), zoneA.LargestNodes[0].Cpu().Value())
+	assert.Equal(t, resource.MustParse("16Gi"), *zoneA.LargestNodes[1].Memory())
+	assert.Equal(t, corev1.LabelZoneFailureDomainStable, zoneA.ZoneLabel)
+	assert.Equal(t, "eu-b", zoneB.Zone)
+	// the nodes of the zone are selected by the label it has been read from
+	assert.Equal(t, corev1.LabelZoneFailureDomain, zoneB.ZoneLabel)
+	assert.Equal(t, int32(1), zoneB.Nodes)
+	assert.Equal(t, int64(2), zoneB.Resources.Cpu().Value())
+	assert.True(t, zoneB.Resources.Memory().IsZero())
+}
+
+func TestComputeTopologyNodePools(t *testing.T) {
+	pNodes, _, _, _, pods := createFakeResources()
+	pNodes.Items[1].Labels["pool"] = "small"
+	pNodes.Items[2].Labels["pool"] = "big"
+	pNodes.Items[3].Labels["pool"] = "big"
+
+	// no node pool label configured: no breakdown
+	assert.Empty(t, advop.ComputeTopology(pNodes, pods, "").NodePools)
+
+	topology := advop.ComputeTopology(pNodes, pods, "pool")
+	assert.Equal(t, "pool", topology.NodePoolLabel)
+	assert.Len(t, topology.NodePools, 2)
+	big, small := topology.NodePools[0], topology.NodePools[1]
+	assert.Equal(t, "big", big.Name)
+	assert.Equal(t, int32(2), big.Nodes)
+	assert.Equal(t, int64(4+6), big.Resources.Cpu().Value())
+	assert.Equal(t, int64(6), big.LargestNodes[0].Cpu().Value())
+	assert.Equal(t, "small", small.Name)
+	assert.Equal(t, int32(1), small.Nodes)
+	assert.Equal(t, int64(2), small.Resources.Cpu().Value())
+	// the largest node of the cluster is not part of any node pool
+	assert.Equal(t, int64(8), topology.LargestNodes[0].Cpu().Value())
+}
+
+func TestComputeTopologyNodeShapes(t *testing.T) {
+	pNodes := &corev1.NodeList{}
+	for i := 1; i <= 16; i++ {
+		pNodes.Items = append(pNodes.Items, corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(int64(i), resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(int64(17-i)<<30, resource.BinarySI),
+				},
+			},
+		})
+	}
+
+	topology := advop.ComputeTopology(pNodes, &corev1.PodList{}, "")
+
+	// no node exceeds another one: all of them are announced, the memory-heavy ones too
+	assert.Len(t, topology.LargestNodes, 16)
+	assert.Equal(t, int64(16), topology.LargestNodes[0].Cpu().Value())
+	assert.Equal(t, resource.MustParse("16Gi"), *topology.LargestNodes[15].Memory())
+}
+
+func TestReserveResources(t *testing.T) {
+	config := createFakeClusterConfig()
+	b := createBroadcaster(config.Spec)
+	cpu := func(value string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}
+	}
+	announced := map[string]corev1.ResourceList{b.PeeringRequestName: cpu("3"), "cluster-1": cpu("4")}
+	for name, resources := range announced {
+		pr := &discoveryv1alpha1.PeeringRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: discoveryv1alpha1.PeeringRequestStatus{
+				AnnouncedResources: resources,
+			},
+		}
+		if _, err := b.DiscoveryClient.Resource("peeringrequests").Create(pr, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getReserved := func() *resource.Quantity {
+		tmp, err := b.DiscoveryClient.Resource("peeringrequests").Get(b.PeeringRequestName, metav1.GetOptions{})
+		assert.Nil(t, err)
+		return tmp.(*discoveryv1alpha1.PeeringRequest).Status.AnnouncedResources.Cpu()
+	}
+
+	// the resources fitting the budget are reserved, the previously announced ones stay reserved until the new
+	// Advertisement is sent
+	reserved, err := b.ReserveResources(cpu("2"), cpu("10"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), reserved.Cpu().Value())
+	assert.Equal(t, int64(3), getReserved().Value())
+
+	// the resources reserved by the other broadcasters are not reserved again
+	reserved, err = b.ReserveResources(cpu("8"), cpu("10"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), reserved.Cpu().Value())
+	assert.Equal(t, int64(6), getReserved().Value())
+
+	// the announced resources release the rest of the reservation
+	adv := prepareAdv(&b)
+	adv.Spec.ResourceQuota.Hard = reserved
+	assert.Nil(t, b.SaveAnnouncedResources(&adv))
+	reserved, err = b.ReserveResources(cpu("1"), cpu("10"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), reserved.Cpu().Value())
+	assert.Equal(t, int64(6), getReserved().Value())
+}
@@ -46,6 +46,7 @@ func TestCheckAdvertisement(t *testing.T) {
 	t.Run("testAutoAcceptMax", testAutoAcceptMax)
 	t.Run("testManualAccept", testManualAccept)
 	t.Run("testRefuseInvalidAdvertisement", testRefuseInvalidAdvertisement)
+	t.Run("testRefuseExpensiveAdvertisement", testRefuseExpensiveAdvertisement)
 }
 
 func testAutoAcceptMax(t *testing.T) {
@@ -98,3 +99,28 @@ func testRefuseInvalidAdvertisement(t *testing.T) {
 	// check that the Adv counter has not been incremented
 	assert.Equal(t, int32(0), r.AcceptedAdvNum)
 }
+
+func testRefuseExpensiveAdvertisement(t *testing.T) {
+	r := createReconciler(0, 10, configv1alpha1.AutoAcceptMax)
+	r.ClusterConfig.IngoingConfig.MaxAcceptablePrices = v12.ResourceList{
+		v12.ResourceCPU: resource.MustParse("2"),
+	}
+
+	// create an Advertisement with a price higher than the maximum and check it is refused
+	adv := createFakeAdv("cluster-expensive", "default")
+	adv.Spec.Prices = v12.ResourceList{
+		v12.ResourceCPU: resource.MustParse("3"),
+	}
+	r.CheckAdvertisement(adv)
+	assert.Equal(t, advtypes.AdvertisementRefused, adv.Status.AdvertisementStatus)
+	assert.Equal(t, int32(0), r.AcceptedAdvNum)
+
+	// create an Advertisement with an acceptable price and check it is accepted
+	adv = createFakeAdv("cluster-cheap", "default")
+	adv.Spec.Prices = v12.ResourceList{
+		v12.ResourceCPU: resource.MustParse("1"),
+	}
+	r.CheckAdvertisement(adv)
+	assert.Equal(t, advtypes.AdvertisementAccepted, adv.Status.AdvertisementStatus)
+	assert.Equal(t, int32(1), r.AcceptedAdvNum)
+}
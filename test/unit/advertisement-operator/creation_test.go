@@ -2,6 +2,7 @@ package advertisement_operator
 
 import (
 	"context"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	pkg "github.com/liqotech/liqo/pkg/advertisement-operator"
 	"github.com/stretchr/testify/assert"
@@ -123,7 +124,7 @@ func TestCreateVkDeployment(t *testing.T) {
 	initVkImage := "liqo/init-vk"
 	homeClusterId := "cluster2"
 
-	deploy := pkg.CreateVkDeployment(adv, vkName, vkNamespace, vkImage, initVkImage, nodeName, homeClusterId)
+	deploy := pkg.CreateVkDeployment(adv, pkg.VirtualNodePartition{}, vkName, vkNamespace, vkImage, initVkImage, nodeName, homeClusterId)
 
 	assert.Equal(t, vkName, deploy.Name)
 	assert.Equal(t, vkNamespace, deploy.Namespace)
@@ -199,3 +200,43 @@ func testAdvertisement(t *testing.T, c client.Client) {
 	//TODO: update test
 	// adv creation is not effective and Get returns an error
 }
+
+func TestCreateVkDeploymentForPartition(t *testing.T) {
+	adv := createFakeAdv("advertisement-cluster1", "fakens")
+	partition := pkg.VirtualNodePartition{Zone: "eu-west-1a"}
+	vkName := partition.VirtualKubeletName(adv.Spec.ClusterId)
+	nodeName := partition.VirtualNodeName(adv.Spec.ClusterId)
+
+	assert.Equal(t, "virtual-kubelet-cluster1-eu-west-1a", vkName)
+	assert.Equal(t, "liqo-cluster1-eu-west-1a", nodeName)
+
+	deploy := pkg.CreateVkDeployment(adv, partition, vkName, "fake", "liqo/virtual-kubelet", "liqo/init-vk", nodeName, "cluster2")
+	args := deploy.Spec.Template.Spec.Containers[0].Args
+	assert.Contains(t, args, "--remote-zone")
+	assert.Contains(t, args, "eu-west-1a")
+	assert.NotContains(t, args, "--remote-node-pool")
+	assert.Contains(t, args, nodeName)
+}
+
+func TestGetVirtualNodePartitions(t *testing.T) {
+	adv := createFakeAdv("advertisement-cluster1", "fakens")
+
+	// no topology announced: a single virtual node
+	assert.Equal(t, []pkg.VirtualNodePartition{{}}, pkg.GetVirtualNodePartitions(adv, configv1alpha1.SplitByZone))
+
+	adv.Spec.Topology = advtypes.AdvertisementTopology{
+		Zones: []advtypes.ZoneTopology{
+			{Region: "eu", Zone: "eu-A"},
+			// mapped on the same suffix as the previous one
+			{Region: "eu", Zone: "eu_a"},
+			{Region: "eu", Zone: "eu-b"},
+		},
+		NodePoolLabel: "pool",
+		NodePools:     []advtypes.NodePoolTopology{{Name: "gpu"}},
+	}
+	assert.Equal(t, []pkg.VirtualNodePartition{{}}, pkg.GetVirtualNodePartitions(adv, configv1alpha1.SplitNone))
+	assert.Equal(t, []pkg.VirtualNodePartition{{}}, pkg.GetVirtualNodePartitions(adv, ""))
+	assert.Equal(t, []pkg.VirtualNodePartition{{Zone: "eu-A"}, {Zone: "eu-b"}}, pkg.GetVirtualNodePartitions(adv, configv1alpha1.SplitByZone))
+	assert.Equal(t, []pkg.VirtualNodePartition{{NodePool: "gpu"}}, pkg.GetVirtualNodePartitions(adv, configv1alpha1.SplitByNodePool))
+	assert.Equal(t, "liqo-cluster1-eu-a", pkg.VirtualNodePartition{Zone: "eu-A"}.VirtualNodeName(adv.Spec.ClusterId))
+}
@@ -73,7 +73,7 @@ func startDispatcherOperator() {
 		QPS:   1000.0,
 		Burst: 2000.0,
 	}
-	err = dOperator.WatchConfiguration(newConfig, &configv1alpha1.GroupVersion)
+	err = dOperator.WatchConfiguration(context.TODO(), newConfig, &configv1alpha1.GroupVersion)
 	if err != nil {
 		klog.Errorf("an error occurred while starting the configuration watcher of crdReplicator operator: %s", err)
 		os.Exit(-1)
@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"context"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery/kubeconfig"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"gotest.tools/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCredentials(t *testing.T) {
+	t.Run("testShortLivedCredentials", testShortLivedCredentials)
+	t.Run("testCredentialsRotation", testCredentialsRotation)
+	t.Run("testTokenReload", testTokenReload)
+}
+
+// ------
+// tests that tokens with the configured lifetime are requested, bound to the given Secret
+func testShortLivedCredentials(t *testing.T) {
+	server, ca := newApiServer()
+	defer server.Close()
+
+	clientset := newServiceAccountClient(t).(*fake.Clientset)
+	var request *authenticationv1.TokenRequest
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request = action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		res := request.DeepCopy()
+		res.Status = authenticationv1.TokenRequestStatus{
+			Token:               "short-lived-token",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second)),
+		}
+		return true, res, nil
+	})
+
+	apiServerConfig := &configv1alpha1.ApiServerConfig{
+		Endpoints:      []string{server.URL},
+		CaData:         ca,
+		CredentialsTtl: 600,
+	}
+	boundTo := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "credentials",
+			UID:  "credentials-uid",
+		},
+	}
+	kc, expiration, err := kubeconfig.CreateKubeConfig(clientset, apiServerConfig, "sa", "default", boundTo)
+	assert.NilError(t, err)
+	assert.Assert(t, request != nil, "the token has not been requested")
+	assert.Equal(t, *request.Spec.ExpirationSeconds, int64(600))
+	assert.Equal(t, request.Spec.BoundObjectRef.Name, boundTo.Name)
+	assert.Equal(t, request.Spec.BoundObjectRef.UID, boundTo.UID)
+	assert.Assert(t, expiration != nil)
+	assert.Equal(t, getKubeconfigToken(t, kc), "short-lived-token")
+
+	// without a lifetime, the long-lived token of the ServiceAccount is used
+	apiServerConfig.CredentialsTtl = 0
+	kc, expiration, err = kubeconfig.CreateKubeConfig(clientset, apiServerConfig, "sa", "default", nil)
+	assert.NilError(t, err)
+	assert.Assert(t, expiration == nil)
+	assert.Equal(t, getKubeconfigToken(t, kc), "token")
+}
+
+// ------
+// tests that credentials are rotated when 80% of their lifetime elapsed
+func testCredentialsRotation(t *testing.T) {
+	expiration := metav1.NewTime(time.Now().Add(10 * time.Minute))
+	assert.Equal(t, kubeconfig.RotationTime(&expiration, 600), expiration.Add(-2*time.Minute))
+
+	assert.Assert(t, !kubeconfig.NeedsRotation(nil, 0), "long-lived credentials are never rotated")
+	assert.Assert(t, kubeconfig.NeedsRotation(nil, 600), "long-lived credentials are replaced when a lifetime is set")
+	assert.Assert(t, !kubeconfig.NeedsRotation(&expiration, 600))
+	expiring := metav1.NewTime(time.Now().Add(1 * time.Minute))
+	assert.Assert(t, kubeconfig.NeedsRotation(&expiring, 600))
+}
+
+// ------
+// tests that the clients use the rotated token stored in the kubeconfig Secret
+func testTokenReload(t *testing.T) {
+	tokens := make(chan string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tokens <- req.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientset := fake.NewSimpleClientset()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubeconfig",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"kubeconfig": newTokenKubeconfig(t, server.URL, "old-token"),
+		},
+	}
+	secret, err := clientset.CoreV1().Secrets("default").Create(context.TODO(), secret, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	config, err := crdClient.NewKubeconfigFromSecret(secret, &discoveryv1alpha1.GroupVersion)
+	assert.NilError(t, err)
+	config.Insecure = true
+	crdClient.ReloadTokenFromSecret(config, clientset, secret.Namespace, secret.Name)
+
+	// the token is rotated after the client has been created
+	secret.Data["kubeconfig"] = newTokenKubeconfig(t, server.URL, "new-token")
+	_, err = clientset.CoreV1().Secrets("default").Update(context.TODO(), secret, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	client, err := kubernetes.NewForConfig(config)
+	assert.NilError(t, err)
+	_, _ = client.CoreV1().Namespaces().Get(context.TODO(), "default", metav1.GetOptions{})
+	assert.Equal(t, <-tokens, "Bearer new-token")
+}
+
+func getKubeconfigToken(t *testing.T, kc string) string {
+	cnf, err := clientcmd.Load([]byte(kc))
+	assert.NilError(t, err)
+	return cnf.AuthInfos[cnf.Contexts[cnf.CurrentContext].AuthInfo].Token
+}
+
+func newTokenKubeconfig(t *testing.T, server string, token string) []byte {
+	cnf := clientcmdapi.NewConfig()
+	cnf.Clusters["cluster"] = &clientcmdapi.Cluster{
+		Server: server,
+	}
+	cnf.AuthInfos["user"] = &clientcmdapi.AuthInfo{
+		Token: token,
+	}
+	cnf.Contexts["context"] = &clientcmdapi.Context{
+		Cluster:  "cluster",
+		AuthInfo: "user",
+	}
+	cnf.CurrentContext = "context"
+	kc, err := clientcmd.Write(*cnf)
+	assert.NilError(t, err)
+	return kc
+}
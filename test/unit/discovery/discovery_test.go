@@ -134,7 +134,7 @@ func testDiscoveryConfig(t *testing.T) {
 	policyConfig.GroupVersion = &configv1alpha1.GroupVersion
 	client, err := crdClient.NewFromConfig(&policyConfig)
 	assert.NilError(t, err, "Can't get CRDClient")
-	err = clientCluster.discoveryCtrl.GetDiscoveryConfig(client, "")
+	err = clientCluster.discoveryCtrl.GetDiscoveryConfig(context.TODO(), client, "")
 	assert.NilError(t, err, "DiscoveryCtrl can't load settings")
 
 	tmp, err := client.Resource("clusterconfigs").Get("configuration", metav1.GetOptions{})
@@ -424,7 +424,7 @@ func testCreateKubeconfig(t *testing.T) {
 	assert.NilError(t, err)
 
 	// test
-	kc, err := kubeconfig.CreateKubeConfig(clientCluster.client.Client(), sa.Name, "default")
+	kc, _, err := kubeconfig.CreateKubeConfig(clientCluster.client.Client(), nil, sa.Name, "default", nil)
 	assert.NilError(t, err)
 	assert.Assert(t, strings.Contains(kc, "127.0.0.2"), "API server ip not set")
 	assert.Assert(t, strings.Contains(kc, "6443"), "default port not set")
@@ -432,7 +432,7 @@ func testCreateKubeconfig(t *testing.T) {
 	err = os.Setenv("APISERVER_PORT", "1234")
 	assert.NilError(t, err)
 
-	kc, err = kubeconfig.CreateKubeConfig(clientCluster.client.Client(), sa.Name, "default")
+	kc, _, err = kubeconfig.CreateKubeConfig(clientCluster.client.Client(), nil, sa.Name, "default", nil)
 	assert.NilError(t, err)
 	assert.Assert(t, strings.Contains(kc, "1234"), "non-default port not set")
 }
@@ -0,0 +1,214 @@
+package discovery
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery"
+	search_domain_operator "github.com/liqotech/liqo/internal/discovery/search-domain-operator"
+	"github.com/miekg/dns"
+	"gotest.tools/assert"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const publicationZone = "published.liqo.io."
+
+// in-process DNS server accepting dynamic updates (RFC 2136) authenticated with TSIG
+type updatableZone struct {
+	mtx     sync.Mutex
+	records []dns.RR
+}
+
+func (z *updatableZone) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(r)
+
+	z.mtx.Lock()
+	switch r.Opcode {
+	case dns.OpcodeUpdate:
+		if r.IsTsig() == nil || w.TsigStatus() != nil {
+			msg.Rcode = dns.RcodeRefused
+			break
+		}
+		for _, rr := range r.Ns {
+			z.update(rr)
+		}
+	case dns.OpcodeQuery:
+		q := r.Question[0]
+		for _, rr := range z.records {
+			if strings.EqualFold(rr.Header().Name, q.Name) && rr.Header().Rrtype == q.Qtype {
+				msg.Answer = append(msg.Answer, rr)
+			}
+		}
+	}
+	z.mtx.Unlock()
+
+	if tsig := r.IsTsig(); tsig != nil {
+		msg.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+	_ = w.WriteMsg(msg)
+}
+
+// apply an update RR, as described in RFC 2136 section 2.5
+func (z *updatableZone) update(rr dns.RR) {
+	hdr := rr.Header()
+	switch hdr.Class {
+	case dns.ClassANY:
+		// delete a RRset, or all the RRsets of a name
+		z.filter(func(old dns.RR) bool {
+			return strings.EqualFold(old.Header().Name, hdr.Name) && (hdr.Rrtype == dns.TypeANY || old.Header().Rrtype == hdr.Rrtype)
+		})
+	case dns.ClassNONE:
+		// delete a RR
+		z.filter(func(old dns.RR) bool {
+			return dns.IsDuplicate(old, withClass(rr, dns.ClassINET))
+		})
+	default:
+		for _, old := range z.records {
+			if dns.IsDuplicate(old, rr) {
+				return
+			}
+		}
+		z.records = append(z.records, rr)
+	}
+}
+
+func (z *updatableZone) filter(remove func(dns.RR) bool) {
+	var records []dns.RR
+	for _, rr := range z.records {
+		if !remove(rr) {
+			records = append(records, rr)
+		}
+	}
+	z.records = records
+}
+
+func (z *updatableZone) count() int {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	return len(z.records)
+}
+
+func (z *updatableZone) contains(rr dns.RR) bool {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	for _, old := range z.records {
+		if dns.IsDuplicate(old, rr) {
+			return true
+		}
+	}
+	return false
+}
+
+func withClass(rr dns.RR, class uint16) dns.RR {
+	rr = dns.Copy(rr)
+	rr.Header().Class = class
+	return rr
+}
+
+func TestDnsPublisher(t *testing.T) {
+	// another cluster already published in the zone
+	other, err := dns.NewRR(publicationZone + " 60 IN PTR other." + publicationZone)
+	assert.NilError(t, err)
+	zone := &updatableZone{records: []dns.RR{other}}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		Handler:           zone,
+		TsigSecret:        map[string]string{tsigKeyName: tsigSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// the default function refuses the dynamic updates
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	defer func() {
+		_ = server.Shutdown()
+	}()
+
+	config := &configv1alpha1.DnsPublicationConfig{
+		Zone:   publicationZone,
+		Server: conn.LocalAddr().String(),
+		Ttl:    60,
+		Tsig: &configv1alpha1.TsigConfig{
+			KeyName:   tsigKeyName,
+			Algorithm: "hmac-sha256",
+		},
+	}
+
+	t.Run("testPublish", func(t *testing.T) {
+		publisher, err := discovery.NewDnsPublisher(config, tsigSecret)
+		assert.NilError(t, err)
+		assert.Equal(t, publisher.RefreshPeriod(), 30*time.Second)
+
+		txtData := &discovery.TxtData{
+			ID:        "published-cluster",
+			Name:      "published",
+			Namespace: "liqo",
+			ApiUrl:    "https://10.0.0.1:6443",
+		}
+		assert.NilError(t, publisher.Publish(txtData))
+		// publishing again replaces the previous records
+		txtData.ApiUrl = "https://api.published.example.com:8443"
+		assert.NilError(t, publisher.Publish(txtData))
+
+		resolver, err := search_domain_operator.NewDnsResolver(conn.LocalAddr().String(), nil, "")
+		assert.NilError(t, err)
+		txt, err := resolver.ResolveWan(&dns.PTR{Ptr: "published-cluster." + publicationZone})
+		assert.NilError(t, err)
+		assert.Equal(t, txt.ID, "published-cluster")
+		assert.Equal(t, txt.Name, "published")
+		assert.Equal(t, txt.Namespace, "liqo")
+		assert.Equal(t, txt.ApiUrl, "https://api.published.example.com.:8443")
+		assert.Equal(t, txt.Ttl, uint32(60))
+		// 2 PTR, 1 SRV and 1 TXT: the A record for the old IP address has been removed
+		assert.Equal(t, zone.count(), 4)
+
+		assert.NilError(t, publisher.Withdraw())
+		assert.Equal(t, zone.count(), 1)
+		assert.Assert(t, zone.contains(other))
+	})
+
+	t.Run("testPublishIP", func(t *testing.T) {
+		publisher, err := discovery.NewDnsPublisher(config, tsigSecret)
+		assert.NilError(t, err)
+		assert.NilError(t, publisher.Publish(&discovery.TxtData{
+			ID:        "published-cluster",
+			Namespace: "liqo",
+			ApiUrl:    "https://10.0.0.1:6443",
+		}))
+
+		resolver, err := search_domain_operator.NewDnsResolver(conn.LocalAddr().String(), nil, "")
+		assert.NilError(t, err)
+		txt, err := resolver.ResolveWan(&dns.PTR{Ptr: "published-cluster." + publicationZone})
+		assert.NilError(t, err)
+		assert.Equal(t, txt.ApiUrl, "https://published-cluster."+publicationZone+":6443")
+		answer, _, err := resolver.Query("published-cluster."+publicationZone, dns.TypeA)
+		assert.NilError(t, err)
+		assert.Equal(t, len(answer), 1)
+		assert.Equal(t, answer[0].(*dns.A).A.String(), "10.0.0.1")
+
+		assert.NilError(t, publisher.Withdraw())
+		assert.Equal(t, zone.count(), 1)
+	})
+
+	t.Run("testPublishWrongKey", func(t *testing.T) {
+		publisher, err := discovery.NewDnsPublisher(config, "d3Jvbmcta2V5")
+		assert.NilError(t, err)
+		assert.Assert(t, publisher.Publish(&discovery.TxtData{
+			ID:        "published-cluster",
+			Namespace: "liqo",
+			ApiUrl:    "https://10.0.0.1:6443",
+		}) != nil)
+		assert.Equal(t, zone.count(), 1)
+	})
+}
@@ -0,0 +1,267 @@
+package discovery
+
+import (
+	"crypto"
+	"encoding/base64"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	search_domain_operator "github.com/liqotech/liqo/internal/discovery/search-domain-operator"
+	"github.com/miekg/dns"
+	"gotest.tools/assert"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	signedDomain = "signed.liqo.io."
+	tsigKeyName  = "liqo-key."
+)
+
+var tsigSecret = base64.StdEncoding.EncodeToString([]byte("liqo-tsig-test-secret"))
+
+// in-process DNS server serving a zone signed with DNSSEC
+type signedZone struct {
+	key     *dns.DNSKEY
+	signer  crypto.Signer
+	records map[string][]dns.RR
+	// if set, the TXT records are altered after having been signed
+	tamper bool
+	// if set, the answers are not signed with TSIG
+	skipTsig bool
+}
+
+func newSignedZone(t *testing.T) *signedZone {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: signedDomain, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	assert.NilError(t, err)
+
+	zone := &signedZone{
+		key:     key,
+		signer:  priv.(crypto.Signer),
+		records: map[string][]dns.RR{},
+	}
+	for _, rr := range []string{
+		signedDomain + " 60 IN PTR cluster1." + signedDomain,
+		"cluster1." + signedDomain + " 60 IN SRV 0 0 6443 api.cluster1." + signedDomain,
+		"cluster1." + signedDomain + ` 60 IN TXT "id=dnssec-cluster" "namespace=default"`,
+	} {
+		record, err := dns.NewRR(rr)
+		assert.NilError(t, err)
+		zone.add(record)
+	}
+	zone.add(key)
+	return zone
+}
+
+func (z *signedZone) add(rr dns.RR) {
+	key := strings.ToLower(rr.Header().Name) + "/" + dns.TypeToString[rr.Header().Rrtype]
+	z.records[key] = append(z.records[key], rr)
+}
+
+func (z *signedZone) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(r)
+	msg.Authoritative = true
+	q := r.Question[0]
+	rrset := z.records[strings.ToLower(q.Name)+"/"+dns.TypeToString[q.Qtype]]
+	if len(rrset) > 0 {
+		sig := &dns.RRSIG{
+			Hdr:        dns.RR_Header{Name: q.Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 60},
+			Algorithm:  z.key.Algorithm,
+			SignerName: signedDomain,
+			KeyTag:     z.key.KeyTag(),
+			Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+			Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+		}
+		_ = sig.Sign(z.signer, rrset)
+		for _, rr := range rrset {
+			msg.Answer = append(msg.Answer, dns.Copy(rr))
+		}
+		msg.Answer = append(msg.Answer, sig)
+		if z.tamper && q.Qtype == dns.TypeTXT {
+			msg.Answer[0].(*dns.TXT).Txt[0] = "id=tampered-cluster"
+		}
+	}
+	if tsig := r.IsTsig(); tsig != nil && !z.skipTsig {
+		msg.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+	_ = w.WriteMsg(msg)
+}
+
+func setupSignedDNSServer(t *testing.T, zone *signedZone) (string, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		Handler:           zone,
+		TsigSecret:        map[string]string{tsigKeyName: tsigSecret},
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	return conn.LocalAddr().String(), func() {
+		_ = server.Shutdown()
+	}
+}
+
+func TestDnssec(t *testing.T) {
+	t.Run("testDnssecValidated", testDnssecValidated)
+	t.Run("testDnssecDsAnchor", testDnssecDsAnchor)
+	t.Run("testDnssecTampered", testDnssecTampered)
+	t.Run("testDnssecWrongAnchor", testDnssecWrongAnchor)
+	t.Run("testDnssecNotConfigured", testDnssecNotConfigured)
+	t.Run("testTsig", testTsig)
+}
+
+// ------
+// tests that the records signed by the trusted key are validated
+func testDnssecValidated(t *testing.T) {
+	zone := newSignedZone(t)
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	resolver, err := search_domain_operator.NewDnsResolver(addr, &configv1alpha1.DnssecConfig{
+		TrustAnchors: []string{zone.key.String()},
+	}, "")
+	assert.NilError(t, err)
+
+	txts, err := resolver.Wan(signedDomain, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Equal(t, txts[0].ID, "dnssec-cluster")
+	assert.Equal(t, txts[0].ApiUrl, "https://api.cluster1."+signedDomain+":6443")
+	assert.Assert(t, txts[0].DnssecChecked)
+	assert.Assert(t, txts[0].DnssecValidated)
+}
+
+// ------
+// tests that a DS record can be used as trust anchor
+func testDnssecDsAnchor(t *testing.T) {
+	zone := newSignedZone(t)
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	resolver, err := search_domain_operator.NewDnsResolver(addr, &configv1alpha1.DnssecConfig{
+		TrustAnchors: []string{zone.key.ToDS(dns.SHA256).String()},
+	}, "")
+	assert.NilError(t, err)
+
+	txts, err := resolver.Wan(signedDomain, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Assert(t, txts[0].DnssecValidated)
+}
+
+// ------
+// tests that altered records are not validated, and ignored when DNSSEC is required
+func testDnssecTampered(t *testing.T) {
+	zone := newSignedZone(t)
+	zone.tamper = true
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	resolver, err := search_domain_operator.NewDnsResolver(addr, &configv1alpha1.DnssecConfig{
+		TrustAnchors: []string{zone.key.String()},
+	}, "")
+	assert.NilError(t, err)
+
+	txts, err := resolver.Wan(signedDomain, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Assert(t, txts[0].DnssecChecked)
+	assert.Assert(t, !txts[0].DnssecValidated)
+
+	txts, err = resolver.Wan(signedDomain, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 0)
+}
+
+// ------
+// tests that the records signed by an untrusted key are not validated
+func testDnssecWrongAnchor(t *testing.T) {
+	zone := newSignedZone(t)
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	other := newSignedZone(t)
+	resolver, err := search_domain_operator.NewDnsResolver(addr, &configv1alpha1.DnssecConfig{
+		TrustAnchors: []string{other.key.String()},
+	}, "")
+	assert.NilError(t, err)
+
+	// the PTR records cannot be validated: the already discovered clusters have to be kept
+	_, err = resolver.Wan(signedDomain, true)
+	assert.Assert(t, err != nil)
+
+	txts, err := resolver.Wan(signedDomain, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Assert(t, !txts[0].DnssecValidated)
+}
+
+// ------
+// tests the behaviour without DNSSEC configuration
+func testDnssecNotConfigured(t *testing.T) {
+	zone := newSignedZone(t)
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	txts, err := search_domain_operator.Wan(addr, signedDomain)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Assert(t, !txts[0].DnssecChecked)
+	assert.Assert(t, !txts[0].DnssecValidated)
+
+	resolver, err := search_domain_operator.NewDnsResolver(addr, nil, "")
+	assert.NilError(t, err)
+	_, err = resolver.Wan(signedDomain, true)
+	assert.Assert(t, err != nil)
+}
+
+// ------
+// tests that the queries are authenticated with TSIG and that unsigned answers are refused
+func testTsig(t *testing.T) {
+	zone := newSignedZone(t)
+	addr, stop := setupSignedDNSServer(t, zone)
+	defer stop()
+
+	config := &configv1alpha1.DnssecConfig{
+		TrustAnchors: []string{zone.key.String()},
+		Tsig: &configv1alpha1.TsigConfig{
+			KeyName:   tsigKeyName,
+			Algorithm: "hmac-sha256",
+		},
+	}
+
+	resolver, err := search_domain_operator.NewDnsResolver(addr, config, tsigSecret)
+	assert.NilError(t, err)
+	txts, err := resolver.Wan(signedDomain, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(txts), 1)
+	assert.Assert(t, txts[0].DnssecValidated)
+
+	wrongSecret := base64.StdEncoding.EncodeToString([]byte("wrong-secret"))
+	resolver, err = search_domain_operator.NewDnsResolver(addr, config, wrongSecret)
+	assert.NilError(t, err)
+	_, err = resolver.Wan(signedDomain, false)
+	assert.Assert(t, err != nil)
+
+	unsignedZone := newSignedZone(t)
+	unsignedZone.skipTsig = true
+	unsignedAddr, stopUnsigned := setupSignedDNSServer(t, unsignedZone)
+	defer stopUnsigned()
+	config.TrustAnchors = []string{unsignedZone.key.String()}
+	resolver, err = search_domain_operator.NewDnsResolver(unsignedAddr, config, tsigSecret)
+	assert.NilError(t, err)
+	_, err = resolver.Wan(signedDomain, false)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,234 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGarbageCollection(t *testing.T) {
+	t.Run("testWanTtl", testWanTtl)
+	t.Run("testRegistryTtl", testRegistryTtl)
+	t.Run("testOrphanedResources", testOrphanedResources)
+	t.Run("testGarbageDryRun", testGarbageDryRun)
+}
+
+// an owner reference to a ForeignCluster that does not exist
+func missingForeignClusterOwner() []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "ForeignCluster",
+			Name:       "fc-test-gc-missing",
+			UID:        "fc-test-gc-missing-uid",
+		},
+	}
+}
+
+func createOrphanedServiceAccount(t *testing.T, name string) {
+	_, err := clientCluster.client.Client().CoreV1().ServiceAccounts("default").Create(context.TODO(), &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			OwnerReferences: missingForeignClusterOwner(),
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+}
+
+// ------
+// tests that the ForeignClusters discovered in WAN are deleted when they are not refreshed in the configured WanTtl
+func testWanTtl(t *testing.T) {
+	config := clientCluster.discoveryCtrl.Config.GarbageCollection
+	defer func() {
+		clientCluster.discoveryCtrl.Config.GarbageCollection = config
+	}()
+
+	fc := &v1alpha1.ForeignCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fc-test-wan-ttl",
+			Labels: map[string]string{
+				"discovery-type": string(v1alpha1.WanDiscovery),
+			},
+			Annotations: map[string]string{
+				v1alpha1.LastUpdateAnnotation: strconv.Itoa(int(time.Now().Add(-2 * time.Minute).Unix())),
+			},
+		},
+		Spec: v1alpha1.ForeignClusterSpec{
+			ClusterIdentity: v1alpha1.ClusterIdentity{
+				ClusterID: "fc-test-wan-ttl",
+			},
+			Namespace:     "default",
+			Join:          false,
+			ApiUrl:        "http://" + serverCluster.cfg.Host,
+			DiscoveryType: v1alpha1.WanDiscovery,
+		},
+	}
+	_, err := clientCluster.client.Resource("foreignclusters").Create(fc, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	clientCluster.discoveryCtrl.Config.GarbageCollection.WanTtl = 0
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	_, err = clientCluster.client.Resource("foreignclusters").Get(fc.Name, metav1.GetOptions{})
+	assert.NilError(t, err, "without WanTtl the WAN ForeignClusters are not collected")
+
+	clientCluster.discoveryCtrl.Config.GarbageCollection.WanTtl = 300
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	_, err = clientCluster.client.Resource("foreignclusters").Get(fc.Name, metav1.GetOptions{})
+	assert.NilError(t, err, "the ForeignCluster has been refreshed before WanTtl")
+
+	clientCluster.discoveryCtrl.Config.GarbageCollection.WanTtl = 60
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	_, err = clientCluster.client.Resource("foreignclusters").Get(fc.Name, metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "this resource was not deleted by garbage collector")
+}
+
+// ------
+// tests that the ForeignClusters discovered in a registry are deleted when their TTL expires, unless they are peered
+func testRegistryTtl(t *testing.T) {
+	fc := &v1alpha1.ForeignCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fc-test-registry-ttl",
+			Labels: map[string]string{
+				"discovery-type": string(v1alpha1.RegistryDiscovery),
+			},
+			Annotations: map[string]string{
+				v1alpha1.LastUpdateAnnotation: strconv.Itoa(int(time.Now().Add(-2 * time.Minute).Unix())),
+			},
+		},
+		Spec: v1alpha1.ForeignClusterSpec{
+			ClusterIdentity: v1alpha1.ClusterIdentity{
+				ClusterID: "fc-test-registry-ttl",
+			},
+			Namespace:     "default",
+			Join:          false,
+			ApiUrl:        "http://" + serverCluster.cfg.Host,
+			DiscoveryType: v1alpha1.RegistryDiscovery,
+		},
+		Status: v1alpha1.ForeignClusterStatus{
+			Ttl: 60,
+			Incoming: v1alpha1.Incoming{
+				Joined: true,
+			},
+		},
+	}
+	_, err := clientCluster.client.Resource("foreignclusters").Create(fc, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	tmp, err := clientCluster.client.Resource("foreignclusters").Get(fc.Name, metav1.GetOptions{})
+	assert.NilError(t, err, "the peered ForeignClusters are not collected")
+
+	fc, ok := tmp.(*v1alpha1.ForeignCluster)
+	assert.Assert(t, ok)
+	fc.Status.Incoming.Joined = false
+	_, err = clientCluster.client.Resource("foreignclusters").Update(fc.Name, fc, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	_, err = clientCluster.client.Resource("foreignclusters").Get(fc.Name, metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "this resource was not deleted by garbage collector")
+}
+
+// ------
+// tests that the resources owned by a ForeignCluster that no longer exists are deleted, as well as the
+// CRDReplicator ClusterRoleBindings owned by a ServiceAccount that no longer exists
+func testOrphanedResources(t *testing.T) {
+	createOrphanedServiceAccount(t, "fc-test-gc-orphan")
+	_, err := clientCluster.client.Client().RbacV1().ClusterRoleBindings().Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fc-test-gc-legacy-crdReplicator",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ServiceAccount",
+					Name:       "fc-test-gc-legacy",
+					UID:        "fc-test-gc-legacy-uid",
+				},
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     "crdReplicator-role",
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	// owned by an existing ServiceAccount
+	sa, err := clientCluster.client.Client().CoreV1().ServiceAccounts("default").Create(context.TODO(), &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fc-test-gc-owner",
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	_, err = clientCluster.client.Client().RbacV1().ClusterRoleBindings().Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fc-test-gc-owner-crdReplicator",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ServiceAccount",
+					Name:       sa.Name,
+					UID:        sa.UID,
+				},
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     "crdReplicator-role",
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+
+	_, err = clientCluster.client.Client().CoreV1().ServiceAccounts("default").Get(context.TODO(), "fc-test-gc-orphan", metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "the orphaned ServiceAccount was not deleted")
+	_, err = clientCluster.client.Client().RbacV1().ClusterRoleBindings().Get(context.TODO(), "fc-test-gc-legacy-crdReplicator", metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "the orphaned ClusterRoleBinding was not deleted")
+	_, err = clientCluster.client.Client().RbacV1().ClusterRoleBindings().Get(context.TODO(), "fc-test-gc-owner-crdReplicator", metav1.GetOptions{})
+	assert.NilError(t, err)
+}
+
+// ------
+// tests that in dry run mode nothing is deleted and the garbage is listed in the report
+func testGarbageDryRun(t *testing.T) {
+	config := clientCluster.discoveryCtrl.Config.GarbageCollection
+	defer func() {
+		clientCluster.discoveryCtrl.Config.GarbageCollection = config
+	}()
+	createOrphanedServiceAccount(t, "fc-test-gc-dry-run")
+
+	clientCluster.discoveryCtrl.Config.GarbageCollection.DryRun = true
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+
+	_, err := clientCluster.client.Client().CoreV1().ServiceAccounts("default").Get(context.TODO(), "fc-test-gc-dry-run", metav1.GetOptions{})
+	assert.NilError(t, err, "a resource was deleted in dry run mode")
+	cm, err := clientCluster.client.Client().CoreV1().ConfigMaps("default").Get(context.TODO(), discovery.GarbageReportName, metav1.GetOptions{})
+	assert.NilError(t, err)
+	var garbage []discovery.Garbage
+	assert.NilError(t, json.Unmarshal([]byte(cm.Data["garbage"]), &garbage))
+	found := false
+	for _, g := range garbage {
+		if g.Kind == "ServiceAccount" && g.Name == "fc-test-gc-dry-run" {
+			assert.Equal(t, g.Namespace, "default")
+			assert.Assert(t, g.Reason != "")
+			found = true
+		}
+	}
+	assert.Assert(t, found, "the orphaned ServiceAccount is not in the report")
+
+	clientCluster.discoveryCtrl.Config.GarbageCollection.DryRun = false
+	assert.NilError(t, clientCluster.discoveryCtrl.CollectGarbage())
+	_, err = clientCluster.client.Client().CoreV1().ServiceAccounts("default").Get(context.TODO(), "fc-test-gc-dry-run", metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "the orphaned ServiceAccount was not deleted")
+}
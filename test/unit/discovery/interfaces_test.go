@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery"
+	"gotest.tools/assert"
+	"net"
+	"testing"
+)
+
+func TestInterfaces(t *testing.T) {
+	t.Run("testInterfaceFilter", testInterfaceFilter)
+	t.Run("testInterfaceFilterCidrs", testInterfaceFilterCidrs)
+	t.Run("testInvalidInterfaceFilter", testInvalidInterfaceFilter)
+	t.Run("testSelectApiAddress", testSelectApiAddress)
+}
+
+// ------
+// tests that interfaces are selected by name
+func testInterfaceFilter(t *testing.T) {
+	ips := []net.IP{net.ParseIP("192.168.1.10")}
+
+	filter, err := discovery.NewInterfaceFilter(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, filter.Accept("eth0", ips), "without configuration every interface is accepted")
+
+	filter, err = discovery.NewInterfaceFilter(&configv1alpha1.MdnsInterfaceConfig{
+		Allow: []string{"eth*", "bond0"},
+		Deny:  []string{"eth1"},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, filter.Accept("eth0", ips))
+	assert.Assert(t, filter.Accept("bond0", ips))
+	assert.Assert(t, !filter.Accept("eth1", ips), "denied interfaces are excluded even if allowed")
+	assert.Assert(t, !filter.Accept("wlan0", ips), "only the allowed interfaces are accepted")
+
+	filter, err = discovery.NewInterfaceFilter(&configv1alpha1.MdnsInterfaceConfig{
+		Deny: []string{"mgmt*"},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, filter.Accept("eth0", ips))
+	assert.Assert(t, !filter.Accept("mgmt0", ips))
+}
+
+// ------
+// tests that interfaces are selected by the networks they are connected to
+func testInterfaceFilterCidrs(t *testing.T) {
+	filter, err := discovery.NewInterfaceFilter(&configv1alpha1.MdnsInterfaceConfig{
+		Cidrs: []string{"192.168.1.0/24", "fd00::/64"},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, filter.Accept("eth0", []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("192.168.1.10")}))
+	assert.Assert(t, filter.Accept("eth1", []net.IP{net.ParseIP("fd00::1")}))
+	assert.Assert(t, !filter.Accept("eth2", []net.IP{net.ParseIP("10.0.0.1")}))
+	assert.Assert(t, !filter.Accept("eth3", nil))
+}
+
+// ------
+// tests that invalid configurations are refused
+func testInvalidInterfaceFilter(t *testing.T) {
+	_, err := discovery.NewInterfaceFilter(&configv1alpha1.MdnsInterfaceConfig{
+		Cidrs: []string{"192.168.1.0"},
+	})
+	assert.Assert(t, err != nil)
+
+	_, err = discovery.NewInterfaceFilter(&configv1alpha1.MdnsInterfaceConfig{
+		Allow: []string{"eth["},
+	})
+	assert.Assert(t, err != nil)
+}
+
+// ------
+// tests that each interface announces the API server address reachable on it
+func testSelectApiAddress(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.168.1.0/24")
+	_, mgmt, _ := net.ParseCIDR("10.0.0.0/24")
+	candidates := []string{"10.0.0.5", "master.example.com", "192.168.1.5"}
+
+	assert.Equal(t, discovery.SelectApiAddress([]*net.IPNet{lan}, candidates), "192.168.1.5")
+	assert.Equal(t, discovery.SelectApiAddress([]*net.IPNet{mgmt}, candidates), "10.0.0.5")
+	_, other, _ := net.ParseCIDR("172.16.0.0/16")
+	assert.Equal(t, discovery.SelectApiAddress([]*net.IPNet{other}, candidates), "10.0.0.5",
+		"the first address is used if no address is in the networks of the interface")
+	assert.Equal(t, discovery.SelectApiAddress(nil, nil), "")
+}
@@ -0,0 +1,138 @@
+package discovery
+
+import (
+	"context"
+	"encoding/pem"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery/kubeconfig"
+	"github.com/liqotech/liqo/pkg/crdClient"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiServerEndpoints(t *testing.T) {
+	t.Run("testKubeconfigEndpoints", testKubeconfigEndpoints)
+	t.Run("testUnreachableEndpoints", testUnreachableEndpoints)
+	t.Run("testKubeconfigFailover", testKubeconfigFailover)
+}
+
+// a TLS server answering as an API server, with the PEM encoded certificate to trust it
+func newApiServer() (*httptest.Server, []byte) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// an unauthorized answer proves the connectivity too
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	return server, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// a clientset with the ServiceAccount the kubeconfig is created for
+func newServiceAccountClient(t *testing.T) kubernetes.Interface {
+	clientset := fake.NewSimpleClientset()
+	_, err := clientset.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sa-secret",
+		},
+		Data: map[string][]byte{
+			"token":  []byte("token"),
+			"ca.crt": []byte("service-account-ca"),
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	_, err = clientset.CoreV1().ServiceAccounts("default").Create(context.TODO(), &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sa",
+		},
+		Secrets: []corev1.ObjectReference{
+			{
+				Name: "sa-secret",
+			},
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	return clientset
+}
+
+// ------
+// tests that the first reachable endpoint is the server of the current context, while the others are kept for failover
+func testKubeconfigEndpoints(t *testing.T) {
+	server, ca := newApiServer()
+	defer server.Close()
+	down, _ := newApiServer()
+	downUrl := down.URL
+	down.Close()
+
+	kc, _, err := kubeconfig.CreateKubeConfig(newServiceAccountClient(t), &configv1alpha1.ApiServerConfig{
+		Endpoints: []string{downUrl, server.URL},
+		CaData:    ca,
+	}, "sa", "default", nil)
+	assert.NilError(t, err)
+
+	cnf, err := clientcmd.Load([]byte(kc))
+	assert.NilError(t, err)
+	current := cnf.Contexts[cnf.CurrentContext]
+	assert.Equal(t, cnf.Clusters[current.Cluster].Server, server.URL)
+	assert.DeepEqual(t, cnf.Clusters[current.Cluster].CertificateAuthorityData, ca)
+	assert.Equal(t, len(cnf.Contexts), 2)
+	assert.Equal(t, len(cnf.Clusters), 2)
+	for name, context := range cnf.Contexts {
+		if name != cnf.CurrentContext {
+			assert.Equal(t, cnf.Clusters[context.Cluster].Server, downUrl)
+			assert.Equal(t, context.AuthInfo, "sa")
+		}
+	}
+}
+
+// ------
+// tests that no kubeconfig is created if no endpoint is reachable, or if its certificate is not trusted
+func testUnreachableEndpoints(t *testing.T) {
+	server, _ := newApiServer()
+	defer server.Close()
+	down, ca := newApiServer()
+	downUrl := down.URL
+	down.Close()
+
+	_, _, err := kubeconfig.CreateKubeConfig(newServiceAccountClient(t), &configv1alpha1.ApiServerConfig{
+		Endpoints: []string{downUrl},
+		CaData:    ca,
+	}, "sa", "default", nil)
+	assert.Assert(t, err != nil)
+
+	// the certificate of server is not signed by the CA of the ServiceAccount
+	_, _, err = kubeconfig.CreateKubeConfig(newServiceAccountClient(t), &configv1alpha1.ApiServerConfig{
+		Endpoints: []string{server.URL},
+	}, "sa", "default", nil)
+	assert.Assert(t, err != nil)
+}
+
+// ------
+// tests that the clients created from the kubeconfig fail over to a reachable server
+func testKubeconfigFailover(t *testing.T) {
+	first, ca := newApiServer()
+	second := httptest.NewUnstartedServer(first.Config.Handler)
+	second.TLS = first.TLS
+	second.StartTLS()
+	defer second.Close()
+
+	kc, _, err := kubeconfig.CreateKubeConfig(newServiceAccountClient(t), &configv1alpha1.ApiServerConfig{
+		Endpoints: []string{first.URL, second.URL},
+		CaData:    ca,
+	}, "sa", "default", nil)
+	assert.NilError(t, err)
+	first.Close()
+
+	config, err := crdClient.NewKubeconfigFromSecret(&corev1.Secret{
+		Data: map[string][]byte{
+			"kubeconfig": []byte(kc),
+		},
+	}, &discoveryv1alpha1.GroupVersion)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Host, second.URL)
+}
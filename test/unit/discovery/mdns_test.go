@@ -31,13 +31,17 @@ func testTxtData(t *testing.T) {
 		Name:      "Cluster 1",
 		Namespace: "default",
 		ApiUrl:    "https://" + serverCluster.cfg.Host,
+		Capabilities: v1alpha1.ClusterCapabilities{
+			TxtVersion:     discovery.TxtVersion,
+			TunnelBackends: []string{"gre"},
+		},
 	}
 	txt, err := txtData.Encode()
 	assert.NilError(t, err, "Error encoding txtData to DNS format")
 
 	txtData2, err := discovery.Decode("127.0.0.1", strings.Split(serverCluster.cfg.Host, ":")[1], txt)
 	assert.NilError(t, err, "Error decoding txtData from DNS format")
-	assert.Equal(t, txtData, *txtData2, "TxtData before and after encoding doesn't match")
+	assert.DeepEqual(t, txtData, *txtData2)
 }
 
 // ------
@@ -0,0 +1,269 @@
+package discovery
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery"
+	"github.com/liqotech/liqo/internal/discovery/registry"
+	"gotest.tools/assert"
+	"k8s.io/client-go/kubernetes/fake"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRegistryKey(t *testing.T) (ed25519.PrivateKey, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	return priv, base64.StdEncoding.EncodeToString(pub)
+}
+
+func createFakeKeyStore() registry.KeyStore {
+	return registry.NewConfigMapKeyStore(fake.NewSimpleClientset(), "liqo", "discovery-registry-keys")
+}
+
+func createFakeRegistry(t *testing.T, store registry.KeyStore) *httptest.Server {
+	handler, err := registry.NewHandler(store)
+	assert.NilError(t, err)
+	return httptest.NewServer(handler)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("testRecordSignature", testRecordSignature)
+	t.Run("testRegistryPublisher", testRegistryPublisher)
+	t.Run("testRegistryKeyPinning", testRegistryKeyPinning)
+	t.Run("testRegistryKeyPersistence", testRegistryKeyPersistence)
+	t.Run("testRegistryRequestSize", testRegistryRequestSize)
+	t.Run("testParsePrivateKey", testParsePrivateKey)
+	t.Run("testRegistryServe", testRegistryServe)
+}
+
+// ------
+// tests that altered and expired records are refused
+func testRecordSignature(t *testing.T) {
+	key, pub := newRegistryKey(t)
+	record := &registry.Record{
+		ClusterID: "registry-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.1:6443",
+		Ttl:       60,
+	}
+	assert.NilError(t, record.Sign(key))
+	assert.NilError(t, record.Verify())
+	assert.Assert(t, record.IsSignedBy([]string{pub}))
+
+	_, otherPub := newRegistryKey(t)
+	assert.Assert(t, !record.IsSignedBy([]string{otherPub}))
+
+	tampered := *record
+	tampered.ApiUrl = "https://10.0.0.2:6443"
+	assert.Assert(t, tampered.Verify() != nil)
+
+	expired := *record
+	expired.Ttl = 1
+	assert.NilError(t, expired.Sign(key))
+	time.Sleep(2 * time.Second)
+	assert.Assert(t, expired.IsExpired())
+	assert.Assert(t, expired.Verify() != nil)
+}
+
+// ------
+// tests that the published records are listed and withdrawn
+func testRegistryPublisher(t *testing.T) {
+	server := createFakeRegistry(t, createFakeKeyStore())
+	defer server.Close()
+
+	key, pub := newRegistryKey(t)
+	otherKey, _ := newRegistryKey(t)
+
+	config := &configv1alpha1.RegistryPublicationConfig{
+		Url: server.URL,
+		Ttl: 60,
+	}
+	publisher, err := discovery.NewRegistryPublisher(config, key, server.Client())
+	assert.NilError(t, err)
+	assert.Equal(t, publisher.RefreshPeriod(), 30*time.Second)
+	untrustedPublisher, err := discovery.NewRegistryPublisher(config, otherKey, server.Client())
+	assert.NilError(t, err)
+
+	assert.NilError(t, publisher.Publish(&discovery.TxtData{
+		ID:        "registry-cluster",
+		Name:      "registry",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.1:6443",
+	}))
+	assert.NilError(t, untrustedPublisher.Publish(&discovery.TxtData{
+		ID:        "untrusted-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.2:6443",
+	}))
+
+	client := registry.NewClient(server.URL, server.Client())
+	records, err := client.List([]string{pub})
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 1, "only the records signed by trusted keys have to be listed")
+
+	txt, err := discovery.TxtDataFromRecord(records[0])
+	assert.NilError(t, err)
+	assert.Equal(t, txt.ID, "registry-cluster")
+	assert.Equal(t, txt.Name, "registry")
+	assert.Equal(t, txt.Namespace, "liqo")
+	assert.Equal(t, txt.ApiUrl, "https://10.0.0.1:6443")
+	assert.Equal(t, txt.Ttl, uint32(60))
+
+	assert.NilError(t, publisher.Withdraw())
+	records, err = client.List([]string{pub})
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 0)
+
+	assert.NilError(t, untrustedPublisher.Withdraw())
+}
+
+// ------
+// tests that a cluster cannot be replaced or withdrawn with another key
+func testRegistryKeyPinning(t *testing.T) {
+	server := createFakeRegistry(t, createFakeKeyStore())
+	defer server.Close()
+	client := registry.NewClient(server.URL, server.Client())
+
+	key, pub := newRegistryKey(t)
+	otherKey, otherPub := newRegistryKey(t)
+
+	record := &registry.Record{
+		ClusterID: "registry-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.1:6443",
+		Ttl:       60,
+	}
+	assert.NilError(t, record.Sign(key))
+	assert.NilError(t, client.Publish(record))
+
+	hijack := *record
+	hijack.ApiUrl = "https://10.0.0.2:6443"
+	assert.NilError(t, hijack.Sign(otherKey))
+	assert.Assert(t, client.Publish(&hijack) != nil)
+
+	withdraw := &registry.Record{ClusterID: "registry-cluster"}
+	assert.NilError(t, withdraw.Sign(otherKey))
+	assert.Assert(t, client.Withdraw(withdraw) != nil)
+
+	// a record with zero TTL cannot be published
+	assert.NilError(t, withdraw.Sign(key))
+	assert.Assert(t, client.Publish(withdraw) != nil)
+
+	records, err := client.List([]string{pub, otherPub})
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].ApiUrl, "https://10.0.0.1:6443")
+
+	assert.NilError(t, client.Withdraw(withdraw))
+}
+
+// ------
+// tests that a cluster cannot be registered with another key after the registry is restarted
+func testRegistryKeyPersistence(t *testing.T) {
+	store := createFakeKeyStore()
+	server := createFakeRegistry(t, store)
+	client := registry.NewClient(server.URL, server.Client())
+
+	key, _ := newRegistryKey(t)
+	otherKey, _ := newRegistryKey(t)
+
+	record := &registry.Record{
+		ClusterID: "registry-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.1:6443",
+		Ttl:       60,
+	}
+	assert.NilError(t, record.Sign(key))
+	assert.NilError(t, client.Publish(record))
+	server.Close()
+
+	// the restarted registry has no record, but the key of the cluster is still pinned
+	server = createFakeRegistry(t, store)
+	defer server.Close()
+	client = registry.NewClient(server.URL, server.Client())
+	records, err := client.List(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 0)
+
+	hijack := *record
+	hijack.ApiUrl = "https://10.0.0.2:6443"
+	assert.NilError(t, hijack.Sign(otherKey))
+	assert.Assert(t, client.Publish(&hijack) != nil)
+
+	assert.NilError(t, record.Sign(key))
+	assert.NilError(t, client.Publish(record))
+
+	// the key stays pinned after the record is withdrawn
+	withdraw := &registry.Record{ClusterID: "registry-cluster"}
+	assert.NilError(t, withdraw.Sign(key))
+	assert.NilError(t, client.Withdraw(withdraw))
+	assert.NilError(t, hijack.Sign(otherKey))
+	assert.Assert(t, client.Publish(&hijack) != nil)
+}
+
+// ------
+// tests that the bodies larger than a record and the invalid cluster IDs are refused
+func testRegistryRequestSize(t *testing.T) {
+	server := createFakeRegistry(t, createFakeKeyStore())
+	defer server.Close()
+
+	body := `{"clusterID": "registry-cluster", "apiUrl": "` + strings.Repeat("a", 1<<20) + `"}`
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/records/registry-cluster", strings.NewReader(body))
+	assert.NilError(t, err)
+	resp, err := server.Client().Do(req)
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+	req, err = http.NewRequest(http.MethodPut, server.URL+"/records/registry%20cluster", strings.NewReader("{}"))
+	assert.NilError(t, err)
+	resp, err = server.Client().Do(req)
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+}
+
+// ------
+// tests that the private key is read from its PEM encoding
+func testParsePrivateKey(t *testing.T) {
+	key, _ := newRegistryKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NilError(t, err)
+	parsed, err := registry.ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parsed, key)
+
+	_, err = registry.ParsePrivateKey([]byte("not a key"))
+	assert.Assert(t, err != nil)
+}
+
+// ------
+// tests that the registry is served until it is stopped
+func testRegistryServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- registry.Serve(listener, createFakeKeyStore(), stop)
+	}()
+
+	client := registry.NewClient("http://"+listener.Addr().String(), nil)
+	records, err := client.List(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 0)
+
+	close(stop)
+	assert.NilError(t, <-done)
+	_, err = client.List(nil)
+	assert.Assert(t, err != nil)
+}
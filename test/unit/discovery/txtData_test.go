@@ -0,0 +1,198 @@
+package discovery
+
+import (
+	"fmt"
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	"github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	"github.com/liqotech/liqo/internal/discovery"
+	"github.com/liqotech/liqo/internal/discovery/registry"
+	"gotest.tools/assert"
+	"strings"
+	"testing"
+)
+
+func TestTxtSchema(t *testing.T) {
+	t.Run("testCapabilitiesEncoding", testCapabilitiesEncoding)
+	t.Run("testLegacyTxt", testLegacyTxt)
+	t.Run("testLongLists", testLongLists)
+	t.Run("testCapabilityRequirements", testCapabilityRequirements)
+	t.Run("testRegistryCapabilities", testRegistryCapabilities)
+	t.Run("testCanAutoJoin", testCanAutoJoin)
+}
+
+// ------
+// tests that the capabilities are encoded and decoded, and that unknown keys are ignored
+func testCapabilitiesEncoding(t *testing.T) {
+	acceptsPeering := true
+	txtData := discovery.TxtData{
+		ID:        "capabilities-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.1:6443",
+		Capabilities: v1alpha1.ClusterCapabilities{
+			TxtVersion:     discovery.TxtVersion,
+			LiqoVersion:    "v0.2.0",
+			TunnelBackends: []string{"gre", "wireguard"},
+			ApiGroups:      []string{"net.liqo.io/v1alpha1", "sharing.liqo.io/v1alpha1"},
+			AcceptsPeering: &acceptsPeering,
+		},
+	}
+	txt, err := txtData.Encode()
+	assert.NilError(t, err)
+	assert.Equal(t, txt[0], fmt.Sprintf("txtvers=%v", discovery.TxtVersion))
+
+	// a newer schema version may add keys unknown to this one
+	txt = append(txt, "txtvers=99", "future=value")
+	decoded, err := discovery.Decode("", "", txt)
+	assert.NilError(t, err)
+	txtData.Capabilities.TxtVersion = 99
+	assert.DeepEqual(t, txtData, *decoded)
+}
+
+// ------
+// tests that the records written before the schema versioning are still accepted
+func testLegacyTxt(t *testing.T) {
+	decoded, err := discovery.Decode("", "", []string{
+		"id=legacy-cluster",
+		"namespace=liqo",
+		"url=https://10.0.0.1:6443",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, decoded.ID, "legacy-cluster")
+	assert.DeepEqual(t, decoded.Capabilities, v1alpha1.ClusterCapabilities{TxtVersion: 1})
+
+	_, err = discovery.Decode("", "", []string{"txtvers=2", "namespace=liqo"})
+	assert.Assert(t, err != nil, "required fields are missing")
+}
+
+// ------
+// tests that lists longer than a TXT character-string are split in more entries
+func testLongLists(t *testing.T) {
+	groups := make([]string, 40)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("group%02d.liqo.io/v1alpha1", i)
+	}
+	txtData := discovery.TxtData{
+		Capabilities: v1alpha1.ClusterCapabilities{
+			ApiGroups: groups,
+		},
+	}
+	entries := txtData.EncodeCapabilities()
+	assert.Assert(t, len(entries) > 1)
+	for _, entry := range entries {
+		assert.Assert(t, strings.HasPrefix(entry, "apigroups="))
+		assert.Assert(t, len(entry) <= 255, "entry %v is too long", entry)
+	}
+
+	decoded := discovery.TxtData{}
+	for _, entry := range entries {
+		decoded.DecodeCapability(entry)
+	}
+	assert.DeepEqual(t, decoded.Capabilities.ApiGroups, groups)
+}
+
+// ------
+// tests the auto-join requirements
+func testCapabilityRequirements(t *testing.T) {
+	acceptsPeering := true
+	caps := &v1alpha1.ClusterCapabilities{
+		TxtVersion:     2,
+		TunnelBackends: []string{"gre"},
+		ApiGroups:      []string{"net.liqo.io/v1alpha1", "sharing.liqo.io/v1alpha1"},
+		AcceptsPeering: &acceptsPeering,
+	}
+	legacy := &v1alpha1.ClusterCapabilities{TxtVersion: 1}
+
+	assert.Assert(t, caps.Satisfies(nil))
+	assert.Assert(t, legacy.Satisfies(nil))
+	var unknown *v1alpha1.ClusterCapabilities
+	assert.Assert(t, unknown.Satisfies(nil))
+
+	req := &v1alpha1.CapabilityRequirements{
+		MinTxtVersion:  2,
+		TunnelBackends: []string{"wireguard", "gre"},
+		ApiGroups:      []string{"net.liqo.io/v1alpha1"},
+		AcceptsPeering: true,
+	}
+	assert.Assert(t, caps.Satisfies(req))
+	assert.Assert(t, !legacy.Satisfies(req))
+	assert.Assert(t, !unknown.Satisfies(req))
+
+	req.TunnelBackends = []string{"wireguard"}
+	assert.Assert(t, !caps.Satisfies(req), "no common tunnel backend")
+
+	req.TunnelBackends = nil
+	req.ApiGroups = append(req.ApiGroups, "virtualkubelet.liqo.io/v1alpha1")
+	assert.Assert(t, !caps.Satisfies(req), "an API group is missing")
+
+	req.ApiGroups = nil
+	acceptsPeering = false
+	assert.Assert(t, !caps.Satisfies(req), "the cluster does not accept peerings")
+}
+
+// ------
+// tests that the capabilities are carried by the registry records
+func testRegistryCapabilities(t *testing.T) {
+	server := createFakeRegistry(t, createFakeKeyStore())
+	defer server.Close()
+
+	key, pub := newRegistryKey(t)
+	publisher, err := discovery.NewRegistryPublisher(&configv1alpha1.RegistryPublicationConfig{
+		Url: server.URL,
+		Ttl: 60,
+	}, key, server.Client())
+	assert.NilError(t, err)
+
+	caps := v1alpha1.ClusterCapabilities{
+		TxtVersion:     discovery.TxtVersion,
+		LiqoVersion:    "v0.2.0",
+		TunnelBackends: []string{"gre"},
+	}
+	assert.NilError(t, publisher.Publish(&discovery.TxtData{
+		ID:           "registry-cluster",
+		Namespace:    "liqo",
+		ApiUrl:       "https://10.0.0.1:6443",
+		Capabilities: caps,
+	}))
+	defer publisher.Withdraw()
+
+	records, err := registry.NewClient(server.URL, server.Client()).List([]string{pub})
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 1)
+	txt, err := discovery.TxtDataFromRecord(records[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, txt.Capabilities, caps)
+
+	// records published before the capabilities were added
+	legacy := &registry.Record{
+		ClusterID: "legacy-cluster",
+		Namespace: "liqo",
+		ApiUrl:    "https://10.0.0.2:6443",
+		Ttl:       60,
+	}
+	assert.NilError(t, legacy.Sign(key))
+	txt, err = discovery.TxtDataFromRecord(legacy)
+	assert.NilError(t, err)
+	assert.Equal(t, txt.Capabilities.TxtVersion, 1)
+}
+
+// ------
+// tests that only the authenticated clusters are automatically joined
+func testCanAutoJoin(t *testing.T) {
+	tests := []struct {
+		name        string
+		txtData     discovery.TxtData
+		spec        v1alpha1.SearchDomainSpec
+		canAutoJoin bool
+	}{
+		{"validated", discovery.TxtData{DnssecChecked: true, DnssecValidated: true}, v1alpha1.SearchDomainSpec{AutoJoin: true}, true},
+		{"autojoin disabled", discovery.TxtData{DnssecChecked: true, DnssecValidated: true}, v1alpha1.SearchDomainSpec{}, false},
+		{"not validated", discovery.TxtData{DnssecChecked: true}, v1alpha1.SearchDomainSpec{AutoJoin: true, AutoJoinWithoutDnssec: true}, false},
+		{"not checked", discovery.TxtData{}, v1alpha1.SearchDomainSpec{AutoJoin: true}, false},
+		{"not checked, opt-in", discovery.TxtData{}, v1alpha1.SearchDomainSpec{AutoJoin: true, AutoJoinWithoutDnssec: true}, true},
+		{"registry", discovery.TxtData{}, v1alpha1.SearchDomainSpec{AutoJoin: true, Registry: &v1alpha1.RegistrySource{}}, true},
+	}
+	for _, test := range tests {
+		sd := &v1alpha1.SearchDomain{Spec: test.spec}
+		assert.Equal(t, test.txtData.CanAutoJoin(sd), test.canAutoJoin, test.name)
+	}
+}
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"context"
+	foreign_cluster_operator "github.com/liqotech/liqo/internal/discovery/foreign-cluster-operator"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+func TestUnpeering(t *testing.T) {
+	t.Run("testCordonNode", testCordonNode)
+	t.Run("testEvictPods", testEvictPods)
+	t.Run("testVirtualNodes", testVirtualNodes)
+}
+
+func newPod(name string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+// ------
+// tests that the virtual node is made unschedulable and schedulable again
+func testCordonNode(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "liqo-cluster",
+		},
+	})
+
+	assert.NilError(t, foreign_cluster_operator.CordonNode(clientset, "liqo-cluster", true))
+	node, err := clientset.CoreV1().Nodes().Get(context.TODO(), "liqo-cluster", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, node.Spec.Unschedulable)
+
+	assert.NilError(t, foreign_cluster_operator.CordonNode(clientset, "liqo-cluster", false))
+	node, err = clientset.CoreV1().Nodes().Get(context.TODO(), "liqo-cluster", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !node.Spec.Unschedulable)
+
+	err = foreign_cluster_operator.CordonNode(clientset, "liqo-missing", true)
+	assert.Assert(t, errors.IsNotFound(err))
+}
+
+// ------
+// tests that the pods running on the virtual node are evicted, except the ones protected by a PodDisruptionBudget,
+// and that the DaemonSet pods and the terminated ones are ignored
+func testEvictPods(t *testing.T) {
+	daemonSetPod := newPod("daemonset", "liqo-cluster")
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{
+		{
+			Kind: "DaemonSet",
+			Name: "ds",
+		},
+	}
+	completedPod := newPod("completed", "liqo-cluster")
+	completedPod.Status.Phase = corev1.PodSucceeded
+	clientset := fake.NewSimpleClientset(
+		newPod("evictable", "liqo-cluster"),
+		newPod("protected", "liqo-cluster"),
+		newPod("other-node", "node-1"),
+		daemonSetPod,
+		completedPod,
+	)
+	var evicted []string
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		if eviction.Name == "protected" {
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		}
+		evicted = append(evicted, eviction.Name)
+		return true, nil, clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+
+	remaining, err := foreign_cluster_operator.EvictPods(clientset, "liqo-cluster")
+	assert.NilError(t, err)
+	assert.Equal(t, remaining, 2, "the evicted pods are counted until they terminate")
+	assert.DeepEqual(t, evicted, []string{"evictable"})
+
+	remaining, err = foreign_cluster_operator.EvictPods(clientset, "liqo-cluster")
+	assert.NilError(t, err)
+	assert.Equal(t, remaining, 1, "the pod protected by a PodDisruptionBudget is still running")
+
+	_, err = clientset.CoreV1().Pods("default").Get(context.TODO(), "other-node", metav1.GetOptions{})
+	assert.NilError(t, err)
+	_, err = clientset.CoreV1().Pods("default").Get(context.TODO(), "daemonset", metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	err = clientset.CoreV1().Pods("default").Delete(context.TODO(), "protected", metav1.DeleteOptions{})
+	assert.NilError(t, err)
+	remaining, err = foreign_cluster_operator.EvictPods(clientset, "liqo-cluster")
+	assert.NilError(t, err)
+	assert.Equal(t, remaining, 0)
+}
+
+// ------
+// tests that all the virtual nodes of the cluster are found, including the partitioned ones and the legacy unlabeled one
+func testVirtualNodes(t *testing.T) {
+	newNode := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		newNode("liqo-cluster", map[string]string{"type": "virtual-node"}),
+		newNode("liqo-cluster-zone-a", map[string]string{"type": "virtual-node", "liqo.io/remote-cluster-id": "cluster"}),
+		newNode("liqo-cluster-zone-b", map[string]string{"type": "virtual-node", "liqo.io/remote-cluster-id": "cluster"}),
+		newNode("liqo-cluster-2", map[string]string{"type": "virtual-node", "liqo.io/remote-cluster-id": "cluster-2"}),
+		newNode("worker", nil),
+	)
+
+	nodes, err := foreign_cluster_operator.VirtualNodes(clientset, "cluster")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, nodes, []string{"liqo-cluster", "liqo-cluster-zone-a", "liqo-cluster-zone-b"})
+}
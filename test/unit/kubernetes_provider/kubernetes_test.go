@@ -1,9 +1,12 @@
 package kubernetes_provider
 
 import (
+	advtypes "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	"github.com/liqotech/liqo/pkg/virtualKubelet/provider"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/translation"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"testing"
@@ -116,7 +119,7 @@ func TestH2FCreation(t *testing.T) {
 		},
 		Status: v1.PodStatus{},
 	}
-	pForeign := translation.H2FTranslate(pHome, "")
+	pForeign := translation.H2FTranslate(pHome, "", nil)
 
 	assert.Empty(t, pForeign.UID, "The UID of translated pod should be null")
 	assert.Empty(t, pForeign.Spec.NodeName, "The NodeName should not be set")
@@ -132,6 +135,33 @@ func TestH2FCreation(t *testing.T) {
 	assert.ElementsMatch(t, filteredVolumes, pForeign.Spec.Volumes)
 }
 
+func TestH2FCreationWithNodeSelector(t *testing.T) {
+	pHome := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "toto", Namespace: "test",
+		},
+		Spec: v1.PodSpec{
+			NodeName:   "trololo",
+			Containers: createFakeContainers(nil),
+		},
+	}
+	nodeSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"pool": "liqo"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "gpu", Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+	pForeign := translation.H2FTranslate(pHome, "", nodeSelector)
+
+	terms := pForeign.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Len(t, terms, 1)
+	assert.ElementsMatch(t, []v1.NodeSelectorRequirement{
+		{Key: "type", Operator: v1.NodeSelectorOpNotIn, Values: []string{"virtual-node"}},
+		{Key: "pool", Operator: v1.NodeSelectorOpIn, Values: []string{"liqo"}},
+		{Key: "gpu", Operator: v1.NodeSelectorOpDoesNotExist},
+	}, terms[0].MatchExpressions)
+}
+
 func TestF2HCreation(t *testing.T) {
 	annotations := make(map[string]string)
 	annotations["home_nodename"] = "toto"
@@ -223,3 +253,126 @@ func TestFilterVolumeMounts(t *testing.T) {
 
 	assert.ElementsMatch(t, expectedResult, result)
 }
+
+func TestCheckPodFitsNode(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("2"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					}},
+				},
+				{
+					Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+						v1.ResourceCPU: resource.MustParse("1"),
+					}},
+				},
+			},
+		},
+	}
+
+	// no topology announced: every pod is accepted
+	assert.NoError(t, provider.CheckPodFitsNode(pod, nil))
+
+	assert.NoError(t, provider.CheckPodFitsNode(pod, []v1.ResourceList{{
+		v1.ResourceCPU:    resource.MustParse("3"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	}}))
+
+	// the pod requests 3 cpus in total
+	assert.Error(t, provider.CheckPodFitsNode(pod, []v1.ResourceList{{
+		v1.ResourceCPU:    resource.MustParse("2500m"),
+		v1.ResourceMemory: resource.MustParse("64Gi"),
+	}}))
+
+	assert.Error(t, provider.CheckPodFitsNode(pod, []v1.ResourceList{{
+		v1.ResourceCPU:    resource.MustParse("64"),
+		v1.ResourceMemory: resource.MustParse("512Mi"),
+	}}))
+
+	// two nodes with complementary shapes: the cpus of the first one and the memory of the second one
+	// would be enough, but the pod cannot be split among them
+	complementary := []v1.ResourceList{
+		{
+			v1.ResourceCPU:    resource.MustParse("64"),
+			v1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+		{
+			v1.ResourceCPU:    resource.MustParse("2500m"),
+			v1.ResourceMemory: resource.MustParse("64Gi"),
+		},
+	}
+	assert.Error(t, provider.CheckPodFitsNode(pod, complementary))
+
+	// the pod is accepted as soon as one node fits all its requests
+	assert.NoError(t, provider.CheckPodFitsNode(pod, append(complementary, v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	})))
+}
+
+func TestGetRemotePartition(t *testing.T) {
+	adv := &advtypes.Advertisement{
+		Spec: advtypes.AdvertisementSpec{
+			ResourceQuota: v1.ResourceQuotaSpec{Hard: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("12Gi"),
+			}},
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"liqo": "true"}},
+			Topology: advtypes.AdvertisementTopology{
+				LargestNodes: []v1.ResourceList{{v1.ResourceCPU: resource.MustParse("8")}},
+				Zones: []advtypes.ZoneTopology{
+					{
+						Region:       "eu",
+						Zone:         "eu-a",
+						Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")},
+						LargestNodes: []v1.ResourceList{{v1.ResourceCPU: resource.MustParse("2")}},
+					},
+					{
+						Region:       "eu",
+						Zone:         "eu-b",
+						Resources:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), v1.ResourceMemory: resource.MustParse("16Gi")},
+						LargestNodes: []v1.ResourceList{{v1.ResourceCPU: resource.MustParse("8")}},
+					},
+				},
+			},
+		},
+	}
+
+	// the whole cluster
+	partition := provider.GetRemotePartition(adv, "", "")
+	assert.Equal(t, adv.Spec.ResourceQuota.Hard, partition.Resources)
+	assert.Equal(t, adv.Spec.Topology.LargestNodes, partition.LargestNodes)
+	assert.Equal(t, adv.Spec.NodeSelector, partition.NodeSelector)
+	assert.Empty(t, partition.Labels)
+
+	// the announced resources are split proportionally among the zones
+	partition = provider.GetRemotePartition(adv, "eu-a", "")
+	assert.Equal(t, int64(2000), partition.Resources.Cpu().MilliValue())
+	assert.Equal(t, int64(4*1024*1024*1024), partition.Resources.Memory().Value())
+	assert.Equal(t, int64(2), partition.LargestNodes[0].Cpu().Value())
+	assert.Equal(t, map[string]string{v1.LabelZoneFailureDomainStable: "eu-a", v1.LabelZoneRegionStable: "eu"}, partition.Labels)
+	assert.Equal(t, map[string]string{"liqo": "true"}, partition.NodeSelector.MatchLabels)
+	assert.Equal(t, []metav1.LabelSelectorRequirement{
+		{Key: v1.LabelZoneFailureDomainStable, Operator: metav1.LabelSelectorOpIn, Values: []string{"eu-a"}},
+	}, partition.NodeSelector.MatchExpressions)
+	// the Advertisement is not modified
+	assert.Empty(t, adv.Spec.NodeSelector.MatchExpressions)
+
+	// the foreign nodes of a zone read from the deprecated label are selected by it, the virtual node has the stable one
+	adv.Spec.Topology.Zones[1].ZoneLabel = v1.LabelZoneFailureDomain
+	partition = provider.GetRemotePartition(adv, "eu-b", "")
+	assert.Equal(t, map[string]string{v1.LabelZoneFailureDomainStable: "eu-b", v1.LabelZoneRegionStable: "eu"}, partition.Labels)
+	assert.Equal(t, []metav1.LabelSelectorRequirement{
+		{Key: v1.LabelZoneFailureDomain, Operator: metav1.LabelSelectorOpIn, Values: []string{"eu-b"}},
+	}, partition.NodeSelector.MatchExpressions)
+
+	// a zone not announced anymore cannot host any pod
+	partition = provider.GetRemotePartition(adv, "eu-c", "")
+	assert.True(t, partition.Resources.Cpu().IsZero())
+	assert.Error(t, provider.CheckPodFitsNode(&v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{
+		Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}},
+	}}}}, partition.LargestNodes))
+}
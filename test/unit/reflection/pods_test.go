@@ -0,0 +1,62 @@
+package reflection
+
+import (
+	api "github.com/liqotech/liqo/pkg/virtualKubelet/apiReflection/reflectors"
+	"github.com/liqotech/liqo/pkg/virtualKubelet/apiReflection/reflectors/incoming"
+	ri "github.com/liqotech/liqo/pkg/virtualKubelet/apiReflection/reflectors/reflectorsInterfaces"
+	"github.com/liqotech/liqo/pkg/virtualKubelet/options/types"
+	"github.com/liqotech/liqo/pkg/virtualKubelet/translation"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"testing"
+)
+
+func createFakePodsReflector(nodeName string) ri.IncomingAPIReflector {
+	reflector := &incoming.PodsIncomingReflector{
+		APIReflector: &api.GenericAPIReflector{
+			ForeignClient:    fake.NewSimpleClientset(),
+			NamespaceNatting: NewFakeNatter(),
+		},
+		RemoteRemappedPodCIDR: types.NewNetworkingOption(types.RemoteRemappedPodCIDR, ""),
+		NodeName:              types.NewNetworkingOption(types.NodeName, types.NetworkingValue(nodeName)),
+	}
+	reflector.SetSpecializedPreProcessingHandlers()
+	return reflector
+}
+
+func createFakeForeignPod(name, homeNodeName string) *v1.Pod {
+	home := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "namespace",
+		},
+		Spec: v1.PodSpec{
+			NodeName: homeNodeName,
+		},
+	}
+	return translation.H2FTranslate(home, "test", nil)
+}
+
+// the virtual nodes of two partitions of the same foreign cluster share the foreign namespace
+func TestPodsPartitionsSharingNamespace(t *testing.T) {
+	reflectorA := createFakePodsReflector("vk-zone-a")
+	reflectorB := createFakePodsReflector("vk-zone-b")
+
+	podA := createFakeForeignPod("pod-a", "vk-zone-a")
+	podB := createFakeForeignPod("pod-b", "vk-zone-b")
+	assert.Equal(t, podA.Namespace, podB.Namespace)
+
+	assert.Assert(t, translation.IsHomeNodePod(podA, "vk-zone-a"))
+	assert.Assert(t, !translation.IsHomeNodePod(podA, "vk-zone-b"))
+
+	// each partition reflects only the pods it offloaded
+	assert.Assert(t, reflectorA.PreProcessIsAllowed(podA))
+	assert.Assert(t, !reflectorA.PreProcessIsAllowed(podB))
+	assert.Assert(t, reflectorB.PreProcessIsAllowed(podB))
+	assert.Assert(t, !reflectorB.PreProcessIsAllowed(podA))
+	assert.Assert(t, !reflectorB.PreProcessIsAllowed(cache.DeletedFinalStateUnknown{Key: "test/pod-a", Obj: podA}))
+	assert.Assert(t, reflectorB.PreProcessIsAllowed(cache.DeletedFinalStateUnknown{Key: "test/pod-b", Obj: podB}))
+}